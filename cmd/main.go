@@ -6,12 +6,16 @@ import (
 	"apicategorywithfallback/pkg/config"
 	"apicategorywithfallback/pkg/database"
 	"apicategorywithfallback/pkg/logger"
+	"apicategorywithfallback/pkg/tracing"
 	"context"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
+	"text/tabwriter"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -52,6 +56,24 @@ func main() {
 	// Load configuration
 	cfg := config.Load()
 
+	// Schema migration subcommand: main migrate status|up|down [steps]
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(cfg.DatabasePath, os.Args[2:]); err != nil {
+			log.Fatal("Migration failed: ", err)
+		}
+		return
+	}
+
+	// Initialize tracing (no-op unless TRACING_ENABLED=true)
+	shutdownTracing, err := tracing.Init(context.Background(), tracing.Settings{
+		Enabled:     cfg.TracingEnabled,
+		ServiceName: cfg.TracingServiceName,
+		SampleRatio: cfg.TracingSampleRatio,
+	})
+	if err != nil {
+		log.Fatal("Failed to initialize tracing:", err)
+	}
+
 	// Initialize database
 	db, err := database.Init(cfg.DatabasePath, cfg)
 	if err != nil {
@@ -62,9 +84,17 @@ func main() {
 	// Initialize services
 	apiService := service.NewAPIService(db, cfg)
 
+	// Create the initial dashboard admin on first start
+	if err := apiService.BootstrapDashboardAdmin(); err != nil {
+		log.Fatal("Failed to set up dashboard authentication:", err)
+	}
+
 	// Start background health checker
 	go apiService.StartHealthChecker()
 
+	// Start background rollups and pruning of old rows
+	go apiService.StartMaintenance()
+
 	// Initialize router
 	router := gin.Default()
 
@@ -108,5 +138,76 @@ func main() {
 		log.Fatal("Server forced to shutdown:", err)
 	}
 
+	// Flush buffered spans
+	if err := shutdownTracing(ctx); err != nil {
+		logger.Warnf("Failed to flush traces: %v", err)
+	}
+
 	logger.Info("Server exited")
 }
+
+// runMigrate shows the migration status, applies pending migrations or rolls back
+func runMigrate(dbPath string, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: migrate status|up|down [steps]")
+	}
+
+	db, err := database.Open(dbPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	migrator, err := db.Migrator()
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "status":
+		statuses, err := migrator.Status()
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+		for _, status := range statuses {
+			state := "pending"
+			if status.Applied {
+				state = "applied"
+			}
+			fmt.Fprintf(w, "%04d\t%s\t%s\t%s\n", status.Version, status.Name, state, status.AppliedAt)
+		}
+		return w.Flush()
+
+	case "up":
+		applied, err := migrator.Up()
+		for _, migration := range applied {
+			fmt.Printf("Applied %04d_%s\n", migration.Version, migration.Name)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Println("Schema is up to date")
+		}
+		return err
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				return fmt.Errorf("invalid number of steps: %s", args[1])
+			}
+		}
+		rolledBack, err := migrator.Down(steps)
+		for _, migration := range rolledBack {
+			fmt.Printf("Rolled back %04d_%s\n", migration.Version, migration.Name)
+		}
+		if err == nil && len(rolledBack) == 0 {
+			fmt.Println("No applied migrations to roll back")
+		}
+		return err
+
+	default:
+		return fmt.Errorf("unknown migrate command %q, expected status, up or down", args[0])
+	}
+}
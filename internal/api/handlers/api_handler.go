@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -69,6 +70,7 @@ func (h *APIHandler) HandleJadwalRilis(c *gin.Context) {
 func (h *APIHandler) HandleJadwalRilisDay(c *gin.Context) {
 	day := c.Param("day")
 	ctx := h.buildRequestContext(c, "/api/v1/jadwal-rilis/"+day)
+	ctx.Route = "/api/v1/jadwal-rilis/:day" // Keeps the day out of metric labels and request logs
 	h.processRequest(c, ctx)
 }
 
@@ -231,6 +233,7 @@ func (h *APIHandler) buildRequestContext(c *gin.Context, endpoint string) *domai
 		Parameters: parameters,
 		ClientIP:   c.ClientIP(),
 		UserAgent:  c.GetHeader("User-Agent"),
+		APIKeyID:   c.GetInt(apiKeyIDContextKey),
 		StartTime:  time.Now(),
 	}
 }
@@ -239,17 +242,17 @@ func (h *APIHandler) buildRequestContext(c *gin.Context, endpoint string) *domai
 func (h *APIHandler) processRequest(c *gin.Context, ctx *domain.RequestContext) {
 	logger.Infof("Processing request: %s for category: %s", ctx.Endpoint, ctx.Category)
 
-	response, err := h.apiService.ProcessRequest(ctx)
+	response, err := h.apiService.ProcessRequest(c.Request.Context(), ctx)
 	if err != nil {
-		logger.Errorf("Request failed: %v", err)
-
-		// Return appropriate error response
-		statusCode := http.StatusServiceUnavailable
-		if err.Error() == "rate limit exceeded" {
-			statusCode = http.StatusTooManyRequests
+		// Client went away; nobody is left to receive a response
+		if c.Request.Context().Err() != nil {
+			logger.Warnf("Client disconnected before response for %s: %v", ctx.Endpoint, err)
+			return
 		}
 
-		c.JSON(statusCode, gin.H{
+		logger.Errorf("Request failed: %v", err)
+
+		c.JSON(http.StatusServiceUnavailable, gin.H{
 			"error":   true,
 			"message": err.Error(),
 			"source":  "apicategorywithfallback",
@@ -263,11 +266,22 @@ func (h *APIHandler) processRequest(c *gin.Context, ctx *domain.RequestContext)
 	c.Header("X-Response-Time", response.ResponseTime.String())
 
 	// If response is from cache, add cache header
-	if response.SourceName == "cache" {
+	if response.CacheStatus != "" {
+		c.Header("X-Cache", response.CacheStatus)
+	} else if response.SourceName == "cache" {
 		c.Header("X-Cache", "HIT")
 	} else {
 		c.Header("X-Cache", "MISS")
 	}
+	if response.Coalesced {
+		c.Header("X-Coalesced", "true")
+	}
+	if len(response.IncludedSources) > 0 {
+		c.Header("X-Included-Sources", strings.Join(response.IncludedSources, ","))
+	}
+	if len(response.LateSources) > 0 {
+		c.Header("X-Late-Sources", strings.Join(response.LateSources, ","))
+	}
 
 	c.Data(http.StatusOK, "application/json", response.Data)
 }
@@ -288,9 +302,12 @@ func (h *APIHandler) createEnhancedResponse(ctx *domain.RequestContext, response
 	totalTime := time.Since(startTime)
 
 	// Determine cache status
-	cacheStatus := "MISS"
-	if response.SourceName == "cache" {
-		cacheStatus = "HIT"
+	cacheStatus := response.CacheStatus
+	if cacheStatus == "" {
+		cacheStatus = "MISS"
+		if response.SourceName == "cache" {
+			cacheStatus = "HIT"
+		}
 	}
 
 	// Create filter description
@@ -313,6 +330,7 @@ func (h *APIHandler) createEnhancedResponse(ctx *domain.RequestContext, response
 			TotalTime:     totalTime.String(),
 			Attempts:      attempts,
 			CacheStatus:   cacheStatus,
+			Coalesced:     response.Coalesced,
 			Timestamp:     time.Now().Format(time.RFC3339),
 		},
 	}
@@ -329,6 +347,9 @@ func (h *APIHandler) sendEnhancedResponse(c *gin.Context, enhancedResponse *doma
 	c.Header("X-Category", enhancedResponse.Metadata.Category)
 	c.Header("X-Total-Time", enhancedResponse.Metadata.TotalTime)
 	c.Header("X-Attempts", fmt.Sprintf("%d", enhancedResponse.Metadata.Attempts))
+	if enhancedResponse.Metadata.Coalesced {
+		c.Header("X-Coalesced", "true")
+	}
 
 	c.JSON(http.StatusOK, enhancedResponse)
 }
@@ -355,6 +376,7 @@ func (h *APIHandler) buildDetailRequestContext(c *gin.Context, endpoint string,
 		Parameters: parameters,
 		ClientIP:   c.ClientIP(),
 		UserAgent:  c.GetHeader("User-Agent"),
+		APIKeyID:   c.GetInt(apiKeyIDContextKey),
 		StartTime:  time.Now(),
 	}
 }
@@ -397,8 +419,14 @@ func (h *APIHandler) processDetailRequest(c *gin.Context, ctx *domain.RequestCon
 	startTime := time.Now()
 	logger.Infof("Processing detail request: %s for category: %s with params: %+v", ctx.Endpoint, ctx.Category, ctx.Parameters)
 
-	response, err := h.apiService.ProcessRequest(ctx)
+	response, err := h.apiService.ProcessRequest(c.Request.Context(), ctx)
 	if err != nil {
+		// Client went away; nobody is left to receive a response
+		if c.Request.Context().Err() != nil {
+			logger.Warnf("Client disconnected before response for %s: %v", ctx.Endpoint, err)
+			return
+		}
+
 		logger.Errorf("Detail request failed: %v", err)
 
 		// Create enhanced error response
@@ -415,9 +443,7 @@ func (h *APIHandler) processDetailRequest(c *gin.Context, ctx *domain.RequestCon
 
 		// Return appropriate status code
 		statusCode := http.StatusServiceUnavailable
-		if err.Error() == "rate limit exceeded" {
-			statusCode = http.StatusTooManyRequests
-		} else if err.Error() == "no API sources configured for endpoint" {
+		if err.Error() == "no API sources configured for endpoint" {
 			statusCode = http.StatusNotFound
 		} else if err.Error() == "missing required parameters" {
 			statusCode = http.StatusBadRequest
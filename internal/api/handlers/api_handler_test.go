@@ -24,18 +24,13 @@ func setupTestHandler() (*APIHandler, func()) {
 	dbPath := "/tmp/test_handler.db"
 
 	cfg := &config.Config{
+		RedisAddr:      "127.0.0.1:1", // Refuses connections, so the memory cache is used
 		APITimeout:     10 * time.Second,
 		MaxConcurrency: 5,
 		RateLimit:      100, // Allow 100 requests per second
 		CacheTTL: map[string]time.Duration{
 			"/api/v1/home": 15 * time.Minute,
 		},
-		// Test API Sources
-		MultipleScrapeURL: "http://localhost:8081",
-		WinbuTVURL:        "http://localhost:8082",
-		SamehadakuURL:     "https://samehadaku.email",
-		OtakudesuURL:      "https://otakudesu.quest",
-		KusonimeURL:       "https://kusonime.com",
 	}
 
 	db, err := database.Init(dbPath, cfg)
@@ -0,0 +1,446 @@
+package handlers
+
+import (
+	"apicategorywithfallback/internal/service"
+	"apicategorywithfallback/pkg/auth"
+	"apicategorywithfallback/pkg/logger"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// sessionCookieName is the dashboard login session cookie
+	sessionCookieName = "dashboard_session"
+	// principalContextKey holds the authenticated *auth.Principal in the Gin context
+	principalContextKey = "dashboard_principal"
+)
+
+type AuthHandler struct {
+	apiService *service.APIService
+}
+
+func NewAuthHandler(apiService *service.APIService) *AuthHandler {
+	return &AuthHandler{
+		apiService: apiService,
+	}
+}
+
+// RequireRole authenticates dashboard requests by session cookie or bearer token
+// and rejects principals below the required role. Unauthenticated page requests
+// are redirected to the login page, JSON requests get 401.
+func (h *AuthHandler) RequireRole(required auth.Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !h.apiService.DashboardAuthEnabled() {
+			c.Next()
+			return
+		}
+
+		principal, err := h.authenticate(c)
+		if err != nil {
+			logger.Errorf("Dashboard authentication failed: %v", err)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+				"error":   "Authentication failed",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		if principal == nil {
+			if wantsHTML(c) {
+				c.Redirect(http.StatusSeeOther, "/dashboard/login?next="+url.QueryEscape(c.Request.URL.RequestURI()))
+				c.Abort()
+				return
+			}
+			c.Header("WWW-Authenticate", `Bearer realm="dashboard"`)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error": "Authentication required",
+			})
+			return
+		}
+
+		if !principal.Role.Allows(required) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"error":   "Insufficient permissions",
+				"details": fmt.Sprintf("%s role required, you have %s", required, principal.Role),
+			})
+			return
+		}
+
+		c.Set(principalContextKey, principal)
+		c.Next()
+	}
+}
+
+// authenticate resolves the bearer token or session cookie of a request
+func (h *AuthHandler) authenticate(c *gin.Context) (*auth.Principal, error) {
+	if header := c.GetHeader("Authorization"); strings.HasPrefix(header, "Bearer ") {
+		return h.apiService.AuthenticateBearer(strings.TrimPrefix(header, "Bearer "))
+	}
+
+	if session, err := c.Cookie(sessionCookieName); err == nil && session != "" {
+		return h.apiService.AuthenticateSession(session)
+	}
+
+	return nil, nil
+}
+
+// wantsHTML reports whether a request comes from a browser navigating to a page
+func wantsHTML(c *gin.Context) bool {
+	return c.Request.Method == http.MethodGet && strings.Contains(c.GetHeader("Accept"), "text/html")
+}
+
+// currentPrincipal returns the authenticated principal, or nil when auth is disabled
+func currentPrincipal(c *gin.Context) *auth.Principal {
+	if value, exists := c.Get(principalContextKey); exists {
+		if principal, ok := value.(*auth.Principal); ok {
+			return principal
+		}
+	}
+	return nil
+}
+
+// safeRedirectTarget only allows redirects back into the dashboard
+func safeRedirectTarget(next string) string {
+	if strings.HasPrefix(next, "/dashboard") && !strings.HasPrefix(next, "/dashboard/login") {
+		return next
+	}
+	return "/dashboard/"
+}
+
+// ShowLogin renders the dashboard login page
+func (h *AuthHandler) ShowLogin(c *gin.Context) {
+	if !h.apiService.DashboardAuthEnabled() {
+		c.Redirect(http.StatusSeeOther, "/dashboard/")
+		return
+	}
+
+	c.HTML(http.StatusOK, "login.html", gin.H{
+		"title": "API Fallback Login",
+		"next":  safeRedirectTarget(c.Query("next")),
+	})
+}
+
+// Login starts a dashboard session from a login form or a JSON body
+func (h *AuthHandler) Login(c *gin.Context) {
+	var req struct {
+		Username string `json:"username" form:"username" binding:"required"`
+		Password string `json:"password" form:"password" binding:"required"`
+		Next     string `json:"next" form:"next"`
+	}
+
+	isForm := c.ContentType() != "application/json"
+
+	if err := c.ShouldBind(&req); err != nil {
+		if isForm {
+			h.renderLoginError(c, http.StatusBadRequest, "Username and password are required", req.Next)
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	token, user, err := h.apiService.Login(req.Username, req.Password)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if errors.Is(err, service.ErrInvalidCredentials) {
+			statusCode = http.StatusUnauthorized
+			logger.Warnf("Failed dashboard login for %s from %s", req.Username, c.ClientIP())
+		}
+
+		if isForm {
+			h.renderLoginError(c, statusCode, err.Error(), req.Next)
+			return
+		}
+		c.JSON(statusCode, gin.H{
+			"error":   "Login failed",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	h.setSessionCookie(c, token, int(h.apiService.DashboardSessionTTL().Seconds()))
+
+	if isForm {
+		c.Redirect(http.StatusSeeOther, safeRedirectTarget(req.Next))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Logged in successfully",
+		"data":    user,
+	})
+}
+
+// Logout ends the current dashboard session
+func (h *AuthHandler) Logout(c *gin.Context) {
+	if session, err := c.Cookie(sessionCookieName); err == nil && session != "" {
+		if err := h.apiService.Logout(session); err != nil {
+			logger.Warnf("Failed to delete dashboard session: %v", err)
+		}
+	}
+
+	h.setSessionCookie(c, "", -1)
+
+	if c.ContentType() == "application/json" {
+		c.JSON(http.StatusOK, gin.H{
+			"status":  "success",
+			"message": "Logged out successfully",
+		})
+		return
+	}
+	c.Redirect(http.StatusSeeOther, "/dashboard/login")
+}
+
+// GetCurrentPrincipal returns the authenticated user or token
+func (h *AuthHandler) GetCurrentPrincipal(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"status":       "success",
+		"auth_enabled": h.apiService.DashboardAuthEnabled(),
+		"data":         currentPrincipal(c),
+	})
+}
+
+func (h *AuthHandler) renderLoginError(c *gin.Context, statusCode int, message, next string) {
+	c.HTML(statusCode, "login.html", gin.H{
+		"title": "API Fallback Login",
+		"error": message,
+		"next":  safeRedirectTarget(next),
+	})
+}
+
+func (h *AuthHandler) setSessionCookie(c *gin.Context, value string, maxAge int) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     sessionCookieName,
+		Value:    value,
+		Path:     "/dashboard",
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https",
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// GetUsers returns all dashboard users
+func (h *AuthHandler) GetUsers(c *gin.Context) {
+	users, err := h.apiService.GetAllDashboardUsers()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to get users",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"data":   users,
+		"count":  len(users),
+	})
+}
+
+// CreateUser creates a dashboard user
+func (h *AuthHandler) CreateUser(c *gin.Context) {
+	var req struct {
+		Username string `json:"username" binding:"required"`
+		Password string `json:"password" binding:"required"`
+		Role     string `json:"role" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	user, err := h.apiService.CreateDashboardUser(req.Username, req.Password, req.Role)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to create user",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"status":  "success",
+		"message": "User created successfully",
+		"data":    user,
+	})
+}
+
+// UpdateUser changes a dashboard user's role, status or password
+func (h *AuthHandler) UpdateUser(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid user ID",
+		})
+		return
+	}
+
+	var req struct {
+		Role     string `json:"role" binding:"required"`
+		IsActive bool   `json:"is_active"`
+		Password string `json:"password"` // Optional, keeps the current password when empty
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	err = h.apiService.UpdateDashboardUser(id, req.Role, req.IsActive, req.Password)
+	if err != nil {
+		statusCode := http.StatusBadRequest
+		if errors.Is(err, service.ErrUserNotFound) {
+			statusCode = http.StatusNotFound
+		}
+		c.JSON(statusCode, gin.H{
+			"error":   "Failed to update user",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "User updated successfully",
+		"data": gin.H{
+			"id":        id,
+			"role":      req.Role,
+			"is_active": req.IsActive,
+		},
+	})
+}
+
+// DeleteUser deletes a dashboard user
+func (h *AuthHandler) DeleteUser(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid user ID",
+		})
+		return
+	}
+
+	err = h.apiService.DeleteDashboardUser(id)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if errors.Is(err, service.ErrUserNotFound) {
+			statusCode = http.StatusNotFound
+		} else if errors.Is(err, service.ErrLastAdmin) {
+			statusCode = http.StatusBadRequest
+		}
+		c.JSON(statusCode, gin.H{
+			"error":   "Failed to delete user",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "User deleted successfully",
+		"data": gin.H{
+			"id": id,
+		},
+	})
+}
+
+// GetTokens returns all dashboard bearer tokens
+func (h *AuthHandler) GetTokens(c *gin.Context) {
+	tokens, err := h.apiService.GetAllDashboardTokens()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to get tokens",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"data":   tokens,
+		"count":  len(tokens),
+	})
+}
+
+// CreateToken creates a dashboard bearer token. The raw token is only returned once.
+func (h *AuthHandler) CreateToken(c *gin.Context) {
+	var req struct {
+		Name string `json:"name" binding:"required"`
+		Role string `json:"role" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	token, rawToken, err := h.apiService.CreateDashboardToken(req.Name, req.Role)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to create token",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"status":  "success",
+		"message": "Token created successfully. Store it now, it will not be shown again.",
+		"data": gin.H{
+			"token_info": token,
+			"token":      rawToken,
+		},
+	})
+}
+
+// DeleteToken revokes a dashboard bearer token
+func (h *AuthHandler) DeleteToken(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid token ID",
+		})
+		return
+	}
+
+	err = h.apiService.DeleteDashboardToken(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to delete token",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Token deleted successfully",
+		"data": gin.H{
+			"id": id,
+		},
+	})
+}
@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"apicategorywithfallback/internal/service"
+	"apicategorywithfallback/pkg/database"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// canonicalTitleRequest is the body of create and update requests
+type canonicalTitleRequest struct {
+	CanonicalID string            `json:"canonical_id" binding:"required"`
+	Title       string            `json:"title"`
+	Slugs       map[string]string `json:"slugs"` // Source name -> native slug
+}
+
+func (r *canonicalTitleRequest) canonicalTitle(id int) *database.CanonicalTitle {
+	return &database.CanonicalTitle{
+		ID:          id,
+		CanonicalID: r.CanonicalID,
+		Title:       r.Title,
+		Slugs:       r.Slugs,
+	}
+}
+
+// GetCanonicalTitles returns all canonical titles with their per-source slugs
+func (h *DashboardHandler) GetCanonicalTitles(c *gin.Context) {
+	titles, err := h.apiService.GetCanonicalTitles()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to get canonical titles",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"data":   titles,
+		"count":  len(titles),
+	})
+}
+
+// CreateCanonicalTitle adds a canonical title and its source slugs
+func (h *DashboardHandler) CreateCanonicalTitle(c *gin.Context) {
+	var req canonicalTitleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	title := req.canonicalTitle(0)
+	if err := h.apiService.CreateCanonicalTitle(title); err != nil {
+		c.JSON(canonicalTitleErrorStatus(err), gin.H{
+			"error":   "Failed to create canonical title",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"status":  "success",
+		"message": "Canonical title created successfully",
+		"data":    title,
+	})
+}
+
+// UpdateCanonicalTitle replaces a canonical title and its source slugs
+func (h *DashboardHandler) UpdateCanonicalTitle(c *gin.Context) {
+	id, ok := parseIDParam(c, "id", "Invalid canonical title ID")
+	if !ok {
+		return
+	}
+
+	var req canonicalTitleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	title := req.canonicalTitle(id)
+	if err := h.apiService.UpdateCanonicalTitle(title); err != nil {
+		c.JSON(canonicalTitleErrorStatus(err), gin.H{
+			"error":   "Failed to update canonical title",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Canonical title updated successfully",
+		"data":    title,
+	})
+}
+
+// DeleteCanonicalTitle removes a canonical title and its source slugs
+func (h *DashboardHandler) DeleteCanonicalTitle(c *gin.Context) {
+	id, ok := parseIDParam(c, "id", "Invalid canonical title ID")
+	if !ok {
+		return
+	}
+
+	if err := h.apiService.DeleteCanonicalTitle(id); err != nil {
+		c.JSON(canonicalTitleErrorStatus(err), gin.H{
+			"error":   "Failed to delete canonical title",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Canonical title deleted successfully",
+	})
+}
+
+// canonicalTitleErrorStatus maps validation errors to 400 and unknown titles to 404
+func canonicalTitleErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrInvalidCanonicalTitle):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrCanonicalTitleNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"apicategorywithfallback/internal/service"
+	"apicategorywithfallback/pkg/database"
+	"apicategorywithfallback/pkg/validator"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// confidencePolicyRequest is the body of create and update requests
+type confidencePolicyRequest struct {
+	EndpointPath string   `json:"endpoint_path" binding:"required"`
+	SourceName   string   `json:"source_name" binding:"required"`
+	MinScore     float64  `json:"min_score"`
+	MissingScore string   `json:"missing_score"` // Optional, defaults to reject
+	AssumedScore *float64 `json:"assumed_score"` // Optional, defaults to 1.0
+	IsActive     *bool    `json:"is_active"`     // Optional, defaults to true
+}
+
+func (r *confidencePolicyRequest) policy(id int) *database.ConfidencePolicy {
+	isActive := true
+	if r.IsActive != nil {
+		isActive = *r.IsActive
+	}
+	missingScore := r.MissingScore
+	if missingScore == "" {
+		missingScore = validator.MissingScoreReject
+	}
+	assumedScore := 1.0
+	if r.AssumedScore != nil {
+		assumedScore = *r.AssumedScore
+	}
+
+	return &database.ConfidencePolicy{
+		ID:           id,
+		EndpointPath: r.EndpointPath,
+		SourceName:   r.SourceName,
+		MinScore:     r.MinScore,
+		MissingScore: missingScore,
+		AssumedScore: assumedScore,
+		IsActive:     isActive,
+	}
+}
+
+// GetConfidencePolicies returns all confidence policies
+func (h *DashboardHandler) GetConfidencePolicies(c *gin.Context) {
+	policies, err := h.apiService.GetConfidencePolicies()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to get confidence policies",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"data":   policies,
+		"count":  len(policies),
+	})
+}
+
+// CreateConfidencePolicy adds a confidence policy for an endpoint and source
+func (h *DashboardHandler) CreateConfidencePolicy(c *gin.Context) {
+	var req confidencePolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	policy := req.policy(0)
+	if err := h.apiService.CreateConfidencePolicy(policy); err != nil {
+		c.JSON(confidencePolicyErrorStatus(err), gin.H{
+			"error":   "Failed to create confidence policy",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"status":  "success",
+		"message": "Confidence policy created successfully",
+		"data":    policy,
+	})
+}
+
+// UpdateConfidencePolicy replaces a confidence policy
+func (h *DashboardHandler) UpdateConfidencePolicy(c *gin.Context) {
+	id, ok := parseIDParam(c, "id", "Invalid confidence policy ID")
+	if !ok {
+		return
+	}
+
+	var req confidencePolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	policy := req.policy(id)
+	if err := h.apiService.UpdateConfidencePolicy(policy); err != nil {
+		c.JSON(confidencePolicyErrorStatus(err), gin.H{
+			"error":   "Failed to update confidence policy",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Confidence policy updated successfully",
+		"data":    policy,
+	})
+}
+
+// DeleteConfidencePolicy removes a confidence policy
+func (h *DashboardHandler) DeleteConfidencePolicy(c *gin.Context) {
+	id, ok := parseIDParam(c, "id", "Invalid confidence policy ID")
+	if !ok {
+		return
+	}
+
+	if err := h.apiService.DeleteConfidencePolicy(id); err != nil {
+		c.JSON(confidencePolicyErrorStatus(err), gin.H{
+			"error":   "Failed to delete confidence policy",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Confidence policy deleted successfully",
+	})
+}
+
+// confidencePolicyErrorStatus maps validation errors to 400 and unknown policies to 404
+func confidencePolicyErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrInvalidConfidencePolicy):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrConfidencePolicyNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"apicategorywithfallback/internal/service"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -9,6 +10,9 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// defaultAPIKeyRateLimit is the per-minute limit for new API keys when none is given
+const defaultAPIKeyRateLimit = 60
+
 type DashboardHandler struct {
 	apiService *service.APIService
 }
@@ -23,6 +27,7 @@ func NewDashboardHandler(apiService *service.APIService) *DashboardHandler {
 func (h *DashboardHandler) ShowDashboard(c *gin.Context) {
 	c.HTML(http.StatusOK, "dashboard.html", gin.H{
 		"title": "API Fallback Dashboard",
+		"user":  currentPrincipal(c),
 	})
 }
 
@@ -30,6 +35,7 @@ func (h *DashboardHandler) ShowDashboard(c *gin.Context) {
 func (h *DashboardHandler) ShowEnhancedDashboard(c *gin.Context) {
 	c.HTML(http.StatusOK, "dashboard_improved.html", gin.H{
 		"title": "API Fallback System",
+		"user":  currentPrincipal(c),
 	})
 }
 
@@ -37,6 +43,7 @@ func (h *DashboardHandler) ShowEnhancedDashboard(c *gin.Context) {
 func (h *DashboardHandler) ShowManagement(c *gin.Context) {
 	c.HTML(http.StatusOK, "dashboard_management.html", gin.H{
 		"title": "API Fallback Management",
+		"user":  currentPrincipal(c),
 	})
 }
 
@@ -469,7 +476,11 @@ func (h *DashboardHandler) CreateEndpoint(c *gin.Context) {
 
 	endpoint, err := h.apiService.CreateEndpoint(req.CategoryID, req.Path)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
+		status := http.StatusInternalServerError
+		if errors.Is(err, service.ErrInvalidEndpointPath) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{
 			"error":   "Failed to create endpoint",
 			"details": err.Error(),
 		})
@@ -509,7 +520,11 @@ func (h *DashboardHandler) UpdateEndpoint(c *gin.Context) {
 
 	endpoint, err := h.apiService.UpdateEndpoint(id, req.CategoryID, req.Path)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
+		status := http.StatusInternalServerError
+		if errors.Is(err, service.ErrInvalidEndpointPath) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{
 			"error":   "Failed to update endpoint",
 			"details": err.Error(),
 		})
@@ -630,3 +645,151 @@ func (h *DashboardHandler) GetAPISourcesByName(c *gin.Context) {
 		"count":  len(sources),
 	})
 }
+
+// GetAPIKeys returns all client API keys with today's usage
+func (h *DashboardHandler) GetAPIKeys(c *gin.Context) {
+	keys, err := h.apiService.GetAllAPIKeys()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to get API keys",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"data":   keys,
+		"count":  len(keys),
+	})
+}
+
+// CreateAPIKey creates a new client API key. The raw key is only returned once.
+func (h *DashboardHandler) CreateAPIKey(c *gin.Context) {
+	var req struct {
+		Name       string `json:"name" binding:"required"`
+		RateLimit  *int   `json:"rate_limit"`
+		DailyQuota int    `json:"daily_quota"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	rateLimit := defaultAPIKeyRateLimit
+	if req.RateLimit != nil {
+		rateLimit = *req.RateLimit
+	}
+	if rateLimit < 0 || req.DailyQuota < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "rate_limit and daily_quota must not be negative",
+		})
+		return
+	}
+
+	key, rawKey, err := h.apiService.CreateAPIKey(req.Name, rateLimit, req.DailyQuota)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to create API key",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"status":  "success",
+		"message": "API key created successfully. Store the key now, it will not be shown again.",
+		"data": gin.H{
+			"api_key": key,
+			"key":     rawKey,
+		},
+	})
+}
+
+// UpdateAPIKey updates the name, limits and status of an API key
+func (h *DashboardHandler) UpdateAPIKey(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid API key ID",
+		})
+		return
+	}
+
+	var req struct {
+		Name       string `json:"name" binding:"required"`
+		RateLimit  int    `json:"rate_limit"`
+		DailyQuota int    `json:"daily_quota"`
+		IsActive   bool   `json:"is_active"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if req.RateLimit < 0 || req.DailyQuota < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "rate_limit and daily_quota must not be negative",
+		})
+		return
+	}
+
+	err = h.apiService.UpdateAPIKey(id, req.Name, req.RateLimit, req.DailyQuota, req.IsActive)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to update API key",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "API key updated successfully",
+		"data": gin.H{
+			"id":          id,
+			"name":        req.Name,
+			"rate_limit":  req.RateLimit,
+			"daily_quota": req.DailyQuota,
+			"is_active":   req.IsActive,
+		},
+	})
+}
+
+// DeleteAPIKey revokes an API key
+func (h *DashboardHandler) DeleteAPIKey(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid API key ID",
+		})
+		return
+	}
+
+	err = h.apiService.DeleteAPIKey(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to delete API key",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "API key deleted successfully",
+		"data": gin.H{
+			"id": id,
+		},
+	})
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"apicategorywithfallback/internal/service"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// routePatternContextKey holds the endpoint template matched by the dynamic router
+	routePatternContextKey = "route_pattern"
+	// routeParamsContextKey holds the path parameters extracted from the template
+	routeParamsContextKey = "route_params"
+)
+
+// ResolveDynamicRoute matches requests without a static Gin route against the
+// endpoints configured in the database, so endpoints added from the dashboard
+// are served without a redeploy. Unknown paths outside /api are left to Gin's
+// default 404.
+func (h *APIHandler) ResolveDynamicRoute(c *gin.Context) {
+	path := c.Request.URL.Path
+	if !strings.HasPrefix(path, "/api/") {
+		c.Abort()
+		return
+	}
+
+	category := c.DefaultQuery("category", "anime")
+	route, params, err := h.apiService.ResolveEndpoint(category, path)
+	if err != nil {
+		status := http.StatusInternalServerError
+		message := "Failed to resolve endpoint"
+		if errors.Is(err, service.ErrEndpointNotFound) {
+			status = http.StatusNotFound
+			message = "Endpoint not found for category " + category
+		}
+
+		c.AbortWithStatusJSON(status, gin.H{
+			"error":   true,
+			"message": message,
+			"source":  "apicategorywithfallback",
+		})
+		return
+	}
+
+	c.Set(routePatternContextKey, route)
+	if c.Request.Method != http.MethodGet {
+		c.Header("Allow", http.MethodGet)
+		c.AbortWithStatusJSON(http.StatusMethodNotAllowed, gin.H{
+			"error":   true,
+			"message": "Method not allowed",
+			"source":  "apicategorywithfallback",
+		})
+		return
+	}
+
+	c.Set(routeParamsContextKey, params)
+}
+
+// HandleDynamicRoute serves a request matched by ResolveDynamicRoute through the
+// fallback pipeline
+func (h *APIHandler) HandleDynamicRoute(c *gin.Context) {
+	ctx := h.buildRequestContext(c, c.Request.URL.Path)
+	ctx.Route = c.GetString(routePatternContextKey)
+	if params, ok := c.Get(routeParamsContextKey); ok {
+		ctx.PathParams = params.(map[string]string)
+	}
+
+	h.processRequest(c, ctx)
+}
+
+// routeLabel returns the route a request matched, for metrics and span names
+func routeLabel(c *gin.Context) string {
+	if route := c.FullPath(); route != "" {
+		return route
+	}
+	if route := c.GetString(routePatternContextKey); route != "" {
+		return route
+	}
+	return "unmatched"
+}
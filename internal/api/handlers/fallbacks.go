@@ -0,0 +1,247 @@
+package handlers
+
+import (
+	"apicategorywithfallback/internal/service"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetFallbacks returns the fallback chain of an API source
+func (h *DashboardHandler) GetFallbacks(c *gin.Context) {
+	sourceID, ok := parseIDParam(c, "id", "Invalid API source ID")
+	if !ok {
+		return
+	}
+
+	fallbacks, err := h.apiService.GetFallbackChain(sourceID)
+	if err != nil {
+		c.JSON(fallbackErrorStatus(err, http.StatusInternalServerError), gin.H{
+			"error":   "Failed to get fallbacks",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"data":   fallbacks,
+		"count":  len(fallbacks),
+	})
+}
+
+// CreateFallback adds a fallback base URL to an API source
+func (h *DashboardHandler) CreateFallback(c *gin.Context) {
+	sourceID, ok := parseIDParam(c, "id", "Invalid API source ID")
+	if !ok {
+		return
+	}
+
+	var req struct {
+		FallbackURL string `json:"fallback_url" binding:"required"`
+		Priority    int    `json:"priority"`  // Optional, appended to the chain when 0
+		IsActive    *bool  `json:"is_active"` // Optional, defaults to true
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	isActive := true
+	if req.IsActive != nil {
+		isActive = *req.IsActive
+	}
+
+	fallback, err := h.apiService.CreateFallback(sourceID, req.FallbackURL, req.Priority, isActive)
+	if err != nil {
+		c.JSON(fallbackErrorStatus(err, http.StatusBadRequest), gin.H{
+			"error":   "Failed to create fallback",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"status":  "success",
+		"message": "Fallback created successfully",
+		"data":    fallback,
+	})
+}
+
+// UpdateFallback changes a fallback's URL, priority and status
+func (h *DashboardHandler) UpdateFallback(c *gin.Context) {
+	sourceID, ok := parseIDParam(c, "id", "Invalid API source ID")
+	if !ok {
+		return
+	}
+	fallbackID, ok := parseIDParam(c, "fallback_id", "Invalid fallback ID")
+	if !ok {
+		return
+	}
+
+	var req struct {
+		FallbackURL string `json:"fallback_url" binding:"required"`
+		Priority    int    `json:"priority" binding:"required"`
+		IsActive    bool   `json:"is_active"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	err := h.apiService.UpdateFallback(sourceID, fallbackID, req.FallbackURL, req.Priority, req.IsActive)
+	if err != nil {
+		c.JSON(fallbackErrorStatus(err, http.StatusBadRequest), gin.H{
+			"error":   "Failed to update fallback",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Fallback updated successfully",
+		"data": gin.H{
+			"id":            fallbackID,
+			"api_source_id": sourceID,
+			"fallback_url":  req.FallbackURL,
+			"priority":      req.Priority,
+			"is_active":     req.IsActive,
+		},
+	})
+}
+
+// EnableFallback puts a disabled fallback back into the chain
+func (h *DashboardHandler) EnableFallback(c *gin.Context) {
+	h.setFallbackActive(c, true)
+}
+
+// DisableFallback takes a fallback out of the chain without deleting it
+func (h *DashboardHandler) DisableFallback(c *gin.Context) {
+	h.setFallbackActive(c, false)
+}
+
+func (h *DashboardHandler) setFallbackActive(c *gin.Context, isActive bool) {
+	sourceID, ok := parseIDParam(c, "id", "Invalid API source ID")
+	if !ok {
+		return
+	}
+	fallbackID, ok := parseIDParam(c, "fallback_id", "Invalid fallback ID")
+	if !ok {
+		return
+	}
+
+	if err := h.apiService.SetFallbackActive(sourceID, fallbackID, isActive); err != nil {
+		c.JSON(fallbackErrorStatus(err, http.StatusInternalServerError), gin.H{
+			"error":   "Failed to update fallback",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	message := "Fallback disabled successfully"
+	if isActive {
+		message = "Fallback enabled successfully"
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": message,
+		"data": gin.H{
+			"id":        fallbackID,
+			"is_active": isActive,
+		},
+	})
+}
+
+// ReorderFallbacks sets the fallback order of an API source
+func (h *DashboardHandler) ReorderFallbacks(c *gin.Context) {
+	sourceID, ok := parseIDParam(c, "id", "Invalid API source ID")
+	if !ok {
+		return
+	}
+
+	var req struct {
+		FallbackIDs []int `json:"fallback_ids" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	fallbacks, err := h.apiService.ReorderFallbacks(sourceID, req.FallbackIDs)
+	if err != nil {
+		c.JSON(fallbackErrorStatus(err, http.StatusBadRequest), gin.H{
+			"error":   "Failed to reorder fallbacks",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Fallbacks reordered successfully",
+		"data":    fallbacks,
+	})
+}
+
+// DeleteFallback removes a fallback from an API source
+func (h *DashboardHandler) DeleteFallback(c *gin.Context) {
+	sourceID, ok := parseIDParam(c, "id", "Invalid API source ID")
+	if !ok {
+		return
+	}
+	fallbackID, ok := parseIDParam(c, "fallback_id", "Invalid fallback ID")
+	if !ok {
+		return
+	}
+
+	if err := h.apiService.DeleteFallback(sourceID, fallbackID); err != nil {
+		c.JSON(fallbackErrorStatus(err, http.StatusInternalServerError), gin.H{
+			"error":   "Failed to delete fallback",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Fallback deleted successfully",
+		"data": gin.H{
+			"id": fallbackID,
+		},
+	})
+}
+
+// parseIDParam parses a numeric path parameter, responding with 400 when it is invalid
+func parseIDParam(c *gin.Context, name, message string) (int, bool) {
+	id, err := strconv.Atoi(c.Param(name))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": message,
+		})
+		return 0, false
+	}
+	return id, true
+}
+
+// fallbackErrorStatus maps unknown sources and fallbacks to 404
+func fallbackErrorStatus(err error, defaultStatus int) int {
+	if errors.Is(err, service.ErrAPISourceNotFound) || errors.Is(err, service.ErrFallbackNotFound) {
+		return http.StatusNotFound
+	}
+	return defaultStatus
+}
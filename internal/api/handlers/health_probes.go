@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"apicategorywithfallback/internal/service"
+	"apicategorywithfallback/pkg/database"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// healthProbeRequest is the body of create and update requests
+type healthProbeRequest struct {
+	EndpointPath string            `json:"endpoint_path" binding:"required"`
+	Params       map[string]string `json:"params"`
+	ExpectSchema string            `json:"expect_schema"`
+	IsActive     *bool             `json:"is_active"` // Optional, defaults to true
+}
+
+func (r *healthProbeRequest) probe(id int) *database.HealthProbe {
+	isActive := true
+	if r.IsActive != nil {
+		isActive = *r.IsActive
+	}
+
+	return &database.HealthProbe{
+		ID:           id,
+		EndpointPath: r.EndpointPath,
+		Params:       r.Params,
+		ExpectSchema: r.ExpectSchema,
+		IsActive:     isActive,
+	}
+}
+
+// GetHealthProbes returns all synthetic health probes
+func (h *DashboardHandler) GetHealthProbes(c *gin.Context) {
+	probes, err := h.apiService.GetHealthProbes()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to get health probes",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"data":   probes,
+		"count":  len(probes),
+	})
+}
+
+// CreateHealthProbe adds the health probe of an endpoint
+func (h *DashboardHandler) CreateHealthProbe(c *gin.Context) {
+	var req healthProbeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	probe := req.probe(0)
+	if err := h.apiService.CreateHealthProbe(probe); err != nil {
+		c.JSON(healthProbeErrorStatus(err), gin.H{
+			"error":   "Failed to create health probe",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"status":  "success",
+		"message": "Health probe created successfully",
+		"data":    probe,
+	})
+}
+
+// UpdateHealthProbe replaces a health probe
+func (h *DashboardHandler) UpdateHealthProbe(c *gin.Context) {
+	id, ok := parseIDParam(c, "id", "Invalid health probe ID")
+	if !ok {
+		return
+	}
+
+	var req healthProbeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	probe := req.probe(id)
+	if err := h.apiService.UpdateHealthProbe(probe); err != nil {
+		c.JSON(healthProbeErrorStatus(err), gin.H{
+			"error":   "Failed to update health probe",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Health probe updated successfully",
+		"data":    probe,
+	})
+}
+
+// DeleteHealthProbe removes a health probe
+func (h *DashboardHandler) DeleteHealthProbe(c *gin.Context) {
+	id, ok := parseIDParam(c, "id", "Invalid health probe ID")
+	if !ok {
+		return
+	}
+
+	if err := h.apiService.DeleteHealthProbe(id); err != nil {
+		c.JSON(healthProbeErrorStatus(err), gin.H{
+			"error":   "Failed to delete health probe",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Health probe deleted successfully",
+	})
+}
+
+// healthProbeErrorStatus maps validation errors to 400 and unknown probes to 404
+func healthProbeErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrInvalidHealthProbe):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrHealthProbeNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"apicategorywithfallback/pkg/metrics"
+	"crypto/subtle"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MetricsMiddleware records request counts and latencies per matched route
+func MetricsMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		metrics.ObserveHTTPRequest(c.Request.Method, routeLabel(c), c.Writer.Status(), time.Since(start))
+	}
+}
+
+// MetricsHandler serves Prometheus metrics. When token is set, scrapers must
+// send it as a bearer token.
+func MetricsHandler(token string) gin.HandlerFunc {
+	handler := metrics.Handler()
+
+	return func(c *gin.Context) {
+		if token != "" {
+			expected := "Bearer " + token
+			if subtle.ConstantTimeCompare([]byte(c.GetHeader("Authorization")), []byte(expected)) != 1 {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+					"error": "Authentication required",
+				})
+				return
+			}
+		}
+
+		handler.ServeHTTP(c.Writer, c.Request)
+	}
+}
@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"apicategorywithfallback/internal/service"
+	"apicategorywithfallback/pkg/database"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultDebounceSeconds is the debounce period of channels created without one
+const defaultDebounceSeconds = 300
+
+// notificationChannelRequest is the body of create and update requests
+type notificationChannelRequest struct {
+	Name            string   `json:"name" binding:"required"`
+	Kind            string   `json:"kind" binding:"required"`
+	URL             string   `json:"url" binding:"required"`
+	ChatID          string   `json:"chat_id"`
+	EventTypes      []string `json:"event_types"`
+	Sources         []string `json:"sources"`
+	Endpoints       []string `json:"endpoints"`
+	DebounceSeconds *int     `json:"debounce_seconds"` // Optional, defaults to 300
+	IsActive        *bool    `json:"is_active"`        // Optional, defaults to true
+}
+
+func (r *notificationChannelRequest) channel(id int) *database.NotificationChannel {
+	isActive := true
+	if r.IsActive != nil {
+		isActive = *r.IsActive
+	}
+	debounce := defaultDebounceSeconds
+	if r.DebounceSeconds != nil {
+		debounce = *r.DebounceSeconds
+	}
+
+	return &database.NotificationChannel{
+		ID:              id,
+		Name:            r.Name,
+		Kind:            r.Kind,
+		URL:             r.URL,
+		ChatID:          r.ChatID,
+		EventTypes:      r.EventTypes,
+		Sources:         r.Sources,
+		Endpoints:       r.Endpoints,
+		DebounceSeconds: debounce,
+		IsActive:        isActive,
+	}
+}
+
+// GetNotificationChannels returns all notification channels
+func (h *DashboardHandler) GetNotificationChannels(c *gin.Context) {
+	channels, err := h.apiService.GetNotificationChannels()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to get notification channels",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"data":   channels,
+		"count":  len(channels),
+	})
+}
+
+// CreateNotificationChannel adds an alert destination
+func (h *DashboardHandler) CreateNotificationChannel(c *gin.Context) {
+	var req notificationChannelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	channel := req.channel(0)
+	if err := h.apiService.CreateNotificationChannel(channel); err != nil {
+		c.JSON(notificationChannelErrorStatus(err), gin.H{
+			"error":   "Failed to create notification channel",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"status":  "success",
+		"message": "Notification channel created successfully",
+		"data":    channel,
+	})
+}
+
+// UpdateNotificationChannel replaces a notification channel
+func (h *DashboardHandler) UpdateNotificationChannel(c *gin.Context) {
+	id, ok := parseIDParam(c, "id", "Invalid notification channel ID")
+	if !ok {
+		return
+	}
+
+	var req notificationChannelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	channel := req.channel(id)
+	if err := h.apiService.UpdateNotificationChannel(channel); err != nil {
+		c.JSON(notificationChannelErrorStatus(err), gin.H{
+			"error":   "Failed to update notification channel",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Notification channel updated successfully",
+		"data":    channel,
+	})
+}
+
+// DeleteNotificationChannel removes a notification channel
+func (h *DashboardHandler) DeleteNotificationChannel(c *gin.Context) {
+	id, ok := parseIDParam(c, "id", "Invalid notification channel ID")
+	if !ok {
+		return
+	}
+
+	if err := h.apiService.DeleteNotificationChannel(id); err != nil {
+		c.JSON(notificationChannelErrorStatus(err), gin.H{
+			"error":   "Failed to delete notification channel",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Notification channel deleted successfully",
+	})
+}
+
+// TestNotificationChannel sends a test notification to a channel
+func (h *DashboardHandler) TestNotificationChannel(c *gin.Context) {
+	id, ok := parseIDParam(c, "id", "Invalid notification channel ID")
+	if !ok {
+		return
+	}
+
+	if err := h.apiService.TestNotificationChannel(id); err != nil {
+		c.JSON(notificationChannelErrorStatus(err), gin.H{
+			"error":   "Failed to send test notification",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Test notification sent successfully",
+	})
+}
+
+// notificationChannelErrorStatus maps validation errors to 400, unknown
+// channels to 404 and failed deliveries to 502
+func notificationChannelErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrInvalidNotificationChannel):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrNotificationChannelNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrNotificationFailed):
+		return http.StatusBadGateway
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"apicategorywithfallback/internal/service"
+	"apicategorywithfallback/pkg/logger"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// apiKeyIDContextKey holds the authenticated API key ID in the Gin context
+const apiKeyIDContextKey = "api_key_id"
+
+// RateLimitMiddleware applies per-client rate limits and daily quotas.
+// Clients identify themselves with the X-API-Key header; anonymous clients
+// are limited per IP.
+func (h *APIHandler) RateLimitMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		result, err := h.apiService.CheckRateLimit(c.GetHeader("X-API-Key"), c.ClientIP())
+		if err != nil {
+			statusCode := http.StatusInternalServerError
+			if errors.Is(err, service.ErrInvalidAPIKey) || errors.Is(err, service.ErrAPIKeyRequired) {
+				statusCode = http.StatusUnauthorized
+			} else {
+				logger.Errorf("Rate limit check failed: %v", err)
+			}
+
+			c.AbortWithStatusJSON(statusCode, gin.H{
+				"error":   true,
+				"message": err.Error(),
+				"source":  "apicategorywithfallback",
+			})
+			return
+		}
+
+		if result.Limit > 0 {
+			c.Header("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+			c.Header("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+			c.Header("X-RateLimit-Reset", strconv.FormatInt(result.Reset.Unix(), 10))
+		}
+		if result.QuotaLimit > 0 {
+			remaining := result.QuotaLimit - result.QuotaUsed
+			if remaining < 0 {
+				remaining = 0
+			}
+			c.Header("X-Quota-Limit", strconv.Itoa(result.QuotaLimit))
+			c.Header("X-Quota-Remaining", strconv.Itoa(remaining))
+		}
+
+		if !result.Allowed {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(result.RetryAfter.Seconds()))))
+
+			message := "rate limit exceeded"
+			if result.QuotaExceeded {
+				message = "daily quota exceeded"
+			}
+
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+				"error":   true,
+				"message": message,
+				"source":  "apicategorywithfallback",
+			})
+			return
+		}
+
+		if result.APIKey != nil {
+			c.Set(apiKeyIDContextKey, result.APIKey.ID)
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"apicategorywithfallback/internal/service"
+	"apicategorywithfallback/pkg/database"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// requestMappingRequest is the body of create and update requests
+type requestMappingRequest struct {
+	SourceName    string            `json:"source_name" binding:"required"`
+	EndpointPath  string            `json:"endpoint_path" binding:"required"`
+	ParamRenames  map[string]string `json:"param_renames"`
+	DefaultParams map[string]string `json:"default_params"`
+	PathSuffix    string            `json:"path_suffix"`
+	Headers       map[string]string `json:"headers"`
+	IsActive      *bool             `json:"is_active"` // Optional, defaults to true
+}
+
+func (r *requestMappingRequest) mapping(id int) *database.RequestMapping {
+	isActive := true
+	if r.IsActive != nil {
+		isActive = *r.IsActive
+	}
+
+	return &database.RequestMapping{
+		ID:            id,
+		SourceName:    r.SourceName,
+		EndpointPath:  r.EndpointPath,
+		ParamRenames:  r.ParamRenames,
+		DefaultParams: r.DefaultParams,
+		PathSuffix:    r.PathSuffix,
+		Headers:       r.Headers,
+		IsActive:      isActive,
+	}
+}
+
+// GetRequestMappings returns all per-source request mappings
+func (h *DashboardHandler) GetRequestMappings(c *gin.Context) {
+	mappings, err := h.apiService.GetRequestMappings()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to get request mappings",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"data":   mappings,
+		"count":  len(mappings),
+	})
+}
+
+// CreateRequestMapping adds a request mapping for a source and endpoint
+func (h *DashboardHandler) CreateRequestMapping(c *gin.Context) {
+	var req requestMappingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	mapping := req.mapping(0)
+	if err := h.apiService.CreateRequestMapping(mapping); err != nil {
+		c.JSON(requestMappingErrorStatus(err), gin.H{
+			"error":   "Failed to create request mapping",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"status":  "success",
+		"message": "Request mapping created successfully",
+		"data":    mapping,
+	})
+}
+
+// UpdateRequestMapping replaces the rules of a request mapping
+func (h *DashboardHandler) UpdateRequestMapping(c *gin.Context) {
+	id, ok := parseIDParam(c, "id", "Invalid request mapping ID")
+	if !ok {
+		return
+	}
+
+	var req requestMappingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	mapping := req.mapping(id)
+	if err := h.apiService.UpdateRequestMapping(mapping); err != nil {
+		c.JSON(requestMappingErrorStatus(err), gin.H{
+			"error":   "Failed to update request mapping",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Request mapping updated successfully",
+		"data":    mapping,
+	})
+}
+
+// DeleteRequestMapping removes a request mapping
+func (h *DashboardHandler) DeleteRequestMapping(c *gin.Context) {
+	id, ok := parseIDParam(c, "id", "Invalid request mapping ID")
+	if !ok {
+		return
+	}
+
+	if err := h.apiService.DeleteRequestMapping(id); err != nil {
+		c.JSON(requestMappingErrorStatus(err), gin.H{
+			"error":   "Failed to delete request mapping",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Request mapping deleted successfully",
+	})
+}
+
+// requestMappingErrorStatus maps validation errors to 400 and unknown mappings to 404
+func requestMappingErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrInvalidRequestMapping):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrRequestMappingNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
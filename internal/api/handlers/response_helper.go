@@ -27,9 +27,12 @@ func createEnhancedResponse(ctx *domain.RequestContext, response *domain.APIResp
 	totalTime := time.Since(startTime)
 
 	// Determine cache status
-	cacheStatus := "MISS"
-	if response.SourceName == "cache" {
-		cacheStatus = "HIT"
+	cacheStatus := response.CacheStatus
+	if cacheStatus == "" {
+		cacheStatus = "MISS"
+		if response.SourceName == "cache" {
+			cacheStatus = "HIT"
+		}
 	}
 
 	// Create filter description
@@ -58,6 +61,7 @@ func createEnhancedResponse(ctx *domain.RequestContext, response *domain.APIResp
 			TotalTime:     totalTime.String(),
 			Attempts:      attempts,
 			CacheStatus:   cacheStatus,
+			Coalesced:     response.Coalesced,
 			Timestamp:     time.Now().Format(time.RFC3339),
 		},
 	}
@@ -74,6 +78,9 @@ func sendEnhancedResponse(c *gin.Context, enhancedResponse *domain.EnhancedRespo
 	c.Header("X-Category", enhancedResponse.Metadata.Category)
 	c.Header("X-Total-Time", enhancedResponse.Metadata.TotalTime)
 	c.Header("X-Attempts", fmt.Sprintf("%d", enhancedResponse.Metadata.Attempts))
+	if enhancedResponse.Metadata.Coalesced {
+		c.Header("X-Coalesced", "true")
+	}
 	c.Header("X-All-Sources", fmt.Sprintf("%v", enhancedResponse.Metadata.AllSources))
 
 	c.JSON(200, enhancedResponse)
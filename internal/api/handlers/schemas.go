@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"apicategorywithfallback/internal/service"
+	"apicategorywithfallback/pkg/database"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// endpointSchemaRequest is the body of schema save requests
+type endpointSchemaRequest struct {
+	EndpointPath string          `json:"endpoint_path" binding:"required"`
+	Schema       json.RawMessage `json:"schema" binding:"required"` // JSON Schema object
+	IsActive     *bool           `json:"is_active"`                 // Optional, defaults to true
+}
+
+// GetSchemas returns the response schema in use for each endpoint and the schemas stored in the database
+func (h *DashboardHandler) GetSchemas(c *gin.Context) {
+	stored, err := h.apiService.GetEndpointSchemas()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to get endpoint schemas",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"data": gin.H{
+			"effective": h.apiService.GetEffectiveSchemas(),
+			"stored":    stored,
+		},
+	})
+}
+
+// SaveSchema stores the response schema of an endpoint, overriding its file or built-in schema
+func (h *DashboardHandler) SaveSchema(c *gin.Context) {
+	var req endpointSchemaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	isActive := true
+	if req.IsActive != nil {
+		isActive = *req.IsActive
+	}
+	schema := &database.EndpointSchema{
+		EndpointPath: req.EndpointPath,
+		Schema:       string(req.Schema),
+		IsActive:     isActive,
+	}
+	if err := h.apiService.SaveEndpointSchema(schema); err != nil {
+		c.JSON(endpointSchemaErrorStatus(err), gin.H{
+			"error":   "Failed to save endpoint schema",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Endpoint schema saved successfully",
+		"data":    schema,
+	})
+}
+
+// DeleteSchema removes a stored endpoint schema
+func (h *DashboardHandler) DeleteSchema(c *gin.Context) {
+	id, ok := parseIDParam(c, "id", "Invalid endpoint schema ID")
+	if !ok {
+		return
+	}
+
+	if err := h.apiService.DeleteEndpointSchema(id); err != nil {
+		c.JSON(endpointSchemaErrorStatus(err), gin.H{
+			"error":   "Failed to delete endpoint schema",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Endpoint schema deleted successfully",
+	})
+}
+
+// endpointSchemaErrorStatus maps validation errors to 400 and unknown schemas to 404
+func endpointSchemaErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrInvalidEndpointSchema):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrEndpointSchemaNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
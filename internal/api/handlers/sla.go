@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"apicategorywithfallback/internal/service"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetSLAReports returns the uptime, MTTR and incident timeline of each source
+// over the window given by ?window= (24h, 7d or 30d; default 24h), optionally
+// only for ?source=
+func (h *DashboardHandler) GetSLAReports(c *gin.Context) {
+	window := c.DefaultQuery("window", "24h")
+	reports, err := h.apiService.GetSLAReports(window, c.Query("source"))
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, service.ErrInvalidSLAWindow) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{
+			"error":   "Failed to get SLA reports",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"window": window,
+		"data":   reports,
+		"count":  len(reports),
+	})
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"apicategorywithfallback/internal/service"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetRoutingOverride returns the routing override of an API source
+func (h *DashboardHandler) GetRoutingOverride(c *gin.Context) {
+	sourceID, ok := parseIDParam(c, "id", "Invalid API source ID")
+	if !ok {
+		return
+	}
+
+	override, err := h.apiService.GetRoutingOverride(sourceID)
+	if err != nil {
+		c.JSON(routingOverrideErrorStatus(err), gin.H{
+			"error":   "Failed to get routing override",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"data": gin.H{
+			"api_source_id":    sourceID,
+			"routing_override": override,
+		},
+	})
+}
+
+// UpdateRoutingOverride forces routing to an API source on or off, or hands it back to the health checks
+func (h *DashboardHandler) UpdateRoutingOverride(c *gin.Context) {
+	sourceID, ok := parseIDParam(c, "id", "Invalid API source ID")
+	if !ok {
+		return
+	}
+
+	var req struct {
+		RoutingOverride string `json:"routing_override" binding:"required"` // auto, force_enable or force_disable
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if err := h.apiService.SetRoutingOverride(sourceID, req.RoutingOverride); err != nil {
+		c.JSON(routingOverrideErrorStatus(err), gin.H{
+			"error":   "Failed to update routing override",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Routing override updated successfully",
+	})
+}
+
+// routingOverrideErrorStatus maps unknown overrides to 400 and unknown sources to 404
+func routingOverrideErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrInvalidRoutingOverride):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrAPISourceNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
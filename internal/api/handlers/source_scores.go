@@ -0,0 +1,19 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetSourceScores returns the adaptive ordering scores of each source per endpoint
+func (h *DashboardHandler) GetSourceScores(c *gin.Context) {
+	enabled, scores := h.apiService.GetSourceScores()
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"enabled": enabled,
+		"data":    scores,
+		"count":   len(scores),
+	})
+}
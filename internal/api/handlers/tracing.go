@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"apicategorywithfallback/pkg/tracing"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracingMiddleware starts a root span per request, continuing the caller's
+// trace when a traceparent header is present, and returns the trace ID in X-Trace-ID
+func TracingMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := routeLabel(c)
+
+		ctx := tracing.Extract(c.Request.Context(), c.Request.Header)
+		ctx, span := tracing.Start(ctx, c.Request.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request.method", c.Request.Method),
+				attribute.String("http.route", route),
+				attribute.String("url.path", c.Request.URL.Path),
+				attribute.String("client.address", c.ClientIP()),
+			),
+		)
+		defer span.End()
+
+		if traceID := tracing.TraceID(ctx); traceID != "" {
+			c.Header("X-Trace-ID", traceID)
+		}
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+
+		// Dynamic routes are only resolved once the request has been handled
+		if resolved := routeLabel(c); resolved != route {
+			span.SetName(c.Request.Method + " " + resolved)
+			span.SetAttributes(attribute.String("http.route", resolved))
+		}
+
+		status := c.Writer.Status()
+		span.SetAttributes(attribute.Int("http.response.status_code", status))
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"apicategorywithfallback/internal/service"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetTrafficReport returns the request counts, success rate and response
+// times over the window given by ?window= (24h, 7d or 30d; default 24h),
+// broken down by ?group_by= (endpoint, category or source; default endpoint)
+func (h *DashboardHandler) GetTrafficReport(c *gin.Context) {
+	report, err := h.apiService.GetTrafficReport(c.DefaultQuery("window", "24h"), c.DefaultQuery("group_by", "endpoint"))
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, service.ErrInvalidSLAWindow) || errors.Is(err, service.ErrInvalidTrafficGroup) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{
+			"error":   "Failed to get traffic report",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"data":   report,
+	})
+}
+
+// RunMaintenance refreshes the request rollups and prunes old rows now instead
+// of waiting for the next scheduled run
+func (h *DashboardHandler) RunMaintenance(c *gin.Context) {
+	result, err := h.apiService.RunMaintenance()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Database maintenance failed",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Database maintenance completed",
+		"data":    result,
+	})
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"apicategorywithfallback/internal/service"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetTransform returns the response transform spec of an API source
+func (h *DashboardHandler) GetTransform(c *gin.Context) {
+	sourceID, ok := parseIDParam(c, "id", "Invalid API source ID")
+	if !ok {
+		return
+	}
+
+	spec, err := h.apiService.GetTransformSpec(sourceID)
+	if err != nil {
+		c.JSON(transformErrorStatus(err), gin.H{
+			"error":   "Failed to get transform",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"data": gin.H{
+			"api_source_id":  sourceID,
+			"transform_spec": spec,
+		},
+	})
+}
+
+// UpdateTransform sets or clears the response transform spec of an API source
+func (h *DashboardHandler) UpdateTransform(c *gin.Context) {
+	sourceID, ok := parseIDParam(c, "id", "Invalid API source ID")
+	if !ok {
+		return
+	}
+
+	var req struct {
+		TransformSpec string `json:"transform_spec"` // Empty removes the transform
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if err := h.apiService.SetTransformSpec(sourceID, req.TransformSpec); err != nil {
+		c.JSON(transformErrorStatus(err), gin.H{
+			"error":   "Failed to update transform",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Transform updated successfully",
+	})
+}
+
+// TestTransform runs a transform spec against a pasted sample payload
+func (h *DashboardHandler) TestTransform(c *gin.Context) {
+	var req struct {
+		TransformSpec string `json:"transform_spec"`
+		Payload       string `json:"payload" binding:"required"`
+		Endpoint      string `json:"endpoint"` // Optional, validates the output for this endpoint
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	result, err := h.apiService.TestTransformSpec(req.TransformSpec, req.Payload, req.Endpoint)
+	if err != nil {
+		c.JSON(transformErrorStatus(err), gin.H{
+			"error":   "Failed to apply transform",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"data":   result,
+	})
+}
+
+// transformErrorStatus maps invalid specs to 400 and unknown sources to 404
+func transformErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrInvalidTransformSpec):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrAPISourceNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
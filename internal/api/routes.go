@@ -3,16 +3,22 @@ package api
 import (
 	"apicategorywithfallback/internal/api/handlers"
 	"apicategorywithfallback/internal/service"
+	"apicategorywithfallback/pkg/auth"
 
 	"github.com/gin-gonic/gin"
 )
 
 func SetupRoutes(router *gin.Engine, apiService *service.APIService) {
+	// Record request metrics and traces for every route
+	router.Use(handlers.MetricsMiddleware())
+	router.Use(handlers.TracingMiddleware())
+
 	// Add CORS middleware
 	router.Use(func(c *gin.Context) {
 		c.Header("Access-Control-Allow-Origin", "*")
 		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization, X-API-Key")
+		c.Header("Access-Control-Expose-Headers", "X-RateLimit-Limit, X-RateLimit-Remaining, X-RateLimit-Reset, X-Quota-Limit, X-Quota-Remaining, Retry-After, X-Trace-ID, X-Included-Sources, X-Late-Sources")
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
@@ -26,9 +32,11 @@ func SetupRoutes(router *gin.Engine, apiService *service.APIService) {
 	apiHandler := handlers.NewAPIHandler(apiService)
 	dashboardHandler := handlers.NewDashboardHandler(apiService)
 	swaggerHandler := handlers.NewSwaggerHandler(apiService)
+	authHandler := handlers.NewAuthHandler(apiService)
 
 	// API routes
 	v1 := router.Group("/api/v1")
+	v1.Use(apiHandler.RateLimitMiddleware())
 	{
 		// Handle both with and without trailing slashes
 		v1.GET("/home", apiHandler.HandleHome)
@@ -48,42 +56,111 @@ func SetupRoutes(router *gin.Engine, apiService *service.APIService) {
 		v1.GET("/search/", apiHandler.HandleSearch)
 	}
 
+	// Endpoints configured in the database without a static route above,
+	// including path templates such as /api/v1/anime/:slug
+	router.NoRoute(apiHandler.ResolveDynamicRoute, apiHandler.RateLimitMiddleware(), apiHandler.HandleDynamicRoute)
+
 	// Dashboard routes
 	dashboard := router.Group("/dashboard")
 	{
-		dashboard.GET("/", dashboardHandler.ShowDashboard)
-		dashboard.GET("/enhanced", dashboardHandler.ShowEnhancedDashboard)
-		dashboard.GET("/management", dashboardHandler.ShowManagement)
-		dashboard.GET("/health", dashboardHandler.GetHealthStatus)
-		dashboard.POST("/health/check", dashboardHandler.RunManualHealthCheck)
-		dashboard.GET("/logs", dashboardHandler.GetRequestLogs)
-		dashboard.GET("/stats", dashboardHandler.GetStatistics)
-
-		// API management routes
-		dashboard.GET("/categories", dashboardHandler.GetCategories)
-		dashboard.POST("/categories", dashboardHandler.CreateCategory)
-		dashboard.PUT("/categories/:id", dashboardHandler.UpdateCategory)
-		dashboard.DELETE("/categories/:id", dashboardHandler.DeleteCategory)
-
-		// Endpoints management routes
-		dashboard.GET("/endpoints", dashboardHandler.GetEndpoints)
-		dashboard.POST("/endpoints", dashboardHandler.CreateEndpoint)
-		dashboard.PUT("/endpoints/:id", dashboardHandler.UpdateEndpoint)
-		dashboard.DELETE("/endpoints/:id", dashboardHandler.DeleteEndpoint)
-
-		// API Sources management routes
-		dashboard.GET("/api-sources", dashboardHandler.GetAPISources)
-		dashboard.POST("/api-sources", dashboardHandler.CreateAPISource)
-		dashboard.POST("/api-sources/bulk", dashboardHandler.CreateAPISourceForAllEndpoints)
-		dashboard.PUT("/api-sources/:id", dashboardHandler.UpdateAPISource)
-		dashboard.DELETE("/api-sources/:id", dashboardHandler.DeleteAPISource)
-
-		// Bulk API Sources management
-		dashboard.GET("/api-sources/by-name", dashboardHandler.GetAPISourcesByName)
-		dashboard.DELETE("/api-sources/by-name", dashboardHandler.DeleteAPISourceByName)
-
-		// Cache management routes
-		dashboard.DELETE("/cache/clear", apiHandler.HandleClearCache)
+		dashboard.GET("/login", authHandler.ShowLogin)
+		dashboard.POST("/login", authHandler.Login)
+		dashboard.POST("/logout", authHandler.Logout)
+	}
+
+	// Read-only dashboard access
+	viewer := dashboard.Group("", authHandler.RequireRole(auth.RoleViewer))
+	{
+		viewer.GET("/", dashboardHandler.ShowDashboard)
+		viewer.GET("/enhanced", dashboardHandler.ShowEnhancedDashboard)
+		viewer.GET("/management", dashboardHandler.ShowManagement)
+		viewer.GET("/me", authHandler.GetCurrentPrincipal)
+		viewer.GET("/health", dashboardHandler.GetHealthStatus)
+		viewer.GET("/logs", dashboardHandler.GetRequestLogs)
+		viewer.GET("/stats", dashboardHandler.GetStatistics)
+		viewer.GET("/categories", dashboardHandler.GetCategories)
+		viewer.GET("/endpoints", dashboardHandler.GetEndpoints)
+		viewer.GET("/api-sources", dashboardHandler.GetAPISources)
+		viewer.GET("/api-sources/by-name", dashboardHandler.GetAPISourcesByName)
+		viewer.GET("/api-sources/:id/fallbacks", dashboardHandler.GetFallbacks)
+		viewer.GET("/api-sources/:id/transform", dashboardHandler.GetTransform)
+		viewer.GET("/api-sources/:id/routing", dashboardHandler.GetRoutingOverride)
+		viewer.GET("/request-mappings", dashboardHandler.GetRequestMappings)
+		viewer.GET("/health-probes", dashboardHandler.GetHealthProbes)
+		viewer.POST("/transforms/test", dashboardHandler.TestTransform)
+		viewer.GET("/schemas", dashboardHandler.GetSchemas)
+		viewer.GET("/confidence-policies", dashboardHandler.GetConfidencePolicies)
+		viewer.GET("/canonical-titles", dashboardHandler.GetCanonicalTitles)
+		viewer.GET("/source-scores", dashboardHandler.GetSourceScores)
+		viewer.GET("/sla", dashboardHandler.GetSLAReports)
+		viewer.GET("/traffic", dashboardHandler.GetTrafficReport)
+	}
+
+	// Creating and updating configuration
+	operator := dashboard.Group("", authHandler.RequireRole(auth.RoleOperator))
+	{
+		operator.POST("/health/check", dashboardHandler.RunManualHealthCheck)
+		operator.POST("/categories", dashboardHandler.CreateCategory)
+		operator.PUT("/categories/:id", dashboardHandler.UpdateCategory)
+		operator.POST("/endpoints", dashboardHandler.CreateEndpoint)
+		operator.PUT("/endpoints/:id", dashboardHandler.UpdateEndpoint)
+		operator.POST("/api-sources", dashboardHandler.CreateAPISource)
+		operator.POST("/api-sources/bulk", dashboardHandler.CreateAPISourceForAllEndpoints)
+		operator.PUT("/api-sources/:id", dashboardHandler.UpdateAPISource)
+		operator.POST("/api-sources/:id/fallbacks", dashboardHandler.CreateFallback)
+		operator.PUT("/api-sources/:id/fallbacks/order", dashboardHandler.ReorderFallbacks)
+		operator.PUT("/api-sources/:id/fallbacks/:fallback_id", dashboardHandler.UpdateFallback)
+		operator.POST("/api-sources/:id/fallbacks/:fallback_id/enable", dashboardHandler.EnableFallback)
+		operator.POST("/api-sources/:id/fallbacks/:fallback_id/disable", dashboardHandler.DisableFallback)
+		operator.PUT("/api-sources/:id/transform", dashboardHandler.UpdateTransform)
+		operator.PUT("/api-sources/:id/routing", dashboardHandler.UpdateRoutingOverride)
+		operator.POST("/request-mappings", dashboardHandler.CreateRequestMapping)
+		operator.PUT("/request-mappings/:id", dashboardHandler.UpdateRequestMapping)
+		operator.POST("/health-probes", dashboardHandler.CreateHealthProbe)
+		operator.PUT("/health-probes/:id", dashboardHandler.UpdateHealthProbe)
+		operator.PUT("/schemas", dashboardHandler.SaveSchema)
+		operator.POST("/confidence-policies", dashboardHandler.CreateConfidencePolicy)
+		operator.PUT("/confidence-policies/:id", dashboardHandler.UpdateConfidencePolicy)
+		operator.POST("/canonical-titles", dashboardHandler.CreateCanonicalTitle)
+		operator.PUT("/canonical-titles/:id", dashboardHandler.UpdateCanonicalTitle)
+		operator.DELETE("/cache/clear", apiHandler.HandleClearCache)
+		// Channel URLs carry webhook and bot tokens, so viewers cannot list them
+		operator.GET("/notification-channels", dashboardHandler.GetNotificationChannels)
+		operator.POST("/notification-channels", dashboardHandler.CreateNotificationChannel)
+		operator.PUT("/notification-channels/:id", dashboardHandler.UpdateNotificationChannel)
+		operator.POST("/notification-channels/:id/test", dashboardHandler.TestNotificationChannel)
+	}
+
+	// Deleting configuration and managing access
+	admin := dashboard.Group("", authHandler.RequireRole(auth.RoleAdmin))
+	{
+		admin.DELETE("/categories/:id", dashboardHandler.DeleteCategory)
+		admin.DELETE("/endpoints/:id", dashboardHandler.DeleteEndpoint)
+		admin.DELETE("/api-sources/:id", dashboardHandler.DeleteAPISource)
+		admin.DELETE("/api-sources/by-name", dashboardHandler.DeleteAPISourceByName)
+		admin.DELETE("/api-sources/:id/fallbacks/:fallback_id", dashboardHandler.DeleteFallback)
+		admin.DELETE("/request-mappings/:id", dashboardHandler.DeleteRequestMapping)
+		admin.DELETE("/health-probes/:id", dashboardHandler.DeleteHealthProbe)
+		admin.DELETE("/schemas/:id", dashboardHandler.DeleteSchema)
+		admin.DELETE("/confidence-policies/:id", dashboardHandler.DeleteConfidencePolicy)
+		admin.DELETE("/canonical-titles/:id", dashboardHandler.DeleteCanonicalTitle)
+		admin.DELETE("/notification-channels/:id", dashboardHandler.DeleteNotificationChannel)
+		admin.POST("/maintenance", dashboardHandler.RunMaintenance)
+
+		// API key management routes
+		admin.GET("/api-keys", dashboardHandler.GetAPIKeys)
+		admin.POST("/api-keys", dashboardHandler.CreateAPIKey)
+		admin.PUT("/api-keys/:id", dashboardHandler.UpdateAPIKey)
+		admin.DELETE("/api-keys/:id", dashboardHandler.DeleteAPIKey)
+
+		// Dashboard user and token management routes
+		admin.GET("/users", authHandler.GetUsers)
+		admin.POST("/users", authHandler.CreateUser)
+		admin.PUT("/users/:id", authHandler.UpdateUser)
+		admin.DELETE("/users/:id", authHandler.DeleteUser)
+		admin.GET("/tokens", authHandler.GetTokens)
+		admin.POST("/tokens", authHandler.CreateToken)
+		admin.DELETE("/tokens/:id", authHandler.DeleteToken)
 	}
 
 	// Public API routes for system information
@@ -99,6 +176,9 @@ func SetupRoutes(router *gin.Engine, apiService *service.APIService) {
 	// Health check endpoint
 	router.GET("/health", apiHandler.HandleHealthCheck)
 
+	// Prometheus metrics
+	router.GET("/metrics", handlers.MetricsHandler(apiService.MetricsToken()))
+
 	// Serve static files for dashboard
 	router.Static("/static", "./web/static")
 	router.LoadHTMLGlob("web/templates/*")
@@ -37,6 +37,10 @@ type APIResponse struct {
 	AllSourcesAttempted []string // All API sources that were attempted
 	TotalAttempts       int      // Total number of attempts made
 	ActualSourceURL     string   // The actual URL that was called successfully
+	Coalesced           bool     // Response was shared from another caller's in-flight fetch
+	CacheStatus         string   // HIT, MISS, STALE (empty when not determined)
+	IncludedSources     []string // Sources whose data a hedged response includes
+	LateSources         []string // Sources a hedged response did not wait for
 }
 
 // EnhancedResponse represents an enhanced response with source metadata
@@ -73,8 +77,9 @@ type ResponseMetadata struct {
 	Attempts     int    `json:"attempts"`      // Number of API calls made
 
 	// Cache information
-	CacheStatus string `json:"cache_status"`        // HIT, MISS, BYPASS
+	CacheStatus string `json:"cache_status"`        // HIT, MISS, STALE, BYPASS
 	CacheKey    string `json:"cache_key,omitempty"` // Cache key used (optional)
+	Coalesced   bool   `json:"coalesced"`           // Response was shared from an identical in-flight request
 
 	// Request timestamp
 	Timestamp string `json:"timestamp"` // When the request was made
@@ -104,11 +109,22 @@ type RequestContext struct {
 	Endpoint   string
 	Category   string
 	Parameters map[string]string
+	Route      string            // Matched endpoint template, e.g. /api/v1/jadwal-rilis/:day (empty = Endpoint)
+	PathParams map[string]string // Values of the route's path parameters
 	ClientIP   string
 	UserAgent  string
+	APIKeyID   int // 0 for anonymous requests
 	StartTime  time.Time
 }
 
+// RoutePath returns the endpoint template used to look up sources and label metrics
+func (r *RequestContext) RoutePath() string {
+	if r.Route != "" {
+		return r.Route
+	}
+	return r.Endpoint
+}
+
 // FallbackResult represents the result of a fallback operation
 type FallbackResult struct {
 	Success      bool
@@ -122,7 +138,7 @@ type FallbackResult struct {
 type HealthStatus struct {
 	APISourceID  int
 	SourceName   string
-	Status       string // OK, TIMEOUT, ERROR
+	Status       string // OK, DEGRADED, TIMEOUT, ERROR
 	ResponseTime int
 	ErrorMessage string
 	LastChecked  time.Time
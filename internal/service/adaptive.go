@@ -0,0 +1,144 @@
+package service
+
+import (
+	"apicategorywithfallback/internal/domain"
+	"apicategorywithfallback/pkg/adaptive"
+	"apicategorywithfallback/pkg/config"
+	"apicategorywithfallback/pkg/database"
+	"apicategorywithfallback/pkg/logger"
+	"apicategorywithfallback/pkg/metrics"
+	"context"
+	"sort"
+	"time"
+)
+
+// newAdaptiveTracker returns the source score tracker, or nil when adaptive ordering is disabled
+func newAdaptiveTracker(cfg *config.Config) *adaptive.Tracker {
+	if !cfg.AdaptiveOrderingEnabled {
+		return nil
+	}
+	return adaptive.New(cfg.AdaptiveWindow, cfg.AdaptiveMinSamples)
+}
+
+// observeAdaptive feeds a primary attempt into the source scores. Fallback
+// attempts and cancelled requests say nothing about the primary source.
+func (s *APIService) observeAdaptive(reqCtx *domain.RequestContext, source, role, outcome string, latency time.Duration) {
+	if s.adaptive == nil || role != metrics.RolePrimary {
+		return
+	}
+
+	switch outcome {
+	case metrics.OutcomeSuccess:
+		s.adaptive.Observe(source, reqCtx.RoutePath(), adaptive.OutcomeSuccess, latency)
+	case metrics.OutcomeInvalid:
+		s.adaptive.Observe(source, reqCtx.RoutePath(), adaptive.OutcomeInvalid, latency)
+	case metrics.OutcomeError:
+		s.adaptive.Observe(source, reqCtx.RoutePath(), adaptive.OutcomeError, latency)
+	}
+}
+
+// orderSources returns sources from the best to the worst adaptive score,
+// with static priority as the tiebreaker. Without adaptive ordering the
+// sources are returned in their static order.
+func (s *APIService) orderSources(sources []database.APISource, endpoint string) []database.APISource {
+	if s.adaptive == nil || len(sources) < 2 {
+		return sources
+	}
+
+	candidates := make([]adaptive.Candidate, len(sources))
+	for i, source := range sources {
+		candidates[i] = adaptive.Candidate{Name: source.SourceName, Priority: source.Priority}
+	}
+
+	ordered := make([]database.APISource, 0, len(sources))
+	for _, i := range s.adaptive.Order(endpoint, candidates) {
+		ordered = append(ordered, sources[i])
+	}
+	logger.Debugf("Adaptive source order for %s: %v", endpoint, sourceNames(ordered))
+	return ordered
+}
+
+// rankOutcomes returns the valid responses of a fan-out and the names of
+// their sources, ordered like sources (which orderSources ranked) so that the
+// best-scored source leads the combined response. Without adaptive ordering
+// they keep their arrival order.
+func (s *APIService) rankOutcomes(outcomes []sourceOutcome, sources []database.APISource) ([]*domain.APIResponse, []string) {
+	ranked := append([]sourceOutcome(nil), outcomes...)
+	if s.adaptive != nil {
+		rank := make(map[string]int, len(sources))
+		for i, source := range sources {
+			rank[source.SourceName] = i
+		}
+		sort.SliceStable(ranked, func(i, j int) bool { return rank[ranked[i].source] < rank[ranked[j].source] })
+	}
+
+	responses := make([]*domain.APIResponse, len(ranked))
+	names := make([]string, len(ranked))
+	for i, outcome := range ranked {
+		responses[i] = outcome.resp
+		names[i] = outcome.source
+	}
+	return responses, names
+}
+
+// awaitBetterRanked waits up to AdaptiveWait for bruteforce sources ranked
+// above best that have not answered yet, and returns the best-ranked valid
+// response. Without adaptive ordering best is returned at once.
+func (s *APIService) awaitBetterRanked(ctx context.Context, best *domain.APIResponse, sources []bruteforceSource, results <-chan *domain.APIResponse) *domain.APIResponse {
+	if s.adaptive == nil {
+		return best
+	}
+
+	pending := make(map[string]int, len(sources)) // Rank of each source that has not answered
+	for _, source := range sources {
+		pending[source.SourceName] = source.Priority
+	}
+	delete(pending, best.SourceName)
+
+	timer := time.NewTimer(s.config.AdaptiveWait)
+	defer timer.Stop()
+
+	for betterPending(pending, best.Priority) {
+		select {
+		case resp, ok := <-results:
+			if !ok {
+				return best
+			}
+			delete(pending, resp.SourceName)
+			if resp.Error == nil && resp.Data != nil && resp.Priority < best.Priority {
+				best = resp
+			}
+		case <-timer.C:
+			return best
+		case <-ctx.Done():
+			return best
+		}
+	}
+	return best
+}
+
+// betterPending reports whether a pending source is ranked above rank
+func betterPending(pending map[string]int, rank int) bool {
+	for _, priority := range pending {
+		if priority < rank {
+			return true
+		}
+	}
+	return false
+}
+
+// GetSourceScores returns the adaptive scores of every source and endpoint seen in the window
+func (s *APIService) GetSourceScores() (enabled bool, scores []adaptive.Stats) {
+	if s.adaptive == nil {
+		return false, nil
+	}
+	return true, s.adaptive.Snapshot()
+}
+
+func sourceNames(sources []database.APISource) []string {
+	names := make([]string, len(sources))
+	for i, source := range sources {
+		names[i] = source.SourceName
+	}
+	return names
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"apicategorywithfallback/internal/domain"
+	"apicategorywithfallback/pkg/adaptive"
+	"apicategorywithfallback/pkg/config"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// newAdaptiveService returns a service with adaptive ordering in which beta
+// scores far above alpha on endpoint
+func newAdaptiveService(t *testing.T, endpoint string, upstreams map[string]string) *APIService {
+	t.Helper()
+
+	service := newUpstreamService(t, &config.Config{
+		AdaptiveOrderingEnabled: true,
+		AdaptiveWindow:          time.Minute,
+		AdaptiveMinSamples:      1,
+		AdaptiveWait:            2 * time.Second,
+	}, upstreams)
+	for i := 0; i < 20; i++ {
+		service.adaptive.Observe("alpha", endpoint, adaptive.OutcomeError, 10*time.Millisecond)
+		service.adaptive.Observe("beta", endpoint, adaptive.OutcomeSuccess, 10*time.Millisecond)
+	}
+	return service
+}
+
+// delayedUpstream answers every request with body after delay
+func delayedUpstream(delay time.Duration, body []byte) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(body)
+	}))
+}
+
+// animeDetailBody is a valid /api/v1/anime-detail response from a source
+func animeDetailBody(source string) []byte {
+	body, _ := json.Marshal(map[string]interface{}{
+		"confidence_score": 0.9,
+		"message":          "success",
+		"source":           source,
+		"judul":            source,
+		"url":              "https://" + source + ".test/anime/1",
+		"anime_slug":       source,
+		"cover":            "https://" + source + ".test/cover.jpg",
+	})
+	return body
+}
+
+func TestAdaptiveDetailPicksHigherScoredSource(t *testing.T) {
+	alpha := delayedUpstream(0, animeDetailBody("alpha"))
+	defer alpha.Close()
+	beta := delayedUpstream(100*time.Millisecond, animeDetailBody("beta"))
+	defer beta.Close()
+
+	service := newAdaptiveService(t, "/api/v1/anime-detail", map[string]string{"alpha": alpha.URL, "beta": beta.URL})
+
+	response, err := service.ProcessRequest(context.Background(), &domain.RequestContext{
+		Endpoint:   "/api/v1/anime-detail",
+		Category:   "anime",
+		Parameters: map[string]string{"id": "1"},
+		ClientIP:   "127.0.0.1",
+		UserAgent:  "test-agent",
+		StartTime:  time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("ProcessRequest failed: %v", err)
+	}
+
+	var data map[string]interface{}
+	if err := json.Unmarshal(response.Data, &data); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if data["judul"] != "beta" {
+		t.Errorf("Expected the higher-scored source beta to win over the faster alpha, got %v", data["judul"])
+	}
+}
+
+func TestAdaptiveAggregationPutsHigherScoredSourceFirst(t *testing.T) {
+	alpha := delayedUpstream(0, homeBody("alpha"))
+	defer alpha.Close()
+	beta := delayedUpstream(100*time.Millisecond, homeBody("beta"))
+	defer beta.Close()
+
+	service := newAdaptiveService(t, "/api/v1/home", map[string]string{"alpha": alpha.URL, "beta": beta.URL})
+
+	response, err := service.ProcessRequest(context.Background(), homeRequest())
+	if err != nil {
+		t.Fatalf("ProcessRequest failed: %v", err)
+	}
+
+	var data struct {
+		NewEps []struct {
+			Judul string `json:"judul"`
+		} `json:"new_eps"`
+	}
+	if err := json.Unmarshal(response.Data, &data); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if len(data.NewEps) != 2 || data.NewEps[0].Judul != "beta" {
+		t.Errorf("Expected beta's episode first, got %+v", data.NewEps)
+	}
+}
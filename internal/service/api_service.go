@@ -2,12 +2,24 @@ package service
 
 import (
 	"apicategorywithfallback/internal/domain"
+	"apicategorywithfallback/pkg/adaptive"
 	"apicategorywithfallback/pkg/cache"
+	"apicategorywithfallback/pkg/circuitbreaker"
 	"apicategorywithfallback/pkg/config"
 	"apicategorywithfallback/pkg/database"
+	"apicategorywithfallback/pkg/dedup"
 	"apicategorywithfallback/pkg/logger"
+	"apicategorywithfallback/pkg/merge"
+	"apicategorywithfallback/pkg/metrics"
+	"apicategorywithfallback/pkg/notifier"
+	"apicategorywithfallback/pkg/ratelimit"
+	"apicategorywithfallback/pkg/routing"
+	"apicategorywithfallback/pkg/tracing"
+	"apicategorywithfallback/pkg/transform"
 	"apicategorywithfallback/pkg/validator"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -16,15 +28,48 @@ import (
 	"sync"
 	"time"
 
-	"golang.org/x/time/rate"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sync/singleflight"
 )
 
 type APIService struct {
-	db          *database.DB
-	cache       cache.Cache
-	config      *config.Config
-	httpClient  *http.Client
-	rateLimiter *rate.Limiter
+	db               *database.DB
+	cache            cache.Cache
+	config           *config.Config
+	httpClient       *http.Client
+	limiter          *ratelimit.Limiter // per-client (API key or IP) request limits
+	apiKeys          apiKeyCache
+	breakers         *circuitbreaker.Registry
+	routes           ttlCache[map[string]*routing.Table]             // endpoints table by category, for path template matching
+	mappings         ttlCache[map[string]database.RequestMapping]    // per-source upstream request rules
+	transforms       ttlCache[map[int]*transform.Spec]               // per-source response transforms
+	schemas          ttlCache[*validator.Registry]                   // per-endpoint response schemas
+	confidence       ttlCache[map[string]validator.ConfidencePolicy] // per-source, per-endpoint confidence thresholds
+	detailStrategies merge.Strategies                                // field strategies of merged detail responses
+	dedupMatcher     dedup.Matcher                                   // decides which aggregated list items are the same title
+	adaptive         *adaptive.Tracker                               // recent source scores; nil unless adaptive ordering is enabled
+	routing          ttlCache[map[int]database.SourceRouting]        // routing overrides and latest health by API source ID
+	probes           ttlCache[map[string]healthProbeEntry]           // synthetic health check requests by endpoint
+	outcomes         outcomeBuffer                                   // live request outcomes not yet added to the hourly counts
+	notifier         *notifier.Notifier                              // sends alerts on source state changes
+	channels         ttlCache[[]notifier.Channel]                    // active alert destinations and their routing rules
+	slugs            ttlCache[map[string]*database.CanonicalTitle]   // canonical titles and their per-source slugs
+	slugWrites       slugGroupBuffer                                 // slug groups of aggregated lists waiting to be stored
+	inflight         singleflight.Group                              // coalesces identical upstream fetches by cache key
+	revalidating     sync.Map                                        // cache keys with a background refresh in progress
+	maintenance      sync.Mutex                                      // serializes rollup and pruning runs
+
+	fetchesMu sync.Mutex
+	fetches   map[string]*inflightFetch // cancellation state of coalesced fetches by cache key
+}
+
+// inflightFetch tracks the callers waiting on a coalesced fetch; the fetch is
+// cancelled once every caller has gone away
+type inflightFetch struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	waiters int
 }
 
 func NewAPIService(db *database.DB, cfg *config.Config) *APIService {
@@ -48,294 +93,348 @@ func NewAPIService(db *database.DB, cfg *config.Config) *APIService {
 		},
 	}
 
-	// Initialize rate limiter
-	rateLimiter := rate.NewLimiter(rate.Limit(cfg.RateLimit), cfg.RateLimit)
+	service := &APIService{
+		db:         db,
+		cache:      cacheInstance,
+		config:     cfg,
+		httpClient: httpClient,
+		limiter:    ratelimit.New(),
+		apiKeys:    apiKeyCache{entries: make(map[string]apiKeyCacheEntry)},
+		fetches:    make(map[string]*inflightFetch),
 
-	return &APIService{
-		db:          db,
-		cache:       cacheInstance,
-		config:      cfg,
-		httpClient:  httpClient,
-		rateLimiter: rateLimiter,
+		detailStrategies: detailMergeStrategies(cfg.DetailMergeStrategies),
+		dedupMatcher:     newDedupMatcher(cfg),
+		adaptive:         newAdaptiveTracker(cfg),
+		notifier:         notifier.New(&http.Client{Timeout: notificationTimeout}),
 	}
+
+	// Initialize per-source circuit breakers
+	service.breakers = service.newBreakerRegistry()
+
+	return service
 }
 
-// ProcessRequest handles incoming API requests with fallback mechanism
-func (s *APIService) ProcessRequest(ctx *domain.RequestContext) (*domain.APIResponse, error) {
+// ProcessRequest handles incoming API requests with fallback mechanism.
+// ctx is the inbound request's context: when it is cancelled (client disconnected)
+// the caller stops waiting, and the upstream fetch is cancelled once no caller needs it.
+func (s *APIService) ProcessRequest(ctx context.Context, reqCtx *domain.RequestContext) (*domain.APIResponse, error) {
 	startTime := time.Now()
 
-	// Check rate limit
-	if !s.rateLimiter.Allow() {
-		return nil, fmt.Errorf("rate limit exceeded")
-	}
+	trace.SpanFromContext(ctx).SetAttributes(
+		tracing.AttrEndpoint.String(reqCtx.Endpoint),
+		tracing.AttrCategory.String(reqCtx.Category),
+	)
 
 	// Generate cache key
-	cacheKey := s.cache.GenerateKey(ctx.Category, ctx.Endpoint, ctx.Parameters)
+	cacheKey := s.cache.GenerateKey(reqCtx.Category, reqCtx.Endpoint, reqCtx.Parameters)
 
-	// Try to get from cache first
-	if cachedData, err := s.cache.Get(cacheKey); err == nil && cachedData != nil {
-		logger.Infof("Cache hit for key: %s", cacheKey)
-
-		// Normalize cached data to ensure consistency
-		// Try to extract original source name from cached data
-		originalSource := s.extractSourceFromResponse(cachedData)
-		logger.Debugf("Extracted source from cached data: %s", originalSource)
-
-		normalizedCachedData, normErr := s.normalizeResponseStructure(cachedData, originalSource)
-		if normErr != nil {
-			logger.Warnf("Failed to normalize cached data: %v, using original", normErr)
-			normalizedCachedData = cachedData
-		} else {
-			logger.Infof("Successfully normalized cached data from original source: %s", originalSource)
-		}
+	// Try to get from cache first (stale entries are kept until their hard expiry)
+	_, cacheSpan := tracing.Start(ctx, "cache.lookup")
+	entry, err := s.cache.GetEntry(cacheKey)
+	if err != nil {
+		logger.Warnf("Cache lookup failed for key %s: %v", cacheKey, err)
+		entry = nil
+	}
+	switch {
+	case entry == nil:
+		cacheSpan.SetAttributes(tracing.AttrCacheResult.String(metrics.CacheMiss))
+	case entry.IsStale():
+		cacheSpan.SetAttributes(tracing.AttrCacheResult.String(metrics.CacheStale))
+	default:
+		cacheSpan.SetAttributes(tracing.AttrCacheResult.String(metrics.CacheHit))
+	}
+	tracing.End(cacheSpan, err)
 
-		return &domain.APIResponse{
-			Data:                normalizedCachedData,
-			StatusCode:          200,
-			ResponseTime:        time.Since(startTime),
-			SourceName:          "cache",
-			AllSourcesAttempted: []string{"cache"},
-			TotalAttempts:       1,
-			ActualSourceURL:     "cache",
-		}, nil
+	if entry != nil && !entry.IsStale() {
+		logger.Infof("Cache hit for key: %s", cacheKey)
+		metrics.ObserveCacheLookup(reqCtx.RoutePath(), s.metricCategory(reqCtx), metrics.CacheHit)
+		return s.cachedResponse(entry.Value, startTime, "HIT"), nil
 	}
 
-	// Handle "all" category to aggregate from all active categories
-	if ctx.Category == "all" {
-		return s.processAllCategories(ctx, startTime)
+	// Stale-while-revalidate: serve the stale entry and refresh it in the background
+	if entry != nil && s.config.CacheStaleWhileRevalidate {
+		logger.Infof("Serving stale cache for key: %s (age %s), revalidating in background", cacheKey, entry.Age().Round(time.Second))
+		s.revalidateInBackground(ctx, reqCtx, cacheKey)
+		metrics.ObserveCacheLookup(reqCtx.RoutePath(), s.metricCategory(reqCtx), metrics.CacheStale)
+		return s.cachedResponse(entry.Value, startTime, "STALE"), nil
 	}
 
-	// Get API sources for this endpoint and category
-	apiSources, err := s.db.GetAPISourcesByEndpoint(ctx.Endpoint, ctx.Category)
+	metrics.ObserveCacheLookup(reqCtx.RoutePath(), s.metricCategory(reqCtx), metrics.CacheMiss)
+	response, err := s.fetchCoalesced(ctx, reqCtx, cacheKey, startTime)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get API sources: %v", err)
+		// Stale-if-error: all sources failed but we still have an older copy
+		if entry != nil && s.config.CacheStaleIfError && ctx.Err() == nil {
+			logger.Warnf("Serving stale cache for key: %s (age %s) after upstream failure: %v", cacheKey, entry.Age().Round(time.Second), err)
+			metrics.ObserveCacheLookup(reqCtx.RoutePath(), s.metricCategory(reqCtx), metrics.CacheStaleIfError)
+			return s.cachedResponse(entry.Value, startTime, "STALE"), nil
+		}
+		return nil, err
 	}
 
-	if len(apiSources) == 0 {
-		return nil, fmt.Errorf("no API sources configured for endpoint %s in category %s", ctx.Endpoint, ctx.Category)
-	}
+	return response, nil
+}
 
-	// Log all sources retrieved from database
-	logger.Infof("Retrieved %d sources from database for %s in category %s:", len(apiSources), ctx.Endpoint, ctx.Category)
-	for _, source := range apiSources {
-		logger.Infof("  - %s (ID: %d, BaseURL: %s, IsPrimary: %t, IsActive: %t)",
-			source.SourceName, source.ID, source.BaseURL, source.IsPrimary, source.IsActive)
+// cachedResponse builds a response from cached data
+func (s *APIService) cachedResponse(cachedData []byte, startTime time.Time, cacheStatus string) *domain.APIResponse {
+	// Normalize cached data to ensure consistency
+	// Try to extract original source name from cached data
+	originalSource := s.extractSourceFromResponse(cachedData)
+	logger.Debugf("Extracted source from cached data: %s", originalSource)
+
+	normalizedCachedData, normErr := s.normalizeResponseStructure(cachedData, originalSource)
+	if normErr != nil {
+		logger.Warnf("Failed to normalize cached data: %v, using original", normErr)
+		normalizedCachedData = cachedData
+	} else {
+		logger.Infof("Successfully normalized cached data from original source: %s", originalSource)
 	}
 
-	// Collect all source names for metadata
-	var allSourceNames []string
-	for _, source := range apiSources {
-		allSourceNames = append(allSourceNames, source.SourceName)
+	return &domain.APIResponse{
+		Data:                normalizedCachedData,
+		StatusCode:          200,
+		ResponseTime:        time.Since(startTime),
+		SourceName:          "cache",
+		AllSourcesAttempted: []string{"cache"},
+		TotalAttempts:       1,
+		ActualSourceURL:     "cache",
+		CacheStatus:         cacheStatus,
 	}
+}
 
-	// Always try to get data from ALL primary sources (this is the main fix)
-	logger.Infof("Attempting to fetch from all %d primary sources for %s", len(apiSources), ctx.Endpoint)
-	result := s.tryAllPrimaryAPIsWithFallback(apiSources, ctx)
-
-	// Log the request
-	s.logRequest(ctx, result, time.Since(startTime))
-
-	if !result.Success {
-		return nil, fmt.Errorf("all API sources failed for endpoint %s", ctx.Endpoint)
+// revalidateInBackground refreshes a stale cache entry; only one refresh per key runs at a time.
+// The refresh gets its own trace, linked to the request that triggered it.
+func (s *APIService) revalidateInBackground(ctx context.Context, reqCtx *domain.RequestContext, cacheKey string) {
+	if _, running := s.revalidating.LoadOrStore(cacheKey, struct{}{}); running {
+		return
 	}
 
-	// Enhance response with metadata
-	if result.Response != nil {
-		result.Response.AllSourcesAttempted = allSourceNames
-		result.Response.TotalAttempts = len(apiSources)
-		if result.Response.SourceName != "cache" {
-			result.Response.ActualSourceURL = fmt.Sprintf("%s%s", result.Response.SourceName, ctx.Endpoint)
-		}
+	// Copy the request context so the refresh does not share state with the caller
+	reqCopy := *reqCtx
+	reqCopy.Parameters = make(map[string]string, len(reqCtx.Parameters))
+	for k, v := range reqCtx.Parameters {
+		reqCopy.Parameters[k] = v
 	}
 
-	// Cache successful response
-	if result.Response != nil && result.Response.Data != nil {
-		ttl := s.config.CacheTTL[ctx.Endpoint]
-		if ttl == 0 {
-			ttl = 15 * time.Minute // default TTL
-		}
+	link := trace.LinkFromContext(ctx)
 
-		if err := s.cache.Set(cacheKey, result.Response.Data, ttl); err != nil {
-			logger.Errorf("Failed to cache response: %v", err)
-		}
-	}
-
-	return result.Response, nil
-}
+	go func() {
+		defer s.revalidating.Delete(cacheKey)
 
-// tryAPISources attempts to get data from primary API sources
-func (s *APIService) tryAPISources(sources []database.APISource, ctx *domain.RequestContext) *domain.FallbackResult {
-	// Create channels for concurrent requests
-	resultChan := make(chan *domain.APIResponse, len(sources))
-	var wg sync.WaitGroup
+		// The refresh outlives the request that triggered it
+		refreshCtx, span := tracing.Start(context.Background(), "cache.revalidate", trace.WithLinks(link))
+		fetch := s.acquireFetch(refreshCtx, cacheKey)
+		defer s.releaseFetch(cacheKey, fetch)
 
-	// Start concurrent requests to all primary APIs
-	for _, source := range sources {
-		if !source.IsPrimary || !source.IsActive {
-			continue
+		_, err, _ := s.inflight.Do(cacheKey, func() (interface{}, error) {
+			return s.fetchAndCache(fetch.ctx, &reqCopy, cacheKey, time.Now())
+		})
+		tracing.End(span, err)
+		if err != nil {
+			logger.Warnf("Background revalidation failed for key %s: %v", cacheKey, err)
+		} else {
+			logger.Infof("Background revalidation refreshed key: %s", cacheKey)
 		}
+	}()
+}
 
-		wg.Add(1)
-		go func(src database.APISource) {
-			defer wg.Done()
-
-			url := s.buildURL(src.BaseURL, ctx.Endpoint, ctx.Parameters)
-			resp := s.makeAPIRequest(url, src.SourceName, false)
+// fetchCoalesced fetches from upstream, coalescing concurrent identical requests into a single fetch
+func (s *APIService) fetchCoalesced(ctx context.Context, reqCtx *domain.RequestContext, cacheKey string, startTime time.Time) (*domain.APIResponse, error) {
+	fetch := s.acquireFetch(ctx, cacheKey)
+	defer s.releaseFetch(cacheKey, fetch)
 
-			// Validate response
-			if resp.Error == nil && resp.Data != nil {
-				if err := validator.ValidateResponse(ctx.Endpoint, resp.Data); err != nil {
-					logger.Warnf("Validation failed for %s: %v", src.SourceName, err)
-					resp.Error = err
-				}
-			}
+	leader := false
+	resultChan := s.inflight.DoChan(cacheKey, func() (interface{}, error) {
+		leader = true
+		return s.fetchAndCache(fetch.ctx, reqCtx, cacheKey, startTime)
+	})
 
-			resultChan <- resp
-		}(source)
+	var shared interface{}
+	var err error
+	select {
+	case result := <-resultChan:
+		shared, err = result.Val, result.Err
+	case <-ctx.Done():
+		logger.Warnf("Request cancelled while waiting for upstream fetch (key: %s): %v", cacheKey, ctx.Err())
+		return nil, fmt.Errorf("request cancelled: %w", ctx.Err())
 	}
 
-	// Close channel when all goroutines complete
-	go func() {
-		wg.Wait()
-		close(resultChan)
-	}()
+	trace.SpanFromContext(ctx).SetAttributes(tracing.AttrCoalesced.Bool(!leader))
 
-	// Return the first successful response (based on priority)
-	var bestResponse *domain.APIResponse
-	var bestPriority int = 999999
-
-	for resp := range resultChan {
-		if resp.Error == nil {
-			// Find the priority of this source
-			for _, src := range sources {
-				if src.SourceName == resp.SourceName && src.Priority < bestPriority {
-					bestResponse = resp
-					bestPriority = src.Priority
-					break
-				}
-			}
+	if err != nil {
+		if !leader {
+			// The leader already logged its own request, log this one too
+			s.logRequest(reqCtx, &domain.FallbackResult{Success: false}, time.Since(startTime))
 		}
+		return nil, err
 	}
 
-	if bestResponse != nil {
-		return &domain.FallbackResult{
-			Success:      true,
-			Response:     bestResponse,
-			SourceUsed:   bestResponse.SourceName,
-			FallbackUsed: false,
-		}
+	sharedResponse, _ := shared.(*domain.APIResponse)
+	if sharedResponse == nil {
+		return nil, fmt.Errorf("no response received for endpoint %s", reqCtx.Endpoint)
 	}
 
-	return &domain.FallbackResult{Success: false}
-}
+	// Each caller gets its own copy so metadata can differ per request
+	response := *sharedResponse
+	if leader {
+		return &response, nil
+	}
 
-// tryFallbackAPIs attempts to get data from fallback APIs
-func (s *APIService) tryFallbackAPIs(sources []database.APISource, ctx *domain.RequestContext) *domain.FallbackResult {
-	for _, source := range sources {
-		if !source.IsActive {
-			continue
-		}
+	logger.Infof("Request coalesced with in-flight fetch for key: %s", cacheKey)
+	response.Coalesced = true
+	s.logRequest(reqCtx, &domain.FallbackResult{
+		Success:      true,
+		Response:     &response,
+		SourceUsed:   response.SourceName,
+		FallbackUsed: response.IsFallback,
+	}, time.Since(startTime))
 
-		// Get fallback APIs for this source
-		fallbacks, err := s.db.GetFallbackAPIs(source.ID)
-		if err != nil {
-			logger.Errorf("Failed to get fallback APIs for source %s: %v", source.SourceName, err)
-			continue
+	return &response, nil
+}
+
+// acquireFetch registers a caller of the coalesced fetch for cacheKey and returns
+// its shared state. The fetch context keeps the first caller's values but not its
+// cancellation, and is bounded by the per-request deadline budget.
+func (s *APIService) acquireFetch(ctx context.Context, cacheKey string) *inflightFetch {
+	s.fetchesMu.Lock()
+	defer s.fetchesMu.Unlock()
+
+	fetch, exists := s.fetches[cacheKey]
+	if !exists {
+		fetch = &inflightFetch{}
+		if s.config.RequestDeadline > 0 {
+			fetch.ctx, fetch.cancel = context.WithTimeout(context.WithoutCancel(ctx), s.config.RequestDeadline)
+		} else {
+			fetch.ctx, fetch.cancel = context.WithCancel(context.WithoutCancel(ctx))
 		}
+		s.fetches[cacheKey] = fetch
+	}
+	fetch.waiters++
 
-		// Try each fallback API
-		for _, fallback := range fallbacks {
-			url := s.buildURL(fallback.FallbackURL, ctx.Endpoint, ctx.Parameters)
-			resp := s.makeAPIRequest(url, source.SourceName, true)
+	return fetch
+}
 
-			// Validate response
-			if resp.Error == nil && resp.Data != nil {
-				if err := validator.ValidateResponse(ctx.Endpoint, resp.Data); err != nil {
-					logger.Warnf("Validation failed for fallback %s: %v", fallback.FallbackURL, err)
-					continue
-				}
+// releaseFetch unregisters a caller; the last one out cancels the fetch
+func (s *APIService) releaseFetch(cacheKey string, fetch *inflightFetch) {
+	s.fetchesMu.Lock()
+	defer s.fetchesMu.Unlock()
 
-				// Success with fallback
-				return &domain.FallbackResult{
-					Success:      true,
-					Response:     resp,
-					SourceUsed:   source.SourceName,
-					FallbackUsed: true,
-				}
-			}
-		}
+	fetch.waiters--
+	if fetch.waiters > 0 {
+		return
 	}
 
-	return &domain.FallbackResult{Success: false}
+	fetch.cancel()
+	if s.fetches[cacheKey] == fetch {
+		delete(s.fetches, cacheKey)
+		// A cancelled fetch may still be finishing; make new callers start a fresh one
+		s.inflight.Forget(cacheKey)
+	}
 }
 
-// tryAllAPISources attempts to get data from all primary API sources and aggregate results
-func (s *APIService) tryAllAPISources(sources []database.APISource, ctx *domain.RequestContext) *domain.FallbackResult {
-	// Create channels for concurrent requests
-	resultChan := make(chan *domain.APIResponse, len(sources))
-	var wg sync.WaitGroup
+// fetchAndCache fetches a response from the upstream sources and caches it on success
+func (s *APIService) fetchAndCache(ctx context.Context, reqCtx *domain.RequestContext, cacheKey string, startTime time.Time) (*domain.APIResponse, error) {
+	// Handle "all" category to aggregate from all active categories
+	if reqCtx.Category == "all" {
+		return s.processAllCategories(ctx, reqCtx, startTime)
+	}
 
-	// Start concurrent requests to all primary APIs
-	for _, source := range sources {
-		if !source.IsPrimary || !source.IsActive {
-			continue
-		}
+	// Get API sources for this endpoint and category
+	apiSources, err := s.db.GetAPISourcesByEndpoint(reqCtx.RoutePath(), reqCtx.Category)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API sources: %v", err)
+	}
 
-		wg.Add(1)
-		go func(src database.APISource) {
-			defer wg.Done()
+	if len(apiSources) == 0 {
+		return nil, fmt.Errorf("no API sources configured for endpoint %s in category %s", reqCtx.Endpoint, reqCtx.Category)
+	}
 
-			url := s.buildURL(src.BaseURL, ctx.Endpoint, ctx.Parameters)
-			resp := s.makeAPIRequest(url, src.SourceName, false)
+	// Log all sources retrieved from database
+	logger.Infof("Retrieved %d sources from database for %s in category %s:", len(apiSources), reqCtx.Endpoint, reqCtx.Category)
+	for _, source := range apiSources {
+		logger.Infof("  - %s (ID: %d, BaseURL: %s, IsPrimary: %t, IsActive: %t)",
+			source.SourceName, source.ID, source.BaseURL, source.IsPrimary, source.IsActive)
+	}
 
-			// Validate response
-			if resp.Error == nil && resp.Data != nil {
-				if err := validator.ValidateResponse(ctx.Endpoint, resp.Data); err != nil {
-					logger.Warnf("Validation failed for %s: %v", src.SourceName, err)
-					resp.Error = err
-				}
-			}
+	// Collect all source names for metadata
+	var allSourceNames []string
+	for _, source := range apiSources {
+		allSourceNames = append(allSourceNames, source.SourceName)
+	}
 
-			resultChan <- resp
-		}(source)
+	// Always try to get data from ALL primary sources (this is the main fix)
+	logger.Infof("Attempting to fetch from all %d primary sources for %s", len(apiSources), reqCtx.Endpoint)
+	// A hedged fetch may refresh the cache with the late sources' data before
+	// this fetch caches its own, smaller response; the refresh then wins
+	var cacheMu sync.Mutex
+	refreshed := false
+	result := s.tryAllPrimaryAPIsWithFallback(ctx, apiSources, reqCtx, func(late *domain.APIResponse) {
+		cacheMu.Lock()
+		defer cacheMu.Unlock()
+		refreshed = true
+		s.cacheResponse(cacheKey, reqCtx, late)
+	})
+
+	// Cancelled fetches (every caller went away) are not logged as failures
+	if !result.Success && errors.Is(ctx.Err(), context.Canceled) {
+		return nil, fmt.Errorf("upstream fetch cancelled for endpoint %s: %w", reqCtx.Endpoint, ctx.Err())
 	}
 
-	// Close channel when all goroutines complete
-	go func() {
-		wg.Wait()
-		close(resultChan)
-	}()
+	// Log the request
+	s.logRequest(reqCtx, result, time.Since(startTime))
+	s.observeResult(reqCtx, result)
 
-	// Collect all successful responses
-	var successfulResponses []*domain.APIResponse
-	for resp := range resultChan {
-		if resp.Error == nil {
-			successfulResponses = append(successfulResponses, resp)
+	if !result.Success {
+		event := notifier.Event{
+			Type:     notifier.EventAllSourcesFailed,
+			Endpoint: reqCtx.RoutePath(),
+			Category: reqCtx.Category,
+		}
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			event.Message = fmt.Sprintf("request deadline of %s exceeded", s.config.RequestDeadline)
+			s.notify(event)
+			return nil, fmt.Errorf("all API sources failed for endpoint %s: request deadline of %s exceeded", reqCtx.Endpoint, s.config.RequestDeadline)
 		}
+		s.notify(event)
+		return nil, fmt.Errorf("all API sources failed for endpoint %s", reqCtx.Endpoint)
 	}
 
-	if len(successfulResponses) == 0 {
-		return &domain.FallbackResult{Success: false}
+	// Enhance response with metadata
+	if result.Response != nil {
+		result.Response.AllSourcesAttempted = allSourceNames
+		result.Response.TotalAttempts = len(apiSources)
+		if result.Response.SourceName != "cache" {
+			result.Response.ActualSourceURL = fmt.Sprintf("%s%s", result.Response.SourceName, reqCtx.Endpoint)
+		}
 	}
 
-	// If only one successful response, return it
-	if len(successfulResponses) == 1 {
-		return &domain.FallbackResult{
-			Success:      true,
-			Response:     successfulResponses[0],
-			SourceUsed:   successfulResponses[0].SourceName,
-			FallbackUsed: false,
+	// Cache successful response
+	if result.Response != nil && result.Response.Data != nil {
+		result.Response.CacheStatus = "MISS"
+		cacheMu.Lock()
+		if !refreshed {
+			s.cacheResponse(cacheKey, reqCtx, result.Response)
 		}
+		cacheMu.Unlock()
 	}
 
-	// Aggregate multiple successful responses
-	aggregatedResponse := s.aggregateResponses(successfulResponses, ctx.Endpoint)
+	return result.Response, nil
+}
 
-	return &domain.FallbackResult{
-		Success:      true,
-		Response:     aggregatedResponse,
-		SourceUsed:   fmt.Sprintf("aggregated_%d_sources", len(successfulResponses)),
-		FallbackUsed: false,
+// cacheResponse stores a response under the endpoint's TTL
+func (s *APIService) cacheResponse(cacheKey string, reqCtx *domain.RequestContext, response *domain.APIResponse) {
+	ttl := s.config.CacheTTL[reqCtx.RoutePath()]
+	if ttl == 0 {
+		ttl = 15 * time.Minute // default TTL
+	}
+
+	// Keep the entry past its TTL so it can be served stale
+	hardTTL := ttl
+	if s.config.CacheStaleWhileRevalidate || s.config.CacheStaleIfError {
+		hardTTL += s.config.CacheStaleTTL
+	}
+
+	if err := s.cache.SetEntry(cacheKey, response.Data, ttl, hardTTL); err != nil {
+		logger.Errorf("Failed to cache response: %v", err)
 	}
 }
 
@@ -448,64 +547,60 @@ func (s *APIService) aggregateHomeData(result map[string]interface{}, responses
 	result["jadwal_rilis"] = allSchedules
 }
 
-// aggregateListData combines list data from multiple sources with deduplication
+// aggregateListData combines list data from multiple sources, merging items
+// that the configured dedup matcher considers the same title
 func (s *APIService) aggregateListData(result map[string]interface{}, responses []*domain.APIResponse, dataKey string) {
-	var allData []interface{}
-	seenItems := make(map[string]bool) // For deduplication based on unique identifiers
-
-	logger.Infof("🔄 AGGREGATION DEBUG: Starting aggregation of %d responses for dataKey='%s'", len(responses), dataKey)
+	deduplicator := dedup.New(s.dedupMatcher)
+	var unkeyed []interface{} // Items that are not objects cannot be deduplicated
+	total := 0
 
-	for i, resp := range responses {
+	for _, resp := range responses {
 		var data map[string]interface{}
 		if err := json.Unmarshal(resp.Data, &data); err != nil {
 			logger.Warnf("Failed to unmarshal response from %s: %v", resp.SourceName, err)
 			continue
 		}
 
-		logger.Infof("🔄 AGGREGATION DEBUG: Processing response %d from %s", i+1, resp.SourceName)
-
-		if listData, exists := data[dataKey]; exists {
-			logger.Infof("🔄 AGGREGATION DEBUG: Found '%s' field in response from %s", dataKey, resp.SourceName)
-			if list, ok := listData.([]interface{}); ok {
-				logger.Infof("🔄 AGGREGATION DEBUG: Response from %s has %d items", resp.SourceName, len(list))
-				for _, item := range list {
-					if itemMap, ok := item.(map[string]interface{}); ok {
-						// Create unique key based on available identifiers
-						var uniqueKey string
-						if slug, exists := itemMap["anime_slug"]; exists {
-							uniqueKey = fmt.Sprintf("%v", slug)
-						} else if judul, exists := itemMap["judul"]; exists {
-							uniqueKey = fmt.Sprintf("%v", judul)
-						} else if url, exists := itemMap["url"]; exists {
-							uniqueKey = fmt.Sprintf("%v", url)
-						} else {
-							// Fallback: use JSON representation as key
-							if jsonBytes, err := json.Marshal(item); err == nil {
-								uniqueKey = string(jsonBytes)
-							}
-						}
+		list, ok := data[dataKey].([]interface{})
+		if !ok {
+			continue
+		}
+		logger.Debugf("Aggregating %d items from %s", len(list), resp.SourceName)
 
-						// Only add if not seen before
-						if uniqueKey != "" && !seenItems[uniqueKey] {
-							seenItems[uniqueKey] = true
-							allData = append(allData, item)
-							logger.Infof("✅ Added unique item from %s: %s", resp.SourceName, uniqueKey)
-						} else if uniqueKey != "" {
-							logger.Infof("❌ Skipped duplicate item from %s: %s (already exists)", resp.SourceName, uniqueKey)
-						}
-					} else {
-						// If item is not a map, add it directly (no deduplication possible)
-						allData = append(allData, item)
-					}
-				}
+		for _, item := range list {
+			total++
+			itemMap, ok := item.(map[string]interface{})
+			if !ok {
+				unkeyed = append(unkeyed, item)
+				continue
+			}
+			if !deduplicator.Add(dedup.Item{Fields: itemMap, Source: resp.SourceName}) {
+				logger.Debugf("Merged duplicate item from %s: %v", resp.SourceName, itemMap["judul"])
 			}
 		}
 	}
 
-	logger.Infof("Aggregated %d unique items from %d sources", len(allData), len(responses))
+	// Items several sources returned link their slugs for later detail requests
+	if groups := deduplicator.Groups(); len(groups) > 0 {
+		s.queueSlugGroups(groups)
+	}
+
+	allData := append(deduplicator.Items(), unkeyed...)
+	logger.Infof("Aggregated %d unique items (%d total) from %d sources", len(allData), total, len(responses))
 	result[dataKey] = allData
 }
 
+// newDedupMatcher builds the configured dedup matcher, falling back to the
+// default fuzzy matcher when the configuration is invalid
+func newDedupMatcher(cfg *config.Config) dedup.Matcher {
+	matcher, err := dedup.NewMatcher(cfg.DedupMatcher, cfg.DedupThreshold, cfg.DedupTitleSuffixes)
+	if err != nil {
+		logger.Warnf("Invalid dedup configuration, using fuzzy defaults: %v", err)
+		matcher, _ = dedup.NewMatcher(dedup.MatcherFuzzy, 0, nil)
+	}
+	return matcher
+}
+
 // aggregateScheduleData combines schedule data from multiple sources
 func (s *APIService) aggregateScheduleData(result map[string]interface{}, responses []*domain.APIResponse) {
 	scheduleMap := make(map[string][]interface{})
@@ -532,9 +627,24 @@ func (s *APIService) aggregateScheduleData(result map[string]interface{}, respon
 }
 
 // makeAPIRequest makes an HTTP request to an API with robust error handling
-func (s *APIService) makeAPIRequest(url, sourceName string, isFallback bool) *domain.APIResponse {
+func (s *APIService) makeAPIRequest(ctx context.Context, url, sourceName string, isFallback bool, opts upstreamOptions) (apiResp *domain.APIResponse) {
 	startTime := time.Now()
 
+	ctx, span := tracing.Start(ctx, "upstream.request",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			tracing.AttrSource.String(sourceName),
+			tracing.AttrFallback.Bool(isFallback),
+			attribute.String("url.full", url),
+		),
+	)
+	defer func() {
+		if apiResp.StatusCode != 0 {
+			span.SetAttributes(attribute.Int("http.response.status_code", apiResp.StatusCode))
+		}
+		tracing.End(span, apiResp.Error)
+	}()
+
 	// Debug logging for search requests
 	if strings.Contains(url, "/api/v1/search") {
 		logger.Infof("🔍 SEARCH DEBUG - Making request to %s: %s", sourceName, url)
@@ -550,7 +660,7 @@ func (s *APIService) makeAPIRequest(url, sourceName string, isFallback bool) *do
 		}
 	}
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return &domain.APIResponse{
 			Error:        fmt.Errorf("failed to create request: %w", err),
@@ -564,6 +674,12 @@ func (s *APIService) makeAPIRequest(url, sourceName string, isFallback bool) *do
 	req.Header.Set("User-Agent", "APIFallback/1.0")
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("Cache-Control", "no-cache")
+	if opts.mapping != nil {
+		for name, value := range opts.mapping.Headers {
+			req.Header.Set(name, value)
+		}
+	}
+	tracing.Inject(ctx, req.Header)
 
 	resp, err := s.httpClient.Do(req)
 	if err != nil {
@@ -628,8 +744,27 @@ func (s *APIService) makeAPIRequest(url, sourceName string, isFallback bool) *do
 	}
 	logger.Infof("🔍 Raw response from %s (first %d chars): %s", sourceName, maxLen, string(data[:maxLen]))
 
+	// Apply the source's declarative transform before the built-in normalization
+	if !opts.transform.IsEmpty() {
+		_, transformSpan := tracing.Start(ctx, "response.transform")
+		transformed, err := opts.transform.Apply(data)
+		tracing.End(transformSpan, err)
+		if err != nil {
+			return &domain.APIResponse{
+				Error:        fmt.Errorf("failed to transform response: %w", err),
+				StatusCode:   resp.StatusCode,
+				SourceName:   sourceName,
+				IsFallback:   isFallback,
+				ResponseTime: time.Since(startTime),
+			}
+		}
+		data = transformed
+	}
+
 	// Normalize response structure before returning
+	_, normalizeSpan := tracing.Start(ctx, "response.normalize")
 	normalizedData, err := s.normalizeResponseStructure(data, sourceName)
+	tracing.End(normalizeSpan, err)
 	if err != nil {
 		logger.Warnf("Failed to normalize response from %s: %v", sourceName, err)
 		// Return original data if normalization fails
@@ -799,11 +934,6 @@ func (s *APIService) normalizeDataFields(dataMap map[string]interface{}, sourceN
 		}
 	}
 
-	// Ensure confidence_score is always present with a default value
-	if _, exists := normalizedMap["confidence_score"]; !exists {
-		normalizedMap["confidence_score"] = 1.0
-	}
-
 	// Ensure source is always present
 	if _, exists := normalizedMap["source"]; !exists {
 		normalizedMap["source"] = sourceName
@@ -850,30 +980,27 @@ func (s *APIService) ClearCacheKey(category, endpoint string, params map[string]
 }
 
 // buildURL constructs the full URL with parameters (excluding internal parameters)
-func (s *APIService) buildURL(baseURL, endpoint string, params map[string]string) string {
+func (s *APIService) buildURL(baseURL, endpoint string, params map[string]string, mapping *database.RequestMapping) string {
 	url := baseURL + endpoint
 
+	// Anime identifiers are sent as the slug the source itself uses
+	if mapping != nil {
+		params = s.translateSlugs(mapping.SourceName, params)
+	}
+
 	// Filter out internal parameters that shouldn't be sent to external APIs
 	internalParams := map[string]bool{
 		"category":  true, // Internal parameter for API fallback routing
 		"aggregate": true, // Internal parameter for aggregation mode
 	}
 
-	// Parameter name mapping for different endpoints
-	paramMapping := map[string]map[string]string{
-		"/api/v1/search": {
-			"q": "query", // Map 'q' parameter to 'query' for search endpoints
-		},
-	}
-
-	// Build query string only with external parameters
+	// Build query string only with external parameters, renamed for the source
 	queryParams := make(map[string]string)
 	for key, value := range params {
 		if !internalParams[key] {
-			// Check if parameter name needs mapping
 			mappedKey := key
-			if endpointMapping, exists := paramMapping[endpoint]; exists {
-				if newKey, needsMapping := endpointMapping[key]; needsMapping {
+			if mapping != nil {
+				if newKey, needsMapping := mapping.ParamRenames[key]; needsMapping {
 					mappedKey = newKey
 				}
 			}
@@ -881,16 +1008,14 @@ func (s *APIService) buildURL(baseURL, endpoint string, params map[string]string
 		}
 	}
 
-	// Special handling for different APIs
-	if endpoint == "/api/v1/search" {
-		// Ensure trailing slash for samehadaku search endpoint
-		if strings.Contains(baseURL, "samehadaku") {
-			if !strings.HasSuffix(url, "/") {
-				url += "/"
-			}
-			// Add force_refresh parameter if not present
-			if _, exists := queryParams["force_refresh"]; !exists {
-				queryParams["force_refresh"] = "false"
+	// Apply the source's path suffix and default parameters
+	if mapping != nil {
+		if mapping.PathSuffix != "" && !strings.HasSuffix(url, mapping.PathSuffix) {
+			url += mapping.PathSuffix
+		}
+		for key, value := range mapping.DefaultParams {
+			if _, exists := queryParams[key]; !exists {
+				queryParams[key] = value
 			}
 		}
 	}
@@ -918,8 +1043,9 @@ func (s *APIService) buildURL(baseURL, endpoint string, params map[string]string
 	return url
 }
 
-// logRequest logs the API request
-func (s *APIService) logRequest(ctx *domain.RequestContext, result *domain.FallbackResult, responseTime time.Duration) {
+// logRequest logs the API request under its route, so that requests for
+// different slugs or days roll up together
+func (s *APIService) logRequest(reqCtx *domain.RequestContext, result *domain.FallbackResult, responseTime time.Duration) {
 	sourceUsed := ""
 	fallbackUsed := false
 	statusCode := 500
@@ -931,14 +1057,15 @@ func (s *APIService) logRequest(ctx *domain.RequestContext, result *domain.Fallb
 	}
 
 	logEntry := database.RequestLog{
-		Endpoint:     ctx.Endpoint,
-		Category:     ctx.Category,
+		Endpoint:     reqCtx.RoutePath(),
+		Category:     reqCtx.Category,
 		SourceUsed:   sourceUsed,
 		FallbackUsed: fallbackUsed,
 		ResponseTime: int(responseTime.Milliseconds()),
 		StatusCode:   statusCode,
-		ClientIP:     ctx.ClientIP,
-		UserAgent:    ctx.UserAgent,
+		ClientIP:     reqCtx.ClientIP,
+		UserAgent:    reqCtx.UserAgent,
+		APIKeyID:     reqCtx.APIKeyID,
 	}
 
 	if err := s.db.LogRequest(logEntry); err != nil {
@@ -996,68 +1123,48 @@ func (s *APIService) performHealthChecks() {
 	}
 }
 
-// checkAPIHealth checks the health of a single API source
+// checkAPIHealth probes one endpoint of an API source and records the result
 func (s *APIService) checkAPIHealth(source database.APISource, endpoint string) {
-	// Add test parameters for endpoints that require them
-	testParams := map[string]string{
-		"/api/v1/search":          "?query=a", // Use 'a' for better test results
-		"/api/v1/anime-detail/":   "?id=1",
-		"/api/v1/episode-detail/": "?id=1",
-		"/api/v1/anime-detail":    "?id=1",
-		"/api/v1/episode-detail":  "?id=1",
-	}
-
-	url := source.BaseURL + endpoint
-	if params, exists := testParams[endpoint]; exists {
-		url += params
-	}
-
-	// Special handling for samehadaku search endpoint
-	if strings.Contains(source.BaseURL, "samehadaku") && endpoint == "/api/v1/search" {
-		if !strings.HasSuffix(url, "/") {
-			url += "/"
-		}
-		// Add force_refresh parameter for samehadaku
-		if strings.Contains(url, "?") {
-			url += "&force_refresh=false"
-		} else {
-			url += "?force_refresh=false"
-		}
+	result, err := s.probeSource(source, endpoint)
+	if err != nil {
+		logger.Warnf("Cannot probe %s of %s: %v", endpoint, source.SourceName, err)
+		result = probeResult{status: healthSkipped, message: err.Error()}
 	}
-	startTime := time.Now()
+	s.recordHealthCheck(source, endpoint, result.status, result.responseTime, result.message)
+}
 
-	req, err := http.NewRequest("GET", url, nil)
+// GetHealthStatus returns the current health status of all API sources
+func (s *APIService) GetHealthStatus() ([]map[string]interface{}, error) {
+	results, err := s.db.GetHealthStatusWithDetails()
 	if err != nil {
-		s.db.UpdateHealthCheck(source.ID, "ERROR", 0, err.Error())
-		return
+		return nil, err
 	}
 
-	req.Header.Set("User-Agent", "APIFallback-HealthCheck/1.0")
-
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
-	responseTime := int(time.Since(startTime).Milliseconds())
-
+	routing, err := s.sourceRouting()
 	if err != nil {
-		status := "ERROR"
-		if err.Error() == "timeout" {
-			status = "TIMEOUT"
-		}
-		s.db.UpdateHealthCheck(source.ID, status, responseTime, err.Error())
-		return
+		logger.Warnf("Failed to load source routing: %v", err)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-		s.db.UpdateHealthCheck(source.ID, "OK", responseTime, "")
-	} else {
-		s.db.UpdateHealthCheck(source.ID, "ERROR", responseTime, fmt.Sprintf("HTTP %d", resp.StatusCode))
+	// Overlay live circuit breaker state (the DB only records transitions)
+	for _, result := range results {
+		id, ok := result["api_source_id"].(int)
+		if !ok {
+			continue
+		}
+		if entry, exists := routing[id]; exists {
+			result["routing_override"] = entry.Override
+			result["routing_state"] = s.routingState(entry)
+		}
+		if snapshot, exists := s.breakers.Snapshot(id); exists {
+			result["circuit_state"] = string(snapshot.State)
+			result["circuit_failures"] = snapshot.ConsecutiveFailures
+			if snapshot.LastError != "" {
+				result["circuit_reason"] = snapshot.LastError
+			}
+		}
 	}
-}
 
-// GetHealthStatus returns the current health status of all API sources
-func (s *APIService) GetHealthStatus() ([]map[string]interface{}, error) {
-	return s.db.GetHealthStatusWithDetails()
+	return results, nil
 }
 
 // RunAllHealthChecks runs health checks on all active API sources
@@ -1081,48 +1188,24 @@ func (s *APIService) RunAllHealthChecks() error {
 	return nil
 }
 
-// runHealthCheckForSource performs health check on a specific API source
+// runHealthCheckForSource probes the endpoint an API source serves
 func (s *APIService) runHealthCheckForSource(source database.APISource) {
-	start := time.Now()
-
-	// Build health check URL - try multiple endpoints
-	healthURLs := []string{
-		strings.TrimSuffix(source.BaseURL, "/") + "/health",
-		strings.TrimSuffix(source.BaseURL, "/") + "/",
-		strings.TrimSuffix(source.BaseURL, "/"),
-	}
-
-	// Create HTTP client with timeout
-	client := &http.Client{
-		Timeout: 10 * time.Second,
-	}
-
-	var lastErr error
-	var resp *http.Response
+	s.checkAPIHealth(source, source.EndpointPath)
+}
 
-	// Try each URL until one works
-	for _, url := range healthURLs {
-		resp, lastErr = client.Get(url)
-		if lastErr == nil && resp != nil {
-			break
-		}
-		if resp != nil {
-			resp.Body.Close()
-		}
+// recordHealthCheck stores a health check result and exports it as metrics
+func (s *APIService) recordHealthCheck(source database.APISource, endpoint, status string, responseTime int, errorMessage string) {
+	previous, err := s.db.GetLatestHealthStatus(source.ID)
+	if err != nil {
+		logger.Warnf("Failed to get previous health status of %s: %v", source.SourceName, err)
 	}
-
-	responseTime := int(time.Since(start).Milliseconds())
-
-	if lastErr != nil {
-		s.db.UpdateHealthCheck(source.ID, "ERROR", responseTime, lastErr.Error())
-		return
+	if err := s.db.UpdateHealthCheck(source.ID, status, responseTime, errorMessage); err != nil {
+		logger.Errorf("Failed to store health check for %s: %v", source.SourceName, err)
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode >= 200 && resp.StatusCode < 400 {
-		s.db.UpdateHealthCheck(source.ID, "OK", responseTime, "")
-	} else {
-		s.db.UpdateHealthCheck(source.ID, "ERROR", responseTime, fmt.Sprintf("HTTP %d", resp.StatusCode))
+	s.notifyHealthChange(source, endpoint, previous, status, errorMessage)
+	s.routing.Invalidate()
+	if status != healthSkipped {
+		metrics.ObserveHealthCheck(source.SourceName, endpoint, status, time.Duration(responseTime)*time.Millisecond)
 	}
 }
 
@@ -1131,33 +1214,57 @@ func (s *APIService) GetRequestLogs(limit int) ([]database.RequestLog, error) {
 	return s.db.GetRequestLogs(limit)
 }
 
-// GetStatistics returns real statistics from database
+// GetStatistics returns real statistics from database, with the mean 24h
+// uptime of the sources
 func (s *APIService) GetStatistics() (map[string]interface{}, error) {
-	return s.db.GetStatistics()
+	stats, err := s.db.GetStatistics()
+	if err != nil {
+		return nil, err
+	}
+	stats["uptime"] = s.overallUptime()
+	return stats, nil
 }
 
 // CreateCategory creates a new category
 func (s *APIService) CreateCategory(name string, isActive bool) error {
+	defer s.routes.Invalidate()
 	return s.db.CreateCategory(name, isActive)
 }
 
 // UpdateCategory updates an existing category
 func (s *APIService) UpdateCategory(id int, name string, isActive bool) error {
+	defer s.routes.Invalidate()
 	return s.db.UpdateCategory(id, name, isActive)
 }
 
 // DeleteCategory deletes a category
 func (s *APIService) DeleteCategory(id int) error {
+	defer s.routes.Invalidate()
 	return s.db.DeleteCategory(id)
 }
 
-// GetAllAPISources returns all API sources with details
+// GetAllAPISources returns all API sources with details and their fallback chains
 func (s *APIService) GetAllAPISources() ([]database.APISourceWithDetails, error) {
-	return s.db.GetAllAPISources()
+	sources, err := s.db.GetAllAPISources()
+	if err != nil {
+		return nil, err
+	}
+
+	// Attach each source's fallback chain
+	chains, err := s.db.GetAllFallbackChains()
+	if err != nil {
+		return nil, err
+	}
+	for i := range sources {
+		sources[i].Fallbacks = chains[sources[i].ID]
+	}
+
+	return sources, nil
 }
 
 // CreateAPISource creates a new API source
 func (s *APIService) CreateAPISource(endpointID int, sourceName, baseURL string, priority int, isPrimary bool) error {
+	defer s.mappings.Invalidate() // A samehadaku URL seeds a request mapping
 	return s.db.CreateAPISource(endpointID, sourceName, baseURL, priority, isPrimary)
 }
 
@@ -1174,6 +1281,7 @@ func (s *APIService) CreateAPISourceForAllEndpoints(categoryName, sourceName, ba
 	}
 
 	// Create API source for each endpoint
+	defer s.mappings.Invalidate() // A samehadaku URL seeds a request mapping
 	var errors []string
 	successCount := 0
 
@@ -1196,6 +1304,7 @@ func (s *APIService) CreateAPISourceForAllEndpoints(categoryName, sourceName, ba
 
 // UpdateAPISource updates an existing API source
 func (s *APIService) UpdateAPISource(id int, sourceName, baseURL string, priority int, isPrimary, isActive bool) error {
+	defer s.mappings.Invalidate() // A samehadaku URL seeds a request mapping
 	return s.db.UpdateAPISource(id, sourceName, baseURL, priority, isPrimary, isActive)
 }
 
@@ -1218,22 +1327,27 @@ func (s *APIService) RunManualHealthCheck() (map[string]interface{}, error) {
 		return nil, fmt.Errorf("failed to get health status: %v", err)
 	}
 
-	// Calculate summary
-	var totalChecked, totalHealthy, totalUnhealthy int
-	totalChecked = len(healthStatus)
+	// Calculate summary; unconfigured endpoints were not probed
+	var totalChecked, totalHealthy, totalUnhealthy, totalUnconfigured int
 
 	for _, status := range healthStatus {
-		if statusStr, ok := status["status"].(string); ok && statusStr == "healthy" {
+		switch statusStr, _ := status["status"].(string); statusStr {
+		case "healthy":
 			totalHealthy++
-		} else {
+		case "unconfigured":
+			totalUnconfigured++
+			continue
+		default:
 			totalUnhealthy++
 		}
+		totalChecked++
 	}
 
 	results := map[string]interface{}{
-		"total_checked":   totalChecked,
-		"total_healthy":   totalHealthy,
-		"total_unhealthy": totalUnhealthy,
+		"total_checked":      totalChecked,
+		"total_healthy":      totalHealthy,
+		"total_unhealthy":    totalUnhealthy,
+		"total_unconfigured": totalUnconfigured,
 		"health_percentage": func() int {
 			if totalChecked > 0 {
 				return int((float64(totalHealthy) / float64(totalChecked)) * 100)
@@ -1274,22 +1388,33 @@ func (s *APIService) GetAllEndpoints() ([]database.EndpointWithDetails, error) {
 
 // CreateEndpoint creates a new endpoint
 func (s *APIService) CreateEndpoint(categoryID int, path string) (*database.Endpoint, error) {
+	if err := validateEndpointPath(path); err != nil {
+		return nil, err
+	}
+
+	defer s.routes.Invalidate()
 	return s.db.CreateEndpoint(categoryID, path)
 }
 
 // UpdateEndpoint updates an existing endpoint
 func (s *APIService) UpdateEndpoint(id int, categoryID int, path string) (*database.Endpoint, error) {
+	if err := validateEndpointPath(path); err != nil {
+		return nil, err
+	}
+
+	defer s.routes.Invalidate()
 	return s.db.UpdateEndpoint(id, categoryID, path)
 }
 
 // DeleteEndpoint deletes an endpoint
 func (s *APIService) DeleteEndpoint(id int) error {
+	defer s.routes.Invalidate()
 	return s.db.DeleteEndpoint(id)
 }
 
 // processAllCategories handles requests for category "all" by fetching from all active categories
-func (s *APIService) processAllCategories(ctx *domain.RequestContext, startTime time.Time) (*domain.APIResponse, error) {
-	logger.Infof("Processing request for all categories: %s", ctx.Endpoint)
+func (s *APIService) processAllCategories(ctx context.Context, reqCtx *domain.RequestContext, startTime time.Time) (*domain.APIResponse, error) {
+	logger.Infof("Processing request for all categories: %s", reqCtx.Endpoint)
 
 	// Get all active categories
 	categories, err := s.db.GetCategories()
@@ -1313,28 +1438,32 @@ func (s *APIService) processAllCategories(ctx *domain.RequestContext, startTime
 
 			// Create new context for this category
 			categoryCtx := &domain.RequestContext{
-				Endpoint:   ctx.Endpoint,
+				Endpoint:   reqCtx.Endpoint,
 				Category:   cat.Name,
-				Parameters: ctx.Parameters,
-				ClientIP:   ctx.ClientIP,
-				UserAgent:  ctx.UserAgent,
+				Parameters: reqCtx.Parameters,
+				Route:      reqCtx.Route,
+				PathParams: reqCtx.PathParams,
+				ClientIP:   reqCtx.ClientIP,
+				UserAgent:  reqCtx.UserAgent,
+				APIKeyID:   reqCtx.APIKeyID,
 				StartTime:  startTime,
 			}
 
 			// Get API sources for this category
-			apiSources, err := s.db.GetAPISourcesByEndpoint(ctx.Endpoint, cat.Name)
+			apiSources, err := s.db.GetAPISourcesByEndpoint(reqCtx.RoutePath(), cat.Name)
 			if err != nil {
 				logger.Warnf("Failed to get API sources for category %s: %v", cat.Name, err)
 				return
 			}
 
 			if len(apiSources) == 0 {
-				logger.Warnf("No API sources for category %s, endpoint %s", cat.Name, ctx.Endpoint)
+				logger.Warnf("No API sources for category %s, endpoint %s", cat.Name, reqCtx.Endpoint)
 				return
 			}
 
-			// Try all primary APIs for this category
-			result := s.tryAllPrimaryAPIsWithFallback(apiSources, categoryCtx)
+			// Try all primary APIs for this category; late hedged sources do not
+			// refresh the cached all-category response
+			result := s.tryAllPrimaryAPIsWithFallback(ctx, apiSources, categoryCtx, nil)
 			if result.Success && result.Response != nil {
 				// Add category metadata to response
 				var responseData map[string]interface{}
@@ -1365,13 +1494,15 @@ func (s *APIService) processAllCategories(ctx *domain.RequestContext, startTime
 	}
 
 	// Aggregate responses from all categories
-	aggregatedResponse := s.aggregateResponsesFromAllCategories(allResponses, ctx.Endpoint)
+	aggregatedResponse := s.aggregateResponsesFromAllCategories(allResponses, reqCtx.Endpoint)
 	return aggregatedResponse, nil
 }
 
-// tryAllPrimaryAPIsWithFallback tries all primary APIs and uses fallback for each that fails
-func (s *APIService) tryAllPrimaryAPIsWithFallback(sources []database.APISource, ctx *domain.RequestContext) *domain.FallbackResult {
-	logger.Infof("Trying all %d primary APIs for %s in category %s", len(sources), ctx.Endpoint, ctx.Category)
+// tryAllPrimaryAPIsWithFallback tries all primary APIs and uses fallback for each that fails.
+// With a hedge policy for the endpoint, refresh (when not nil) receives the
+// response including the sources that answered after the result was returned.
+func (s *APIService) tryAllPrimaryAPIsWithFallback(ctx context.Context, sources []database.APISource, reqCtx *domain.RequestContext, refresh func(*domain.APIResponse)) *domain.FallbackResult {
+	logger.Infof("Trying all %d primary APIs for %s in category %s", len(sources), reqCtx.Endpoint, reqCtx.Category)
 
 	// Filter only primary sources
 	var primarySources []database.APISource
@@ -1383,21 +1514,62 @@ func (s *APIService) tryAllPrimaryAPIsWithFallback(sources []database.APISource,
 	}
 
 	if len(primarySources) == 0 {
-		logger.Warnf("No primary sources available for %s in category %s", ctx.Endpoint, ctx.Category)
+		logger.Warnf("No primary sources available for %s in category %s", reqCtx.Endpoint, reqCtx.Category)
 		return &domain.FallbackResult{Success: false}
 	}
 
 	logger.Infof("Total primary sources found: %d", len(primarySources))
 
+	// Sources failing their latest health check are only tried when the others fail
+	preferred, demoted := s.partitionByHealth(primarySources)
+	if len(preferred) == 0 {
+		preferred, demoted = demoted, nil
+	}
+	if len(preferred) == 0 {
+		logger.Warnf("All primary sources for %s in category %s are disabled for routing", reqCtx.Endpoint, reqCtx.Category)
+		return &domain.FallbackResult{Success: false}
+	}
+
+	preferredCtx, cancelPreferred := ctx, context.CancelFunc(func() {})
+	if len(demoted) > 0 {
+		preferredCtx, cancelPreferred = preferredPassContext(ctx)
+	}
+	result := s.tryPrimarySources(preferredCtx, preferred, reqCtx, refresh)
+	cancelPreferred()
+	if !result.Success && len(demoted) > 0 && ctx.Err() == nil {
+		logger.Warnf("Healthy sources failed for %s, trying %d unhealthy sources", reqCtx.Endpoint, len(demoted))
+		result = s.tryPrimarySources(ctx, demoted, reqCtx, refresh)
+	}
+	return result
+}
+
+// preferredPassContext bounds the pass over healthy sources to half of the
+// request deadline budget left, so a hanging source cannot use up the time of
+// the unhealthy sources tried after it
+func preferredPassContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	deadline, exists := ctx.Deadline()
+	if !exists {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, time.Until(deadline)/2)
+}
+
+// tryPrimarySources queries primary sources concurrently, each with its fallbacks
+func (s *APIService) tryPrimarySources(ctx context.Context, primarySources []database.APISource, reqCtx *domain.RequestContext, refresh func(*domain.APIResponse)) *domain.FallbackResult {
 	// Special handling for detail endpoints - bruteforce all sources and return first valid
-	// Support both with and without trailing slash
-	if ctx.Endpoint == "/api/v1/anime-detail/" || ctx.Endpoint == "/api/v1/anime-detail" ||
-		ctx.Endpoint == "/api/v1/episode-detail/" || ctx.Endpoint == "/api/v1/episode-detail" {
-		return s.bruteforceDetailSources(primarySources, ctx)
+	if isDetailEndpoint(reqCtx.Endpoint) {
+		return s.bruteforceDetailSources(ctx, primarySources, reqCtx)
+	}
+
+	// With adaptive ordering the best-scored source leads the combined response
+	primarySources = s.orderSources(primarySources, reqCtx.RoutePath())
+
+	if policy, exists := s.hedgePolicy(reqCtx.RoutePath()); exists {
+		return s.tryHedged(ctx, primarySources, reqCtx, policy, refresh)
 	}
 
 	// Create channels for concurrent requests
-	resultChan := make(chan *domain.APIResponse, len(primarySources))
+	outcomeChan := make(chan sourceOutcome, len(primarySources))
 	var wg sync.WaitGroup
 
 	// Try each primary source concurrently
@@ -1405,30 +1577,39 @@ func (s *APIService) tryAllPrimaryAPIsWithFallback(sources []database.APISource,
 		wg.Add(1)
 		go func(src database.APISource) {
 			defer wg.Done()
-			s.trySourceWithFallback(src, ctx, resultChan)
+			results := make(chan *domain.APIResponse, 1)
+			s.trySourceWithFallback(ctx, src, reqCtx, results)
+			close(results)
+			outcomeChan <- sourceOutcome{source: src.SourceName, resp: <-results}
 		}(source)
 	}
 
 	// Close channel when all goroutines complete
 	go func() {
 		wg.Wait()
-		close(resultChan)
+		close(outcomeChan)
 	}()
 
 	// Collect all successful responses
-	var successfulResponses []*domain.APIResponse
-	for resp := range resultChan {
-		if resp.Error == nil {
-			successfulResponses = append(successfulResponses, resp)
-			logger.Infof("Successfully got data from source: %s", resp.SourceName)
+	var valid []sourceOutcome
+	for outcome := range outcomeChan {
+		if outcome.resp != nil && outcome.resp.Error == nil {
+			valid = append(valid, outcome)
+			logger.Infof("Successfully got data from source: %s", outcome.resp.SourceName)
 		}
 	}
 
-	if len(successfulResponses) == 0 {
-		logger.Warnf("All primary and fallback APIs failed for %s", ctx.Endpoint)
+	if len(valid) == 0 {
+		logger.Warnf("All primary and fallback APIs failed for %s", reqCtx.Endpoint)
 		return &domain.FallbackResult{Success: false}
 	}
 
+	successfulResponses, _ := s.rankOutcomes(valid, primarySources)
+	return s.combineResponses(successfulResponses, reqCtx.Endpoint)
+}
+
+// combineResponses returns the only valid response, or aggregates several
+func (s *APIService) combineResponses(successfulResponses []*domain.APIResponse, endpoint string) *domain.FallbackResult {
 	// If only one successful response, return it
 	if len(successfulResponses) == 1 {
 		return &domain.FallbackResult{
@@ -1441,7 +1622,7 @@ func (s *APIService) tryAllPrimaryAPIsWithFallback(sources []database.APISource,
 
 	// Aggregate multiple successful responses
 	logger.Infof("Aggregating %d successful responses from different sources", len(successfulResponses))
-	aggregatedResponse := s.aggregateResponses(successfulResponses, ctx.Endpoint)
+	aggregatedResponse := s.aggregateResponses(successfulResponses, endpoint)
 
 	return &domain.FallbackResult{
 		Success:      true,
@@ -1452,13 +1633,48 @@ func (s *APIService) tryAllPrimaryAPIsWithFallback(sources []database.APISource,
 }
 
 // trySourceWithFallback tries a primary source and its fallbacks
-func (s *APIService) trySourceWithFallback(source database.APISource, ctx *domain.RequestContext, resultChan chan<- *domain.APIResponse) {
+func (s *APIService) trySourceWithFallback(ctx context.Context, source database.APISource, reqCtx *domain.RequestContext, resultChan chan<- *domain.APIResponse) {
+	// Skip sources whose circuit is open
+	breaker := s.breakers.Get(source.ID, source.SourceName)
+	if !breaker.Allow() {
+		logger.Warnf("🔌 Skipping source %s (ID: %d): circuit open", source.SourceName, source.ID)
+		return
+	}
+
+	// Report a single outcome per source: success if primary or any fallback validated
+	succeeded := false
+	var lastErr error
+	defer func() {
+		if succeeded {
+			breaker.RecordSuccess()
+		} else if isCancellation(lastErr) {
+			breaker.Release() // Cancelled by the caller, says nothing about the source
+		} else if lastErr != nil {
+			breaker.RecordFailure(lastErr.Error())
+		} else {
+			breaker.RecordFailure("all attempts failed")
+		}
+	}()
+
+	ctx, span := tracing.Start(ctx, "source.fetch", trace.WithAttributes(tracing.AttrSource.String(source.SourceName)))
+	defer func() {
+		switch {
+		case succeeded:
+			span.End()
+		case lastErr != nil:
+			tracing.End(span, lastErr)
+		default:
+			tracing.End(span, errors.New("no valid response"))
+		}
+	}()
+
 	logger.Infof("Trying primary source: %s (ID: %d, BaseURL: %s)", source.SourceName, source.ID, source.BaseURL)
 
 	// Try primary source first
-	url := s.buildURL(source.BaseURL, ctx.Endpoint, ctx.Parameters)
+	opts := s.upstreamOptions(source, reqCtx.RoutePath())
+	url := s.buildURL(source.BaseURL, reqCtx.Endpoint, reqCtx.Parameters, opts.mapping)
 	logger.Infof("Built URL for %s: %s", source.SourceName, url)
-	resp := s.makeAPIRequest(url, source.SourceName, false)
+	resp := s.makeAPIRequest(ctx, url, source.SourceName, false, opts)
 
 	// Special debug for winbutv
 	if source.SourceName == "winbutv" {
@@ -1477,24 +1693,29 @@ func (s *APIService) trySourceWithFallback(source database.APISource, ctx *domai
 
 	// Validate response
 	if resp.Error == nil && resp.Data != nil {
-		if err := validator.ValidateResponse(ctx.Endpoint, resp.Data); err != nil {
+		if err := s.validateResponse(ctx, reqCtx.Endpoint, resp.Data, opts.confidence); err != nil {
 			logger.Warnf("Validation failed for %s: %v", source.SourceName, err)
 			if source.SourceName == "winbutv" {
 				logger.Errorf("WINBUTV VALIDATION FAILED: %v", err)
 			}
+			s.observeUpstream(reqCtx, source.SourceName, resp, err)
 			resp.Error = err
 		} else {
+			s.observeUpstream(reqCtx, source.SourceName, resp, nil)
 			// Primary source successful
 			logger.Infof("Primary source %s successful with %d bytes of data", source.SourceName, len(resp.Data))
 			if source.SourceName == "winbutv" {
 				logger.Infof("WINBUTV SUCCESS: Sending to result channel")
 			}
+			succeeded = true
 			resultChan <- resp
 			return
 		}
 	} else {
 		logger.Warnf("Primary source %s failed: Error=%v, DataLen=%d", source.SourceName, resp.Error, len(resp.Data))
+		s.observeUpstream(reqCtx, source.SourceName, resp, nil)
 	}
+	lastErr = resp.Error
 
 	// Primary failed, try fallbacks
 	logger.Warnf("Primary source %s failed, trying fallbacks", source.SourceName)
@@ -1506,60 +1727,98 @@ func (s *APIService) trySourceWithFallback(source database.APISource, ctx *domai
 
 	// Try each fallback
 	for _, fallback := range fallbacks {
+		if err := ctx.Err(); err != nil {
+			logger.Warnf("Stopping fallbacks for %s: %v", source.SourceName, err)
+			lastErr = err
+			break
+		}
+
 		logger.Infof("Trying fallback: %s", fallback.FallbackURL)
-		fallbackURL := s.buildURL(fallback.FallbackURL, ctx.Endpoint, ctx.Parameters)
-		fallbackResp := s.makeAPIRequest(fallbackURL, source.SourceName+"_fallback", true)
+		fallbackURL := s.buildURL(fallback.FallbackURL, reqCtx.Endpoint, reqCtx.Parameters, opts.mapping)
+		fallbackResp := s.makeAPIRequest(ctx, fallbackURL, source.SourceName+"_fallback", true, opts)
 
 		// Validate fallback response
 		if fallbackResp.Error == nil && fallbackResp.Data != nil {
-			if err := validator.ValidateResponse(ctx.Endpoint, fallbackResp.Data); err != nil {
+			if err := s.validateResponse(ctx, reqCtx.Endpoint, fallbackResp.Data, opts.confidence); err != nil {
 				logger.Warnf("Validation failed for fallback %s: %v", fallback.FallbackURL, err)
+				s.observeUpstream(reqCtx, source.SourceName, fallbackResp, err)
+				lastErr = err
 				continue
 			}
 
 			// Fallback successful
+			s.observeUpstream(reqCtx, source.SourceName, fallbackResp, nil)
 			logger.Infof("Fallback successful for %s", source.SourceName)
+			succeeded = true
 			resultChan <- fallbackResp
 			return
 		}
+		s.observeUpstream(reqCtx, source.SourceName, fallbackResp, nil)
+		if fallbackResp.Error != nil {
+			lastErr = fallbackResp.Error
+		}
 	}
 
 	logger.Warnf("All attempts failed for source %s", source.SourceName)
 }
 
 // bruteforceDetailSources implements parallel bruteforce approach for detail endpoints
-// This method hits ALL available sources concurrently and returns the first valid response
-func (s *APIService) bruteforceDetailSources(primarySources []database.APISource, ctx *domain.RequestContext) *domain.FallbackResult {
-	logger.Infof("Starting bruteforce approach for %s - hitting all %d sources concurrently", ctx.Endpoint, len(primarySources))
+// This method hits ALL available sources concurrently and returns the first valid response,
+// or with adaptive ordering the best-ranked valid response within AdaptiveWait of it
+func (s *APIService) bruteforceDetailSources(ctx context.Context, primarySources []database.APISource, reqCtx *domain.RequestContext) *domain.FallbackResult {
+	logger.Infof("Starting bruteforce approach for %s - hitting all %d sources concurrently", reqCtx.Endpoint, len(primarySources))
 
-	// Collect all available URLs (primary + fallbacks)
+	// With adaptive ordering the score rank replaces the static priority when picking a winner
+	primarySources = s.orderSources(primarySources, reqCtx.RoutePath())
+
+	// Collect all available URLs (primary + fallbacks), skipping sources whose circuit is open
 	var allSources []bruteforceSource
-	for _, source := range primarySources {
+	outcomes := newSourceOutcomeTracker()
+	for rank, source := range primarySources {
+		priority := source.Priority
+		if s.adaptive != nil {
+			priority = rank
+		}
+
+		breaker := s.breakers.Get(source.ID, source.SourceName)
+		if !breaker.Allow() {
+			logger.Warnf("🔌 Skipping source %s (ID: %d): circuit open", source.SourceName, source.ID)
+			continue
+		}
+
 		// Add primary source
-		primaryURL := s.buildURL(source.BaseURL, ctx.Endpoint, ctx.Parameters)
+		opts := s.upstreamOptions(source, reqCtx.RoutePath())
+		primaryURL := s.buildURL(source.BaseURL, reqCtx.Endpoint, reqCtx.Parameters, opts.mapping)
 		allSources = append(allSources, bruteforceSource{
-			URL:        primaryURL,
-			SourceName: source.SourceName,
-			Priority:   source.Priority,
-			IsFallback: false,
+			URL:         primaryURL,
+			Options:     opts,
+			SourceID:    source.ID,
+			PrimaryName: source.SourceName,
+			SourceName:  source.SourceName,
+			Priority:    priority,
+			IsFallback:  false,
 		})
 
 		// Add fallback sources
 		fallbacks, err := s.db.GetFallbackAPIs(source.ID)
 		if err != nil {
 			logger.Warnf("Failed to get fallback APIs for source %s: %v", source.SourceName, err)
-			continue
 		}
 
 		for i, fallback := range fallbacks {
-			fallbackURL := s.buildURL(fallback.FallbackURL, ctx.Endpoint, ctx.Parameters)
+			fallbackURL := s.buildURL(fallback.FallbackURL, reqCtx.Endpoint, reqCtx.Parameters, opts.mapping)
 			allSources = append(allSources, bruteforceSource{
-				URL:        fallbackURL,
-				SourceName: fmt.Sprintf("%s_fallback_%d", source.SourceName, i+1),
-				Priority:   source.Priority + 1000 + i, // Lower priority than primary
-				IsFallback: true,
+				URL:         fallbackURL,
+				Options:     opts,
+				SourceID:    source.ID,
+				PrimaryName: source.SourceName,
+				SourceName:  fmt.Sprintf("%s_fallback_%d", source.SourceName, i+1),
+				Priority:    priority + 1000 + i, // Lower priority than primary
+				IsFallback:  true,
 			})
 		}
+
+		outcomes.add(source.ID, breaker, 1+len(fallbacks))
 	}
 
 	if len(allSources) == 0 {
@@ -1569,12 +1828,19 @@ func (s *APIService) bruteforceDetailSources(primarySources []database.APISource
 
 	logger.Infof("Bruteforcing %d total sources (primary + fallback)", len(allSources))
 
+	ctx, span := tracing.Start(ctx, "bruteforce", trace.WithAttributes(attribute.Int("gateway.bruteforce.sources", len(allSources))))
+	defer span.End()
+
 	// Channel to receive results
 	resultChan := make(chan *domain.APIResponse, len(allSources))
 	firstValidChan := make(chan *domain.APIResponse, 1)
 	var wg sync.WaitGroup
 	var once sync.Once
 
+	// Losing requests are cancelled as soon as we return (first valid response, timeout or failure)
+	bruteforceCtx, cancelLosers := context.WithCancel(ctx)
+	defer cancelLosers()
+
 	// Start all requests concurrently
 	for _, source := range allSources {
 		wg.Add(1)
@@ -1582,18 +1848,21 @@ func (s *APIService) bruteforceDetailSources(primarySources []database.APISource
 			defer wg.Done()
 
 			logger.Debugf("Trying source: %s at %s", src.SourceName, src.URL)
-			resp := s.makeAPIRequest(src.URL, src.SourceName, src.IsFallback)
+			resp := s.makeAPIRequest(bruteforceCtx, src.URL, src.SourceName, src.IsFallback, src.Options)
+			defer func() { outcomes.done(src.SourceID, resp.Error) }()
 
 			// Check if response is valid
 			if resp.Error == nil && resp.Data != nil {
-				if err := validator.ValidateResponse(ctx.Endpoint, resp.Data); err != nil {
+				if err := s.validateResponse(bruteforceCtx, reqCtx.Endpoint, resp.Data, src.Options.confidence); err != nil {
 					logger.Warnf("Validation failed for %s: %v", src.SourceName, err)
+					s.observeUpstream(reqCtx, src.PrimaryName, resp, err)
 					resp.Error = err
 					resultChan <- resp
 					return
 				}
 
 				logger.Infof("✓ Valid data found from source: %s", src.SourceName)
+				s.observeUpstream(reqCtx, src.PrimaryName, resp, nil)
 				resp.Priority = src.Priority // Store priority for sorting
 
 				// Send to result channel for collection
@@ -1611,6 +1880,7 @@ func (s *APIService) bruteforceDetailSources(primarySources []database.APISource
 				})
 			} else {
 				logger.Debugf("Failed to get valid data from %s: %v", src.SourceName, resp.Error)
+				s.observeUpstream(reqCtx, src.PrimaryName, resp, nil)
 				resultChan <- resp
 			}
 		}(source)
@@ -1628,7 +1898,14 @@ func (s *APIService) bruteforceDetailSources(primarySources []database.APISource
 	case validResp, ok := <-firstValidChan:
 		// Check if channel is still open and we got a valid response
 		if ok && validResp != nil {
-			logger.Infof("Bruteforce SUCCESS: Got valid data from %s", validResp.SourceName)
+			if s.config.DetailMergeEnabled {
+				validResp = s.mergeDetailResponses(ctx, validResp, resultChan)
+			} else {
+				validResp = s.awaitBetterRanked(ctx, validResp, allSources, resultChan)
+			}
+			logger.Infof("Bruteforce SUCCESS: Got valid data from %s, cancelling remaining requests", validResp.SourceName)
+			cancelLosers()
+			s.observeBruteforceWin(reqCtx, allSources, validResp)
 
 			// Still wait for other goroutines to complete to avoid resource leaks
 			go func() {
@@ -1642,10 +1919,12 @@ func (s *APIService) bruteforceDetailSources(primarySources []database.APISource
 				SourceUsed:   validResp.SourceName,
 				FallbackUsed: validResp.IsFallback,
 			}
-		} else {
-			logger.Warnf("Received nil or closed channel in firstValidChan")
 		}
-	case <-time.After(time.Duration(len(allSources)) * time.Second * 2): // Dynamic timeout based on source count
+		// Closed without a valid response: every source has finished
+	case <-ctx.Done():
+		logger.Warnf("Bruteforce stopped for %s: %v", reqCtx.Endpoint, ctx.Err())
+		return &domain.FallbackResult{Success: false}
+	case <-time.After(bruteforceTimeout(ctx, len(allSources))):
 		// Timeout - collect any results we got
 		logger.Warnf("Bruteforce timeout reached, collecting partial results")
 
@@ -1674,6 +1953,7 @@ func (s *APIService) bruteforceDetailSources(primarySources []database.APISource
 
 		if bestValid != nil {
 			logger.Infof("Found valid response after timeout from: %s", bestValid.SourceName)
+			s.observeBruteforceWin(reqCtx, allSources, bestValid)
 			return &domain.FallbackResult{
 				Success:      true,
 				Response:     bestValid,
@@ -1687,12 +1967,37 @@ func (s *APIService) bruteforceDetailSources(primarySources []database.APISource
 	return &domain.FallbackResult{Success: false}
 }
 
+// bruteforceTimeout is how long a bruteforce over count URLs waits for a valid
+// response: two seconds per URL, but no later than shortly before ctx's
+// deadline, so that partial results are still collected
+func bruteforceTimeout(ctx context.Context, count int) time.Duration {
+	timeout := time.Duration(count) * 2 * time.Second
+	if deadline, exists := ctx.Deadline(); exists {
+		if remaining := time.Until(deadline) * 9 / 10; remaining < timeout {
+			timeout = remaining
+		}
+	}
+	return timeout
+}
+
+// isDetailEndpoint reports whether an endpoint returns a single title, with or without trailing slash
+func isDetailEndpoint(endpoint string) bool {
+	switch strings.TrimSuffix(endpoint, "/") {
+	case "/api/v1/anime-detail", "/api/v1/episode-detail":
+		return true
+	}
+	return false
+}
+
 // bruteforceSource represents a source for bruteforce attempt
 type bruteforceSource struct {
-	URL        string
-	SourceName string
-	Priority   int
-	IsFallback bool
+	URL         string
+	Options     upstreamOptions // Request mapping and response transform of the source
+	SourceID    int
+	PrimaryName string // Name of the primary source this URL belongs to
+	SourceName  string
+	Priority    int
+	IsFallback  bool
 }
 
 // aggregateResponsesFromAllCategories combines responses from different categories
@@ -1713,8 +2018,11 @@ func (s *APIService) aggregateResponsesFromAllCategories(responses []*domain.API
 	var categories []string
 	categoryData := make(map[string]interface{})
 
+	var includedSources, lateSources []string
 	for _, resp := range responses {
 		sources = append(sources, resp.SourceName)
+		includedSources = append(includedSources, resp.IncludedSources...)
+		lateSources = append(lateSources, resp.LateSources...)
 
 		var data map[string]interface{}
 		if err := json.Unmarshal(resp.Data, &data); err != nil {
@@ -1766,11 +2074,13 @@ func (s *APIService) aggregateResponsesFromAllCategories(responses []*domain.API
 	}
 
 	return &domain.APIResponse{
-		Data:         aggregatedJSON,
-		StatusCode:   200,
-		ResponseTime: responseTime,
-		SourceName:   "aggregated_all_categories",
-		IsFallback:   false,
+		Data:            aggregatedJSON,
+		StatusCode:      200,
+		ResponseTime:    responseTime,
+		SourceName:      "aggregated_all_categories",
+		IsFallback:      false,
+		IncludedSources: includedSources,
+		LateSources:     lateSources,
 	}
 }
 
@@ -5,36 +5,41 @@ import (
 	"apicategorywithfallback/pkg/config"
 	"apicategorywithfallback/pkg/database"
 	"apicategorywithfallback/pkg/logger"
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 )
 
-func TestNewAPIService(t *testing.T) {
-	// Initialize logger
+func TestMain(m *testing.M) {
+	// Initialized once: background fetches of one test may still log during the next
 	logger.Init()
+	os.Exit(m.Run())
+}
+
+// testRedisAddr refuses connections, so services under test use the memory cache
+const testRedisAddr = "127.0.0.1:1"
 
+func TestNewAPIService(t *testing.T) {
 	// Create temporary database
 	dbPath := "/tmp/test_api_service.db"
 	defer os.Remove(dbPath)
 
-	cfg := &config.Config{
-		MultipleScrapeURL: "http://localhost:8081",
-		WinbuTVURL:        "http://localhost:8082",
-		SamehadakuURL:     "https://samehadaku.email",
-		OtakudesuURL:      "https://otakudesu.quest",
-		KusonimeURL:       "https://kusonime.com",
-	}
-	db, err := database.Init(dbPath, cfg)
+	db, err := database.Init(dbPath, &config.Config{})
 	if err != nil {
 		t.Fatalf("Failed to initialize database: %v", err)
 	}
 	defer db.Close()
 
 	cfg := &config.Config{
+		RedisAddr:      testRedisAddr,
 		APITimeout:     10 * time.Second,
 		MaxConcurrency: 5,
 		RateLimit:      100, // Allow 100 requests per second
@@ -62,9 +67,6 @@ func TestNewAPIService(t *testing.T) {
 }
 
 func TestProcessRequestWithMockAPI(t *testing.T) {
-	// Initialize logger
-	logger.Init()
-
 	// Create mock API server
 	mockResponse := map[string]interface{}{
 		"confidence_score": 0.8,
@@ -89,14 +91,7 @@ func TestProcessRequestWithMockAPI(t *testing.T) {
 	dbPath := "/tmp/test_process_request.db"
 	defer os.Remove(dbPath)
 
-	cfg := &config.Config{
-		MultipleScrapeURL: "http://localhost:8081",
-		WinbuTVURL:        "http://localhost:8082",
-		SamehadakuURL:     "https://samehadaku.email",
-		OtakudesuURL:      "https://otakudesu.quest",
-		KusonimeURL:       "https://kusonime.com",
-	}
-	db, err := database.Init(dbPath, cfg)
+	db, err := database.Init(dbPath, &config.Config{})
 	if err != nil {
 		t.Fatalf("Failed to initialize database: %v", err)
 	}
@@ -123,6 +118,7 @@ func TestProcessRequestWithMockAPI(t *testing.T) {
 	}
 
 	cfg := &config.Config{
+		RedisAddr:      testRedisAddr,
 		APITimeout:     10 * time.Second,
 		MaxConcurrency: 5,
 		RateLimit:      100, // Allow 100 requests per second
@@ -146,7 +142,7 @@ func TestProcessRequestWithMockAPI(t *testing.T) {
 		StartTime:  time.Now(),
 	}
 
-	response, err := service.ProcessRequest(ctx)
+	response, err := service.ProcessRequest(context.Background(), ctx)
 	if err != nil {
 		t.Errorf("ProcessRequest failed: %v", err)
 	}
@@ -166,27 +162,18 @@ func TestProcessRequestWithMockAPI(t *testing.T) {
 }
 
 func TestProcessRequestWithCache(t *testing.T) {
-	// Initialize logger
-	logger.Init()
-
 	// Create temporary database
 	dbPath := "/tmp/test_cache_request.db"
 	defer os.Remove(dbPath)
 
-	cfg := &config.Config{
-		MultipleScrapeURL: "http://localhost:8081",
-		WinbuTVURL:        "http://localhost:8082",
-		SamehadakuURL:     "https://samehadaku.email",
-		OtakudesuURL:      "https://otakudesu.quest",
-		KusonimeURL:       "https://kusonime.com",
-	}
-	db, err := database.Init(dbPath, cfg)
+	db, err := database.Init(dbPath, &config.Config{})
 	if err != nil {
 		t.Fatalf("Failed to initialize database: %v", err)
 	}
 	defer db.Close()
 
 	cfg := &config.Config{
+		RedisAddr:      testRedisAddr,
 		APITimeout:     10 * time.Second,
 		MaxConcurrency: 5,
 		RateLimit:      100, // Allow 100 requests per second
@@ -198,7 +185,7 @@ func TestProcessRequestWithCache(t *testing.T) {
 	service := NewAPIService(db, cfg)
 
 	// Pre-populate cache
-	cacheKey := "anime:/api/v1/home:d41d8cd98f00b204e9800998ecf8427e" // Empty params hash
+	cacheKey := service.cache.GenerateKey("anime", "/api/v1/home", map[string]string{})
 	cacheData := []byte(`{"cached": true, "source": "cache"}`)
 	service.cache.Set(cacheKey, cacheData, 15*time.Minute)
 
@@ -211,7 +198,7 @@ func TestProcessRequestWithCache(t *testing.T) {
 		StartTime:  time.Now(),
 	}
 
-	response, err := service.ProcessRequest(ctx)
+	response, err := service.ProcessRequest(context.Background(), ctx)
 	if err != nil {
 		t.Errorf("ProcessRequest with cache failed: %v", err)
 	}
@@ -227,13 +214,10 @@ func TestProcessRequestWithCache(t *testing.T) {
 }
 
 func TestBuildURL(t *testing.T) {
-	// Initialize logger
-	logger.Init()
-
 	service := &APIService{}
 
 	// Test without parameters
-	url := service.buildURL("http://example.com", "/api/v1/home", map[string]string{})
+	url := service.buildURL("http://example.com", "/api/v1/home", map[string]string{}, nil)
 	expected := "http://example.com/api/v1/home"
 	if url != expected {
 		t.Errorf("Expected URL '%s', got '%s'", expected, url)
@@ -244,7 +228,7 @@ func TestBuildURL(t *testing.T) {
 		"page": "1",
 		"sort": "latest",
 	}
-	url = service.buildURL("http://example.com", "/api/v1/search", params)
+	url = service.buildURL("http://example.com", "/api/v1/search", params, nil)
 
 	// URL should contain base URL and endpoint
 	if !contains(url, "http://example.com/api/v1/search") {
@@ -258,21 +242,11 @@ func TestBuildURL(t *testing.T) {
 }
 
 func TestGetHealthStatus(t *testing.T) {
-	// Initialize logger
-	logger.Init()
-
 	// Create temporary database
 	dbPath := "/tmp/test_health_status.db"
 	defer os.Remove(dbPath)
 
-	cfg := &config.Config{
-		MultipleScrapeURL: "http://localhost:8081",
-		WinbuTVURL:        "http://localhost:8082",
-		SamehadakuURL:     "https://samehadaku.email",
-		OtakudesuURL:      "https://otakudesu.quest",
-		KusonimeURL:       "https://kusonime.com",
-	}
-	db, err := database.Init(dbPath, cfg)
+	db, err := database.Init(dbPath, &config.Config{})
 	if err != nil {
 		t.Fatalf("Failed to initialize database: %v", err)
 	}
@@ -282,7 +256,7 @@ func TestGetHealthStatus(t *testing.T) {
 	db.LogHealthCheck(1, "OK", 500, "")
 	db.LogHealthCheck(2, "ERROR", 0, "Connection failed")
 
-	cfg := &config.Config{}
+	cfg := &config.Config{RedisAddr: testRedisAddr}
 	service := NewAPIService(db, cfg)
 
 	healthStatus, err := service.GetHealthStatus()
@@ -296,21 +270,11 @@ func TestGetHealthStatus(t *testing.T) {
 }
 
 func TestGetRequestLogs(t *testing.T) {
-	// Initialize logger
-	logger.Init()
-
 	// Create temporary database
 	dbPath := "/tmp/test_request_logs.db"
 	defer os.Remove(dbPath)
 
-	cfg := &config.Config{
-		MultipleScrapeURL: "http://localhost:8081",
-		WinbuTVURL:        "http://localhost:8082",
-		SamehadakuURL:     "https://samehadaku.email",
-		OtakudesuURL:      "https://otakudesu.quest",
-		KusonimeURL:       "https://kusonime.com",
-	}
-	db, err := database.Init(dbPath, cfg)
+	db, err := database.Init(dbPath, &config.Config{})
 	if err != nil {
 		t.Fatalf("Failed to initialize database: %v", err)
 	}
@@ -329,7 +293,7 @@ func TestGetRequestLogs(t *testing.T) {
 	}
 	db.LogRequest(log)
 
-	cfg := &config.Config{}
+	cfg := &config.Config{RedisAddr: testRedisAddr}
 	service := NewAPIService(db, cfg)
 
 	logs, err := service.GetRequestLogs(10)
@@ -347,27 +311,17 @@ func TestGetRequestLogs(t *testing.T) {
 }
 
 func TestGetCategories(t *testing.T) {
-	// Initialize logger
-	logger.Init()
-
 	// Create temporary database
 	dbPath := "/tmp/test_categories.db"
 	defer os.Remove(dbPath)
 
-	cfg := &config.Config{
-		MultipleScrapeURL: "http://localhost:8081",
-		WinbuTVURL:        "http://localhost:8082",
-		SamehadakuURL:     "https://samehadaku.email",
-		OtakudesuURL:      "https://otakudesu.quest",
-		KusonimeURL:       "https://kusonime.com",
-	}
-	db, err := database.Init(dbPath, cfg)
+	db, err := database.Init(dbPath, &config.Config{})
 	if err != nil {
 		t.Fatalf("Failed to initialize database: %v", err)
 	}
 	defer db.Close()
 
-	cfg := &config.Config{}
+	cfg := &config.Config{RedisAddr: testRedisAddr}
 	service := NewAPIService(db, cfg)
 
 	categories, err := service.GetCategories()
@@ -406,3 +360,463 @@ func containsSubstring(s, substr string) bool {
 	}
 	return false
 }
+
+// newUpstreamService returns a service backed by a fresh database whose API
+// sources point at the given upstreams for every default endpoint
+func newUpstreamService(t *testing.T, cfg *config.Config, upstreams map[string]string) *APIService {
+	t.Helper()
+
+	cfg.RedisAddr = testRedisAddr
+	cfg.APISources = upstreams
+	db, err := database.Init(filepath.Join(t.TempDir(), "test.db"), cfg)
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return NewAPIService(db, cfg)
+}
+
+// homeBody is a valid /api/v1/home response from a source
+func homeBody(source string) []byte {
+	body, _ := json.Marshal(map[string]interface{}{
+		"confidence_score": 0.9,
+		"message":          "success",
+		"source":           source,
+		"top10":            []interface{}{},
+		"new_eps": []interface{}{map[string]interface{}{
+			"judul":      source,
+			"url":        "https://" + source + ".test/episode/1",
+			"anime_slug": source,
+			"episode":    "1",
+			"cover":      "https://" + source + ".test/cover.jpg",
+		}},
+		"movies":       []interface{}{},
+		"jadwal_rilis": map[string]interface{}{},
+	})
+	return body
+}
+
+func homeRequest() *domain.RequestContext {
+	return &domain.RequestContext{
+		Endpoint:   "/api/v1/home",
+		Category:   "anime",
+		Parameters: map[string]string{},
+		ClientIP:   "127.0.0.1",
+		UserAgent:  "test-agent",
+		StartTime:  time.Now(),
+	}
+}
+
+// fetchWaiters returns how many callers wait on the in-flight fetch of a cache key
+func (s *APIService) fetchWaiters(cacheKey string) int {
+	s.fetchesMu.Lock()
+	defer s.fetchesMu.Unlock()
+	if fetch, exists := s.fetches[cacheKey]; exists {
+		return fetch.waiters
+	}
+	return 0
+}
+
+// waitFor polls condition until it holds or the timeout passes
+func waitFor(t *testing.T, timeout time.Duration, condition func() bool) bool {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if condition() {
+			return true
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	return condition()
+}
+
+func TestConcurrentIdenticalRequestsShareOneUpstreamCall(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(homeBody("alpha"))
+	}))
+	defer upstream.Close()
+
+	service := newUpstreamService(t, &config.Config{}, map[string]string{"alpha": upstream.URL})
+	cacheKey := service.cache.GenerateKey("anime", "/api/v1/home", map[string]string{})
+
+	const callers = 10
+	responses := make(chan *domain.APIResponse, callers)
+	errs := make(chan error, callers)
+	for i := 0; i < callers; i++ {
+		go func() {
+			response, err := service.ProcessRequest(context.Background(), homeRequest())
+			responses <- response
+			errs <- err
+		}()
+	}
+
+	if !waitFor(t, 5*time.Second, func() bool { return service.fetchWaiters(cacheKey) == callers }) {
+		close(release)
+		t.Fatalf("Expected %d callers waiting on one fetch, got %d", callers, service.fetchWaiters(cacheKey))
+	}
+	close(release)
+
+	coalesced := 0
+	for i := 0; i < callers; i++ {
+		response := <-responses
+		if err := <-errs; err != nil {
+			t.Fatalf("ProcessRequest failed: %v", err)
+		}
+		if response.SourceName != "alpha" {
+			t.Errorf("Expected source 'alpha', got '%s'", response.SourceName)
+		}
+		if response.Coalesced {
+			coalesced++
+		}
+	}
+
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("Expected 1 upstream call, got %d", got)
+	}
+	if coalesced != callers-1 {
+		t.Errorf("Expected %d coalesced responses, got %d", callers-1, coalesced)
+	}
+}
+
+func TestStaleEntryIsServedAndRefreshed(t *testing.T) {
+	var calls int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(homeBody("fresh"))
+	}))
+	defer upstream.Close()
+
+	service := newUpstreamService(t, &config.Config{
+		CacheStaleWhileRevalidate: true,
+		CacheStaleTTL:             time.Hour,
+	}, map[string]string{"alpha": upstream.URL})
+	cacheKey := service.cache.GenerateKey("anime", "/api/v1/home", map[string]string{})
+	service.cache.SetEntry(cacheKey, homeBody("stale"), time.Nanosecond, time.Hour)
+
+	response, err := service.ProcessRequest(context.Background(), homeRequest())
+	if err != nil {
+		t.Fatalf("ProcessRequest failed: %v", err)
+	}
+	if response.CacheStatus != "STALE" {
+		t.Errorf("Expected cache status STALE, got %s", response.CacheStatus)
+	}
+	if !strings.Contains(string(response.Data), `"stale"`) {
+		t.Errorf("Expected the stale entry to be served, got %s", response.Data)
+	}
+
+	refreshed := waitFor(t, 5*time.Second, func() bool {
+		entry, _ := service.cache.GetEntry(cacheKey)
+		return entry != nil && !entry.IsStale() && strings.Contains(string(entry.Value), `"fresh"`)
+	})
+	if !refreshed {
+		t.Fatal("Expected the stale entry to be refreshed in the background")
+	}
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("Expected 1 upstream call, got %d", got)
+	}
+
+	response, err = service.ProcessRequest(context.Background(), homeRequest())
+	if err != nil {
+		t.Fatalf("ProcessRequest failed: %v", err)
+	}
+	if response.CacheStatus != "HIT" || !strings.Contains(string(response.Data), `"fresh"`) {
+		t.Errorf("Expected a fresh cache hit, got %s: %s", response.CacheStatus, response.Data)
+	}
+}
+
+func TestStaleEntryIsServedOnUpstreamFailureUntilHardExpiry(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer upstream.Close()
+
+	service := newUpstreamService(t, &config.Config{
+		CacheStaleIfError: true,
+		CacheStaleTTL:     time.Hour,
+	}, map[string]string{"alpha": upstream.URL})
+	cacheKey := service.cache.GenerateKey("anime", "/api/v1/home", map[string]string{})
+
+	// Past its TTL but not its hard expiry: served when every source fails
+	service.cache.SetEntry(cacheKey, homeBody("stale"), time.Nanosecond, time.Hour)
+	response, err := service.ProcessRequest(context.Background(), homeRequest())
+	if err != nil {
+		t.Fatalf("Expected the stale entry after upstream failure, got error: %v", err)
+	}
+	if response.CacheStatus != "STALE" || !strings.Contains(string(response.Data), `"stale"`) {
+		t.Errorf("Expected the stale entry, got %s: %s", response.CacheStatus, response.Data)
+	}
+
+	// Past its hard expiry: the failure is returned
+	service.cache.SetEntry(cacheKey, homeBody("expired"), time.Nanosecond, time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	if response, err := service.ProcessRequest(context.Background(), homeRequest()); err == nil {
+		t.Errorf("Expected an error once the entry hard-expired, got %s", response.Data)
+	}
+}
+
+func TestCancelledCallerAbortsUpstreamWithoutTrippingBreaker(t *testing.T) {
+	started := make(chan struct{}, 1)
+	aborted := make(chan struct{}, 1)
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		select {
+		case <-r.Context().Done():
+			aborted <- struct{}{}
+		case <-time.After(5 * time.Second):
+			w.Write(homeBody("alpha"))
+		}
+	}))
+	defer upstream.Close()
+
+	service := newUpstreamService(t, &config.Config{
+		CircuitBreakerThreshold:   1,
+		CircuitBreakerOpenTimeout: time.Minute,
+	}, map[string]string{"alpha": upstream.URL})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error, 1)
+	go func() {
+		_, err := service.ProcessRequest(ctx, homeRequest())
+		errs <- err
+	}()
+
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Upstream was never called")
+	}
+	cancel()
+
+	select {
+	case err := <-errs:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected a cancellation error, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("ProcessRequest did not return after its caller was cancelled")
+	}
+	select {
+	case <-aborted:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Upstream request was not aborted")
+	}
+
+	sources, err := service.db.GetAPISourcesByEndpoint("/api/v1/home", "anime")
+	if err != nil || len(sources) != 1 {
+		t.Fatalf("Expected one API source, got %d (err: %v)", len(sources), err)
+	}
+	// The aborted attempt says nothing about the source
+	tripped := waitFor(t, 200*time.Millisecond, func() bool {
+		snapshot, exists := service.breakers.Snapshot(sources[0].ID)
+		return exists && (snapshot.State != "closed" || snapshot.ConsecutiveFailures > 0)
+	})
+	if tripped {
+		snapshot, _ := service.breakers.Snapshot(sources[0].ID)
+		t.Errorf("Expected the breaker to stay closed without failures, got %s with %d failures", snapshot.State, snapshot.ConsecutiveFailures)
+	}
+}
+
+func TestDetailEndpointWithoutProbeIsSkipped(t *testing.T) {
+	var calls int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer upstream.Close()
+
+	service := newUpstreamService(t, &config.Config{HealthRoutingEnabled: true}, map[string]string{"alpha": upstream.URL})
+
+	sources, err := service.db.GetAPISourcesByEndpoint("/api/v1/anime-detail", "anime")
+	if err != nil || len(sources) != 1 {
+		t.Fatalf("Expected one anime-detail source, got %d: %v", len(sources), err)
+	}
+	// The placeholder probe seeded for anime-detail is inactive
+	service.checkAPIHealth(sources[0], "/api/v1/anime-detail")
+
+	if got := atomic.LoadInt32(&calls); got != 0 {
+		t.Errorf("Expected no upstream call without a probe, got %d", got)
+	}
+	status, err := service.db.GetLatestHealthStatus(sources[0].ID)
+	if err != nil {
+		t.Fatalf("Failed to get health status: %v", err)
+	}
+	if status != healthSkipped {
+		t.Errorf("Expected status %s, got %q", healthSkipped, status)
+	}
+
+	routing, err := service.sourceRouting()
+	if err != nil {
+		t.Fatalf("Failed to get source routing: %v", err)
+	}
+	if state := service.routingState(routing[sources[0].ID]); state != routingPreferred {
+		t.Errorf("Expected a skipped source to stay %s, got %s", routingPreferred, state)
+	}
+}
+
+func TestMetricCategoryOnlyLabelsConfiguredCategories(t *testing.T) {
+	service := newUpstreamService(t, &config.Config{}, map[string]string{})
+
+	for category, want := range map[string]string{
+		"anime":         "anime",
+		"all":           "all",
+		"random-1f3a9c": "unknown",
+		"":              "unknown",
+	} {
+		reqCtx := &domain.RequestContext{Endpoint: "/api/v1/home", Category: category}
+		if got := service.metricCategory(reqCtx); got != want {
+			t.Errorf("metricCategory(%q) = %q, want %q", category, got, want)
+		}
+	}
+}
+
+func TestUnknownAPIKeysAreNotCached(t *testing.T) {
+	service := newUpstreamService(t, &config.Config{}, map[string]string{})
+	_, rawKey, err := service.CreateAPIKey("client", 60, 0)
+	if err != nil {
+		t.Fatalf("Failed to create API key: %v", err)
+	}
+
+	for _, unknown := range []string{"ak_unknown1", "ak_unknown2", "ak_unknown3"} {
+		if _, err := service.CheckRateLimit(unknown, "127.0.0.1"); !errors.Is(err, ErrInvalidAPIKey) {
+			t.Errorf("Expected ErrInvalidAPIKey for an unknown key, got %v", err)
+		}
+	}
+	if _, err := service.CheckRateLimit(rawKey, "127.0.0.1"); err != nil {
+		t.Fatalf("CheckRateLimit failed for a valid key: %v", err)
+	}
+
+	service.apiKeys.mu.Lock()
+	cached := len(service.apiKeys.entries)
+	service.apiKeys.mu.Unlock()
+	if cached != 1 {
+		t.Errorf("Expected only the valid key to be cached, got %d entries", cached)
+	}
+}
+
+func TestRequestsAreLoggedUnderTheirRoute(t *testing.T) {
+	service := newUpstreamService(t, &config.Config{}, map[string]string{})
+	for _, day := range []string{"senin", "selasa"} {
+		service.logRequest(&domain.RequestContext{
+			Endpoint: "/api/v1/jadwal-rilis/" + day,
+			Route:    "/api/v1/jadwal-rilis/:day",
+			Category: "anime",
+		}, &domain.FallbackResult{}, 10*time.Millisecond)
+	}
+
+	logs, err := service.GetRequestLogs(10)
+	if err != nil {
+		t.Fatalf("GetRequestLogs failed: %v", err)
+	}
+	if len(logs) != 2 {
+		t.Fatalf("Expected 2 request logs, got %d", len(logs))
+	}
+	for _, log := range logs {
+		if log.Endpoint != "/api/v1/jadwal-rilis/:day" {
+			t.Errorf("Expected the route to be logged, got %s", log.Endpoint)
+		}
+	}
+}
+
+func TestSamehadakuSourcesGetSearchMapping(t *testing.T) {
+	service := newUpstreamService(t, &config.Config{}, map[string]string{"alpha": "http://alpha.test"})
+	endpoints, err := service.db.GetEndpointsByCategory("anime")
+	if err != nil {
+		t.Fatalf("Failed to get endpoints: %v", err)
+	}
+	searchID := 0
+	for _, endpoint := range endpoints {
+		if endpoint.Path == "/api/v1/search" {
+			searchID = endpoint.ID
+		}
+	}
+	if searchID == 0 {
+		t.Fatal("Expected a search endpoint")
+	}
+
+	hasMapping := func(sourceName string) bool {
+		return service.requestMapping(sourceName, "/api/v1/search").PathSuffix == "/"
+	}
+	// Load the mappings first, so the checks below also cover invalidation
+	if hasMapping("alpha") {
+		t.Fatal("Expected no search mapping for a source on another URL")
+	}
+
+	if err := service.CreateAPISource(searchID, "created", "https://samehadaku.example", 5, false); err != nil {
+		t.Fatalf("CreateAPISource failed: %v", err)
+	}
+	if !hasMapping("created") {
+		t.Error("Expected a source created on a samehadaku URL to get the search mapping")
+	}
+
+	if err := service.CreateAPISourceForAllEndpoints("anime", "everywhere", "https://v2.samehadaku.example", 5, false); err != nil {
+		t.Fatalf("CreateAPISourceForAllEndpoints failed: %v", err)
+	}
+	if !hasMapping("everywhere") {
+		t.Error("Expected a source created for all endpoints on a samehadaku URL to get the search mapping")
+	}
+
+	sources, err := service.GetAPISourcesByName("alpha")
+	if err != nil {
+		t.Fatalf("Failed to get sources: %v", err)
+	}
+	for _, source := range sources {
+		if source.EndpointPath != "/api/v1/search" {
+			continue
+		}
+		if err := service.UpdateAPISource(source.ID, source.SourceName, "https://samehadaku.example", source.Priority, source.IsPrimary, source.IsActive); err != nil {
+			t.Fatalf("UpdateAPISource failed: %v", err)
+		}
+	}
+	if !hasMapping("alpha") {
+		t.Error("Expected a source moved to a samehadaku URL to get the search mapping")
+	}
+}
+
+func TestHangingHealthySourceLeavesTimeForDemotedSources(t *testing.T) {
+	alpha := delayedUpstream(5*time.Second, animeDetailBody("alpha"))
+	defer alpha.Close()
+	beta := delayedUpstream(0, animeDetailBody("beta"))
+	defer beta.Close()
+
+	service := newUpstreamService(t, &config.Config{
+		RequestDeadline:      1500 * time.Millisecond,
+		HealthRoutingEnabled: true,
+		HealthRoutingMaxAge:  time.Hour,
+	}, map[string]string{"alpha": alpha.URL, "beta": beta.URL})
+	sources, err := service.db.GetAPISourcesByName("beta")
+	if err != nil {
+		t.Fatalf("Failed to get sources: %v", err)
+	}
+	for _, source := range sources {
+		if err := service.db.LogHealthCheck(source.ID, "ERROR", 0, "connection refused"); err != nil {
+			t.Fatalf("Failed to log health check: %v", err)
+		}
+	}
+
+	response, err := service.ProcessRequest(context.Background(), &domain.RequestContext{
+		Endpoint:   "/api/v1/anime-detail",
+		Category:   "anime",
+		Parameters: map[string]string{"id": "1"},
+		ClientIP:   "127.0.0.1",
+		UserAgent:  "test-agent",
+		StartTime:  time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("Expected the demoted source to answer within the deadline: %v", err)
+	}
+
+	var data map[string]interface{}
+	if err := json.Unmarshal(response.Data, &data); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if data["judul"] != "beta" {
+		t.Errorf("Expected the demoted source beta to answer, got %v", data["judul"])
+	}
+}
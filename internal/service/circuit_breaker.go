@@ -0,0 +1,142 @@
+package service
+
+import (
+	"apicategorywithfallback/pkg/circuitbreaker"
+	"apicategorywithfallback/pkg/logger"
+	"apicategorywithfallback/pkg/metrics"
+	"apicategorywithfallback/pkg/notifier"
+	"context"
+	"errors"
+	"sync"
+)
+
+// newBreakerRegistry creates the per-source circuit breaker registry and restores
+// breakers that were open when the service last stopped
+func (s *APIService) newBreakerRegistry() *circuitbreaker.Registry {
+	registry := circuitbreaker.NewRegistry(circuitbreaker.Settings{
+		FailureThreshold:  s.config.CircuitBreakerThreshold,
+		OpenTimeout:       s.config.CircuitBreakerOpenTimeout,
+		HalfOpenMaxProbes: s.config.CircuitBreakerHalfOpenProbes,
+	}, s.onBreakerTransition)
+
+	if s.config.CircuitBreakerThreshold <= 0 {
+		logger.Info("Circuit breaker disabled (CIRCUIT_BREAKER_THRESHOLD <= 0)")
+		return registry
+	}
+
+	openBreakers, err := s.db.GetOpenCircuitBreakers()
+	if err != nil {
+		logger.Warnf("Failed to restore circuit breaker state: %v", err)
+		return registry
+	}
+
+	for _, ev := range openBreakers {
+		registry.Restore(ev.APISourceID, ev.SourceName, ev.CreatedAt, ev.Reason)
+		metrics.SetCircuitState(ev.APISourceID, ev.SourceName, string(circuitbreaker.StateOpen))
+		logger.Infof("🔌 Restored open circuit for %s (ID: %d)", ev.SourceName, ev.APISourceID)
+	}
+
+	return registry
+}
+
+// onBreakerTransition logs, persists and alerts on circuit breaker state changes
+func (s *APIService) onBreakerTransition(t circuitbreaker.Transition) {
+	switch t.To {
+	case circuitbreaker.StateOpen:
+		logger.Warnf("🔌 Circuit OPEN for %s (ID: %d): %s", t.Name, t.ID, t.Reason)
+	case circuitbreaker.StateHalfOpen:
+		logger.Infof("🔌 Circuit HALF-OPEN for %s (ID: %d): %s", t.Name, t.ID, t.Reason)
+	case circuitbreaker.StateClosed:
+		logger.Infof("🔌 Circuit CLOSED for %s (ID: %d): %s", t.Name, t.ID, t.Reason)
+	}
+
+	metrics.SetCircuitState(t.ID, t.Name, string(t.To))
+
+	if err := s.db.LogCircuitBreakerEvent(t.ID, string(t.From), string(t.To), t.Reason); err != nil {
+		logger.Errorf("Failed to log circuit breaker event for %s: %v", t.Name, err)
+	}
+
+	// Alert when a circuit trips and when it recovers; half-open is only a probe
+	if t.To == circuitbreaker.StateOpen || (t.To == circuitbreaker.StateClosed && t.From != circuitbreaker.StateClosed) {
+		event := notifier.Event{
+			Type:    notifier.EventCircuitChanged,
+			Source:  t.Name,
+			From:    string(t.From),
+			To:      string(t.To),
+			Message: t.Reason,
+			At:      t.At,
+		}
+		if source, err := s.db.GetAPISource(t.ID); err == nil && source != nil {
+			event.Endpoint = source.EndpointPath
+		}
+		s.notify(event)
+	}
+}
+
+// GetCircuitBreakerStates returns the live state of every circuit breaker
+func (s *APIService) GetCircuitBreakerStates() []circuitbreaker.Snapshot {
+	return s.breakers.Snapshots()
+}
+
+// isCancellation reports whether err was caused by the request being cancelled
+// (client gone or another source already won) rather than by the source itself
+func isCancellation(err error) bool {
+	return errors.Is(err, context.Canceled)
+}
+
+// sourceOutcomeTracker aggregates the results of concurrent attempts (primary +
+// fallbacks) per API source and reports a single outcome to its breaker once
+// every attempt for that source has finished
+type sourceOutcomeTracker struct {
+	mu       sync.Mutex
+	breakers map[int]*circuitbreaker.Breaker
+	pending  map[int]int
+	success  map[int]bool
+	lastErr  map[int]string
+}
+
+func newSourceOutcomeTracker() *sourceOutcomeTracker {
+	return &sourceOutcomeTracker{
+		breakers: make(map[int]*circuitbreaker.Breaker),
+		pending:  make(map[int]int),
+		success:  make(map[int]bool),
+		lastErr:  make(map[int]string),
+	}
+}
+
+// add registers attempts for a source
+func (t *sourceOutcomeTracker) add(sourceID int, breaker *circuitbreaker.Breaker, attempts int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.breakers[sourceID] = breaker
+	t.pending[sourceID] += attempts
+}
+
+// done records the result of one attempt; cancelled attempts count as neither
+// success nor failure
+func (t *sourceOutcomeTracker) done(sourceID int, err error) {
+	t.mu.Lock()
+	if err == nil {
+		t.success[sourceID] = true
+	} else if !isCancellation(err) {
+		t.lastErr[sourceID] = err.Error()
+	}
+	t.pending[sourceID]--
+	if t.pending[sourceID] > 0 {
+		t.mu.Unlock()
+		return
+	}
+	breaker := t.breakers[sourceID]
+	succeeded := t.success[sourceID]
+	reason := t.lastErr[sourceID]
+	t.mu.Unlock()
+
+	if succeeded {
+		breaker.RecordSuccess()
+	} else if reason != "" {
+		breaker.RecordFailure(reason)
+	} else {
+		breaker.Release()
+	}
+}
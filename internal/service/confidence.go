@@ -0,0 +1,147 @@
+package service
+
+import (
+	"apicategorywithfallback/pkg/database"
+	"apicategorywithfallback/pkg/logger"
+	"apicategorywithfallback/pkg/validator"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrConfidencePolicyNotFound is returned when a confidence policy does not exist
+var ErrConfidencePolicyNotFound = errors.New("confidence policy not found")
+
+// ErrInvalidConfidencePolicy is returned when a confidence policy fails validation
+var ErrInvalidConfidencePolicy = errors.New("invalid confidence policy")
+
+// anyEndpoint is the endpoint path of policies that apply to every endpoint
+const anyEndpoint = "*"
+
+// confidencePolicy returns the most specific active policy for a source's
+// endpoint, falling back to validator.DefaultConfidencePolicy
+func (s *APIService) confidencePolicy(sourceName, endpoint string) validator.ConfidencePolicy {
+	entries, err := s.confidencePolicies()
+	if err != nil {
+		logger.Warnf("Failed to load confidence policies: %v", err)
+	}
+
+	candidates := [][2]string{
+		{sourceName, endpoint},
+		{anySource, endpoint},
+		{sourceName, anyEndpoint},
+		{anySource, anyEndpoint},
+	}
+	for _, candidate := range candidates {
+		if policy, exists := entries[requestMappingKey(candidate[0], candidate[1])]; exists {
+			return policy
+		}
+	}
+	return validator.DefaultConfidencePolicy
+}
+
+// confidencePolicies returns the cached active policies by source and endpoint
+func (s *APIService) confidencePolicies() (map[string]validator.ConfidencePolicy, error) {
+	return s.confidence.Get(func() (map[string]validator.ConfidencePolicy, error) {
+		policies, err := s.db.GetConfidencePolicies()
+		if err != nil {
+			return nil, err
+		}
+
+		entries := make(map[string]validator.ConfidencePolicy)
+		for _, policy := range policies {
+			if policy.IsActive {
+				entries[requestMappingKey(policy.SourceName, policy.EndpointPath)] = validator.ConfidencePolicy{
+					MinScore:     policy.MinScore,
+					MissingScore: policy.MissingScore,
+					AssumedScore: policy.AssumedScore,
+				}
+			}
+		}
+		return entries, nil
+	})
+}
+
+// GetConfidencePolicies returns all confidence policies
+func (s *APIService) GetConfidencePolicies() ([]database.ConfidencePolicy, error) {
+	return s.db.GetConfidencePolicies()
+}
+
+// CreateConfidencePolicy validates and stores a new confidence policy
+func (s *APIService) CreateConfidencePolicy(policy *database.ConfidencePolicy) error {
+	if err := s.validateConfidencePolicy(policy); err != nil {
+		return err
+	}
+
+	defer s.confidence.Invalidate()
+	return s.db.CreateConfidencePolicy(policy)
+}
+
+// UpdateConfidencePolicy validates and replaces a confidence policy
+func (s *APIService) UpdateConfidencePolicy(policy *database.ConfidencePolicy) error {
+	existing, err := s.db.GetConfidencePolicy(policy.ID)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return ErrConfidencePolicyNotFound
+	}
+	if err := s.validateConfidencePolicy(policy); err != nil {
+		return err
+	}
+
+	defer s.confidence.Invalidate()
+	return s.db.UpdateConfidencePolicy(policy)
+}
+
+// DeleteConfidencePolicy deletes a confidence policy
+func (s *APIService) DeleteConfidencePolicy(id int) error {
+	existing, err := s.db.GetConfidencePolicy(id)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return ErrConfidencePolicyNotFound
+	}
+
+	defer s.confidence.Invalidate()
+	return s.db.DeleteConfidencePolicy(id)
+}
+
+// validateConfidencePolicy trims and checks a policy before it is stored; each
+// source and endpoint pair may have only one policy
+func (s *APIService) validateConfidencePolicy(policy *database.ConfidencePolicy) error {
+	policy.SourceName = strings.TrimSpace(policy.SourceName)
+	policy.EndpointPath = strings.TrimSpace(policy.EndpointPath)
+	policy.MissingScore = strings.ToLower(strings.TrimSpace(policy.MissingScore))
+
+	if policy.SourceName == "" {
+		return fmt.Errorf("%w: source_name is required (use %q for every source)", ErrInvalidConfidencePolicy, anySource)
+	}
+	if policy.EndpointPath != anyEndpoint {
+		if err := validateEndpointPath(policy.EndpointPath); err != nil {
+			return fmt.Errorf("%w: %v (use %q for every endpoint)", ErrInvalidConfidencePolicy, err, anyEndpoint)
+		}
+	}
+	rule := validator.ConfidencePolicy{
+		MinScore:     policy.MinScore,
+		MissingScore: policy.MissingScore,
+		AssumedScore: policy.AssumedScore,
+	}
+	if err := rule.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidConfidencePolicy, err)
+	}
+
+	existing, err := s.db.GetConfidencePolicies()
+	if err != nil {
+		return err
+	}
+	key := requestMappingKey(policy.SourceName, policy.EndpointPath)
+	for _, other := range existing {
+		if other.ID != policy.ID && requestMappingKey(other.SourceName, other.EndpointPath) == key {
+			return fmt.Errorf("%w: source %s already has a policy for %s", ErrInvalidConfidencePolicy, policy.SourceName, policy.EndpointPath)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,258 @@
+package service
+
+import (
+	"apicategorywithfallback/pkg/auth"
+	"apicategorywithfallback/pkg/database"
+	"apicategorywithfallback/pkg/logger"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrInvalidCredentials is returned for a wrong username or password
+	ErrInvalidCredentials = errors.New("invalid username or password")
+	// ErrLastAdmin is returned when a change would leave no active admin
+	ErrLastAdmin = errors.New("at least one active admin user is required")
+	// ErrUserNotFound is returned for unknown dashboard user IDs
+	ErrUserNotFound = errors.New("dashboard user not found")
+)
+
+// dashboardTokenPrefix is prepended to generated dashboard bearer tokens
+const dashboardTokenPrefix = "dt_"
+
+// dummyPasswordHash is compared against on unknown usernames so that logins
+// take the same time whether or not the user exists
+var dummyPasswordHash = sync.OnceValue(func() string {
+	hash, _ := auth.HashPassword("dummy-password-for-timing")
+	return hash
+})
+
+// DashboardAuthEnabled reports whether the dashboard requires authentication
+func (s *APIService) DashboardAuthEnabled() bool {
+	return s.config.DashboardAuthEnabled
+}
+
+// DashboardSessionTTL returns how long dashboard login sessions last
+func (s *APIService) DashboardSessionTTL() time.Duration {
+	return s.config.DashboardSessionTTL
+}
+
+// BootstrapDashboardAdmin creates the initial admin user when no dashboard users exist.
+// Without ADMIN_PASSWORD a random password is generated and logged once.
+func (s *APIService) BootstrapDashboardAdmin() error {
+	if !s.config.DashboardAuthEnabled {
+		logger.Warn("⚠️ Dashboard authentication is disabled (DASHBOARD_AUTH_ENABLED=false)")
+		return nil
+	}
+
+	count, err := s.db.CountDashboardUsers()
+	if err != nil {
+		return fmt.Errorf("failed to count dashboard users: %w", err)
+	}
+	if count > 0 {
+		return nil
+	}
+
+	password := s.config.AdminPassword
+	generated := password == ""
+	if generated {
+		token, err := auth.GenerateToken("")
+		if err != nil {
+			return err
+		}
+		password = token[:24]
+	}
+
+	hash, err := auth.HashPassword(password)
+	if err != nil {
+		return fmt.Errorf("invalid ADMIN_PASSWORD: %w", err)
+	}
+	if _, err := s.db.CreateDashboardUser(s.config.AdminUsername, hash, string(auth.RoleAdmin)); err != nil {
+		return fmt.Errorf("failed to create admin user: %w", err)
+	}
+
+	if generated {
+		logger.Warnf("🔑 Created dashboard admin '%s' with generated password: %s (change it after logging in)", s.config.AdminUsername, password)
+	} else {
+		logger.Infof("🔑 Created dashboard admin '%s'", s.config.AdminUsername)
+	}
+	return nil
+}
+
+// Login checks a username and password and starts a session.
+// It returns the raw session token, which is only ever sent to the client.
+func (s *APIService) Login(username, password string) (string, *database.DashboardUser, error) {
+	user, err := s.db.GetDashboardUserByUsername(username)
+	if err != nil {
+		return "", nil, err
+	}
+	if user == nil {
+		auth.CheckPassword(dummyPasswordHash(), password)
+		return "", nil, ErrInvalidCredentials
+	}
+	if !auth.CheckPassword(user.PasswordHash, password) || !user.IsActive {
+		return "", nil, ErrInvalidCredentials
+	}
+
+	token, err := auth.GenerateToken("")
+	if err != nil {
+		return "", nil, err
+	}
+
+	if err := s.db.DeleteExpiredDashboardSessions(); err != nil {
+		logger.Warnf("Failed to prune expired dashboard sessions: %v", err)
+	}
+	expiresAt := time.Now().Add(s.config.DashboardSessionTTL)
+	if err := s.db.CreateDashboardSession(auth.HashToken(token), user.ID, expiresAt); err != nil {
+		return "", nil, err
+	}
+
+	logger.Infof("Dashboard login: %s (%s)", user.Username, user.Role)
+	return token, user, nil
+}
+
+// Logout ends a dashboard session
+func (s *APIService) Logout(sessionToken string) error {
+	return s.db.DeleteDashboardSession(auth.HashToken(sessionToken))
+}
+
+// AuthenticateSession resolves a session cookie to a principal, or nil when it is invalid or expired
+func (s *APIService) AuthenticateSession(sessionToken string) (*auth.Principal, error) {
+	user, err := s.db.GetDashboardSessionUser(auth.HashToken(sessionToken))
+	if err != nil || user == nil {
+		return nil, err
+	}
+
+	return &auth.Principal{ID: user.ID, Name: user.Username, Role: auth.Role(user.Role), Kind: auth.PrincipalUser}, nil
+}
+
+// AuthenticateBearer resolves a dashboard bearer token to a principal, or nil when it is unknown
+func (s *APIService) AuthenticateBearer(bearerToken string) (*auth.Principal, error) {
+	token, err := s.db.GetDashboardTokenByHash(auth.HashToken(bearerToken))
+	if err != nil || token == nil {
+		return nil, err
+	}
+
+	if err := s.db.TouchDashboardToken(token.ID); err != nil {
+		logger.Warnf("Failed to record use of dashboard token %s: %v", token.Name, err)
+	}
+
+	return &auth.Principal{ID: token.ID, Name: token.Name, Role: auth.Role(token.Role), Kind: auth.PrincipalToken}, nil
+}
+
+// GetAllDashboardUsers returns all dashboard users
+func (s *APIService) GetAllDashboardUsers() ([]database.DashboardUser, error) {
+	return s.db.GetAllDashboardUsers()
+}
+
+// CreateDashboardUser creates a dashboard user with the given role
+func (s *APIService) CreateDashboardUser(username, password, role string) (*database.DashboardUser, error) {
+	parsedRole, err := auth.ParseRole(role)
+	if err != nil {
+		return nil, err
+	}
+
+	hash, err := auth.HashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.db.CreateDashboardUser(username, hash, string(parsedRole))
+}
+
+// UpdateDashboardUser changes a user's role, status and (when non-empty) password
+func (s *APIService) UpdateDashboardUser(id int, role string, isActive bool, password string) error {
+	parsedRole, err := auth.ParseRole(role)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.db.GetDashboardUser(id)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return ErrUserNotFound
+	}
+
+	if user.Role == string(auth.RoleAdmin) && (parsedRole != auth.RoleAdmin || !isActive) {
+		if err := s.ensureOtherActiveAdmin(id); err != nil {
+			return err
+		}
+	}
+
+	if password != "" {
+		hash, err := auth.HashPassword(password)
+		if err != nil {
+			return err
+		}
+		if err := s.db.UpdateDashboardUserPassword(id, hash); err != nil {
+			return err
+		}
+	}
+
+	return s.db.UpdateDashboardUser(id, string(parsedRole), isActive)
+}
+
+// DeleteDashboardUser deletes a dashboard user
+func (s *APIService) DeleteDashboardUser(id int) error {
+	user, err := s.db.GetDashboardUser(id)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return ErrUserNotFound
+	}
+
+	if user.Role == string(auth.RoleAdmin) {
+		if err := s.ensureOtherActiveAdmin(id); err != nil {
+			return err
+		}
+	}
+
+	return s.db.DeleteDashboardUser(id)
+}
+
+// ensureOtherActiveAdmin fails when the given user is the only active admin
+func (s *APIService) ensureOtherActiveAdmin(id int) error {
+	others, err := s.db.CountActiveAdmins(id)
+	if err != nil {
+		return err
+	}
+	if others == 0 {
+		return ErrLastAdmin
+	}
+	return nil
+}
+
+// GetAllDashboardTokens returns all dashboard bearer tokens
+func (s *APIService) GetAllDashboardTokens() ([]database.DashboardToken, error) {
+	return s.db.GetAllDashboardTokens()
+}
+
+// CreateDashboardToken generates a bearer token with the given role. The raw token is returned only here.
+func (s *APIService) CreateDashboardToken(name, role string) (*database.DashboardToken, string, error) {
+	parsedRole, err := auth.ParseRole(role)
+	if err != nil {
+		return nil, "", err
+	}
+
+	rawToken, err := auth.GenerateToken(dashboardTokenPrefix)
+	if err != nil {
+		return nil, "", err
+	}
+
+	token, err := s.db.CreateDashboardToken(name, auth.HashToken(rawToken), auth.DisplayPrefix(rawToken), string(parsedRole))
+	if err != nil {
+		return nil, "", err
+	}
+
+	return token, rawToken, nil
+}
+
+// DeleteDashboardToken revokes a dashboard bearer token
+func (s *APIService) DeleteDashboardToken(id int) error {
+	return s.db.DeleteDashboardToken(id)
+}
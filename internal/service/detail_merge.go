@@ -0,0 +1,130 @@
+package service
+
+import (
+	"apicategorywithfallback/internal/domain"
+	"apicategorywithfallback/pkg/logger"
+	"apicategorywithfallback/pkg/merge"
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+)
+
+// defaultDetailMergeStrategies are the per-field strategies of merged detail
+// responses; DETAIL_MERGE_STRATEGIES_JSON overrides them field by field
+var defaultDetailMergeStrategies = merge.Strategies{
+	"episode_list":      merge.StrategyLongestList,
+	"recommendations":   merge.StrategyUnionByURL,
+	"streaming_servers": merge.StrategyUnionByURL,
+	"download_links":    merge.StrategyUnionByURL,
+}
+
+// fieldSourcesKey is the detail object field recording which sources contributed each merged field
+const fieldSourcesKey = "field_sources"
+
+// detailMergeStrategies returns the default strategies overlaid with the configured ones
+func detailMergeStrategies(configured map[string]string) merge.Strategies {
+	strategies := make(merge.Strategies, len(defaultDetailMergeStrategies)+len(configured))
+	for field, strategy := range defaultDetailMergeStrategies {
+		strategies[field] = strategy
+	}
+	for field, strategy := range configured {
+		if err := (merge.Strategies{field: strategy}).Validate(); err != nil {
+			logger.Warnf("Ignoring detail merge strategy: %v", err)
+			continue
+		}
+		strategies[field] = strategy
+	}
+	return strategies
+}
+
+// mergeDetailResponses waits up to DetailMergeWait for the rest of a bruteforce
+// race, then merges the valid detail payloads field by field. The first valid
+// response is returned as is when no other source answers in time.
+func (s *APIService) mergeDetailResponses(ctx context.Context, first *domain.APIResponse, results <-chan *domain.APIResponse) *domain.APIResponse {
+	valid := []*domain.APIResponse{first}
+	timer := time.NewTimer(s.config.DetailMergeWait)
+	defer timer.Stop()
+
+collect:
+	for {
+		select {
+		case resp, ok := <-results:
+			if !ok {
+				break collect
+			}
+			if resp != first && resp.Error == nil && resp.Data != nil {
+				valid = append(valid, resp)
+			}
+		case <-timer.C:
+			break collect
+		case <-ctx.Done():
+			break collect
+		}
+	}
+
+	if len(valid) == 1 {
+		return first
+	}
+	sort.SliceStable(valid, func(i, j int) bool { return valid[i].Priority < valid[j].Priority })
+
+	merged, err := mergeDetailPayloads(valid, s.detailStrategies)
+	if err != nil {
+		logger.Warnf("Failed to merge detail responses, using %s: %v", first.SourceName, err)
+		return first
+	}
+
+	best := valid[0]
+	resp := &domain.APIResponse{
+		Data:       merged,
+		StatusCode: best.StatusCode,
+		SourceName: best.SourceName,
+		IsFallback: best.IsFallback,
+		Priority:   best.Priority,
+	}
+	for _, r := range valid {
+		if r.ResponseTime > resp.ResponseTime {
+			resp.ResponseTime = r.ResponseTime
+		}
+	}
+	logger.Infof("Merged detail responses from %d sources", len(valid))
+	return resp
+}
+
+// mergeDetailPayloads merges responses ordered by priority. The detail object
+// is the "data" object of wrapped responses and the whole response otherwise;
+// the highest-priority response provides everything around it.
+func mergeDetailPayloads(responses []*domain.APIResponse, strategies merge.Strategies) ([]byte, error) {
+	var envelope map[string]interface{}
+	wrapped := false
+	candidates := make([]merge.Candidate, 0, len(responses))
+	for i, resp := range responses {
+		var payload map[string]interface{}
+		if err := json.Unmarshal(resp.Data, &payload); err != nil {
+			return nil, err
+		}
+
+		detail := payload
+		if data, ok := payload["data"].(map[string]interface{}); ok {
+			detail = data
+			if i == 0 {
+				wrapped = true
+			}
+		}
+		if i == 0 {
+			envelope = payload
+		}
+		delete(detail, fieldSourcesKey)
+		candidates = append(candidates, merge.Candidate{Source: resp.SourceName, Priority: i, Data: detail})
+	}
+
+	result := merge.Merge(candidates, strategies)
+	result.Data[fieldSourcesKey] = result.Sources
+	if wrapped {
+		envelope["data"] = result.Data
+	} else {
+		envelope = result.Data
+	}
+
+	return json.Marshal(envelope)
+}
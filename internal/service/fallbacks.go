@@ -0,0 +1,117 @@
+package service
+
+import (
+	"apicategorywithfallback/pkg/database"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+var (
+	// ErrAPISourceNotFound is returned for unknown API source IDs
+	ErrAPISourceNotFound = errors.New("API source not found")
+	// ErrFallbackNotFound is returned for fallback IDs that do not exist or belong to another source
+	ErrFallbackNotFound = errors.New("fallback API not found")
+)
+
+// GetFallbackChain returns all fallbacks of an API source, including disabled ones, in priority order
+func (s *APIService) GetFallbackChain(apiSourceID int) ([]database.FallbackAPI, error) {
+	if err := s.ensureAPISource(apiSourceID); err != nil {
+		return nil, err
+	}
+	return s.db.GetFallbackChain(apiSourceID)
+}
+
+// CreateFallback adds a fallback base URL to an API source. A priority of 0 appends it to the chain.
+func (s *APIService) CreateFallback(apiSourceID int, fallbackURL string, priority int, isActive bool) (*database.FallbackAPI, error) {
+	if err := s.ensureAPISource(apiSourceID); err != nil {
+		return nil, err
+	}
+
+	fallbackURL, err := normalizeFallbackURL(fallbackURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.db.CreateFallbackAPI(apiSourceID, fallbackURL, priority, isActive)
+}
+
+// UpdateFallback changes a fallback's URL, priority and status
+func (s *APIService) UpdateFallback(apiSourceID, fallbackID int, fallbackURL string, priority int, isActive bool) error {
+	if _, err := s.getSourceFallback(apiSourceID, fallbackID); err != nil {
+		return err
+	}
+
+	fallbackURL, err := normalizeFallbackURL(fallbackURL)
+	if err != nil {
+		return err
+	}
+	if priority <= 0 {
+		return fmt.Errorf("priority must be at least 1")
+	}
+
+	return s.db.UpdateFallbackAPI(fallbackID, fallbackURL, priority, isActive)
+}
+
+// SetFallbackActive enables or disables a fallback without changing its position
+func (s *APIService) SetFallbackActive(apiSourceID, fallbackID int, isActive bool) error {
+	fallback, err := s.getSourceFallback(apiSourceID, fallbackID)
+	if err != nil {
+		return err
+	}
+
+	return s.db.UpdateFallbackAPI(fallbackID, fallback.FallbackURL, fallback.Priority, isActive)
+}
+
+// DeleteFallback removes a fallback from an API source
+func (s *APIService) DeleteFallback(apiSourceID, fallbackID int) error {
+	if _, err := s.getSourceFallback(apiSourceID, fallbackID); err != nil {
+		return err
+	}
+	return s.db.DeleteFallbackAPI(fallbackID)
+}
+
+// ReorderFallbacks sets the fallback order of an API source; orderedIDs must list all its fallbacks
+func (s *APIService) ReorderFallbacks(apiSourceID int, orderedIDs []int) ([]database.FallbackAPI, error) {
+	if err := s.ensureAPISource(apiSourceID); err != nil {
+		return nil, err
+	}
+	if err := s.db.ReorderFallbackAPIs(apiSourceID, orderedIDs); err != nil {
+		return nil, err
+	}
+	return s.db.GetFallbackChain(apiSourceID)
+}
+
+func (s *APIService) ensureAPISource(apiSourceID int) error {
+	source, err := s.db.GetAPISource(apiSourceID)
+	if err != nil {
+		return err
+	}
+	if source == nil {
+		return ErrAPISourceNotFound
+	}
+	return nil
+}
+
+// getSourceFallback returns a fallback, checking that it belongs to the given source
+func (s *APIService) getSourceFallback(apiSourceID, fallbackID int) (*database.FallbackAPI, error) {
+	fallback, err := s.db.GetFallbackAPI(fallbackID)
+	if err != nil {
+		return nil, err
+	}
+	if fallback == nil || fallback.APISourceID != apiSourceID {
+		return nil, ErrFallbackNotFound
+	}
+	return fallback, nil
+}
+
+// normalizeFallbackURL checks that a fallback is an absolute http(s) base URL
+func normalizeFallbackURL(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	parsed, err := url.Parse(raw)
+	if err != nil || parsed.Host == "" || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return "", fmt.Errorf("fallback URL must be an absolute http(s) URL, got %q", raw)
+	}
+	return strings.TrimRight(raw, "/"), nil
+}
@@ -0,0 +1,229 @@
+package service
+
+import (
+	"apicategorywithfallback/pkg/database"
+	"apicategorywithfallback/pkg/logger"
+	"apicategorywithfallback/pkg/routing"
+	"apicategorywithfallback/pkg/validator"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// ErrHealthProbeNotFound is returned when a health probe does not exist
+var ErrHealthProbeNotFound = errors.New("health probe not found")
+
+// ErrInvalidHealthProbe is returned when a health probe fails validation
+var ErrInvalidHealthProbe = errors.New("invalid health probe")
+
+// healthProbeTimeout bounds one probe request, including reading the body
+const healthProbeTimeout = 10 * time.Second
+
+// healthSkipped is the status recorded for an endpoint that cannot be probed
+// as configured. It says nothing about the source, so routing, uptime and
+// notifications ignore it.
+const healthSkipped = "SKIPPED"
+
+// errHealthProbeMissing is returned for detail endpoints without an active probe
+var errHealthProbeMissing = errors.New("no active health probe with the parameters of a real title")
+
+// healthProbeEntry is an active probe with its parsed expect_schema (nil when empty)
+type healthProbeEntry struct {
+	params map[string]string
+	schema *validator.Schema
+}
+
+// probeResult is the outcome of probing one endpoint of a source
+type probeResult struct {
+	status       string // OK, DEGRADED, TIMEOUT or ERROR
+	responseTime int    // milliseconds
+	message      string
+}
+
+func healthProbeKey(endpoint string) string {
+	if endpoint == "/" {
+		return endpoint
+	}
+	return strings.TrimRight(endpoint, "/")
+}
+
+// probeSource calls a source's endpoint the way live traffic does: the probe's
+// parameters go through the source's request mapping, the response through its
+// transform and normalization, then the endpoint schema and confidence policy
+// and the probe's own schema. A source that answers with data failing
+// validation is DEGRADED; one that does not answer successfully is ERROR or
+// TIMEOUT. An error means the endpoint cannot be probed as configured:
+// detail endpoints need an active probe, since any other title is unlikely to
+// resolve.
+func (s *APIService) probeSource(source database.APISource, endpoint string) (probeResult, error) {
+	probe, exists := s.healthProbe(endpoint)
+	if !exists && isDetailEndpoint(endpoint) {
+		return probeResult{}, errHealthProbeMissing
+	}
+	path, params, err := routing.Expand(endpoint, probe.params)
+	if err != nil {
+		return probeResult{}, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), healthProbeTimeout)
+	defer cancel()
+
+	opts := s.upstreamOptions(source, endpoint)
+	url := s.buildURL(source.BaseURL, path, params, opts.mapping)
+	resp := s.makeAPIRequest(ctx, url, source.SourceName, false, opts)
+	result := probeResult{responseTime: int(resp.ResponseTime.Milliseconds())}
+
+	if resp.Error != nil {
+		result.message = resp.Error.Error()
+		var netErr net.Error
+		switch {
+		case errors.Is(resp.Error, context.DeadlineExceeded) || (errors.As(resp.Error, &netErr) && netErr.Timeout()):
+			result.status = "TIMEOUT"
+		case resp.StatusCode >= 200 && resp.StatusCode < 300:
+			// The source answered, but with a body that cannot be used
+			result.status = "DEGRADED"
+		default:
+			result.status = "ERROR"
+		}
+		return result, nil
+	}
+
+	if err := s.validateResponse(ctx, path, resp.Data, opts.confidence); err != nil {
+		result.status = "DEGRADED"
+		result.message = err.Error()
+		return result, nil
+	}
+	if probe.schema != nil {
+		if err := probe.schema.Validate(resp.Data); err != nil {
+			result.status = "DEGRADED"
+			result.message = fmt.Sprintf("probe schema: %v", err)
+			return result, nil
+		}
+	}
+
+	result.status = "OK"
+	return result, nil
+}
+
+// healthProbe returns the active probe of an endpoint; exists is false when it has none
+func (s *APIService) healthProbe(endpoint string) (probe healthProbeEntry, exists bool) {
+	entries, err := s.healthProbes()
+	if err != nil {
+		logger.Warnf("Failed to load health probes: %v", err)
+	}
+	probe, exists = entries[healthProbeKey(endpoint)]
+	return probe, exists
+}
+
+// healthProbes returns the cached active probes by endpoint path
+func (s *APIService) healthProbes() (map[string]healthProbeEntry, error) {
+	return s.probes.Get(func() (map[string]healthProbeEntry, error) {
+		probes, err := s.db.GetHealthProbes()
+		if err != nil {
+			return nil, err
+		}
+
+		entries := make(map[string]healthProbeEntry)
+		for _, probe := range probes {
+			if !probe.IsActive {
+				continue
+			}
+			entry := healthProbeEntry{params: probe.Params}
+			if probe.ExpectSchema != "" {
+				schema, err := validator.ParseSchema([]byte(probe.ExpectSchema))
+				if err != nil {
+					logger.Warnf("Ignoring expect_schema of health probe for %s: %v", probe.EndpointPath, err)
+				} else {
+					entry.schema = schema
+				}
+			}
+			entries[healthProbeKey(probe.EndpointPath)] = entry
+		}
+		return entries, nil
+	})
+}
+
+// GetHealthProbes returns all health probes
+func (s *APIService) GetHealthProbes() ([]database.HealthProbe, error) {
+	return s.db.GetHealthProbes()
+}
+
+// CreateHealthProbe validates and stores a new health probe
+func (s *APIService) CreateHealthProbe(probe *database.HealthProbe) error {
+	if err := s.validateHealthProbe(probe); err != nil {
+		return err
+	}
+
+	defer s.probes.Invalidate()
+	return s.db.CreateHealthProbe(probe)
+}
+
+// UpdateHealthProbe validates and replaces a health probe
+func (s *APIService) UpdateHealthProbe(probe *database.HealthProbe) error {
+	existing, err := s.db.GetHealthProbe(probe.ID)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return ErrHealthProbeNotFound
+	}
+	if err := s.validateHealthProbe(probe); err != nil {
+		return err
+	}
+
+	defer s.probes.Invalidate()
+	return s.db.UpdateHealthProbe(probe)
+}
+
+// DeleteHealthProbe deletes a health probe; its endpoint is then probed without
+// parameters, or skipped if it is a detail endpoint
+func (s *APIService) DeleteHealthProbe(id int) error {
+	existing, err := s.db.GetHealthProbe(id)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return ErrHealthProbeNotFound
+	}
+
+	defer s.probes.Invalidate()
+	return s.db.DeleteHealthProbe(id)
+}
+
+// validateHealthProbe trims and checks a probe before it is stored: the params
+// must fill every path parameter of the endpoint, the schema must parse, and
+// each endpoint may have only one probe
+func (s *APIService) validateHealthProbe(probe *database.HealthProbe) error {
+	probe.EndpointPath = healthProbeKey(strings.TrimSpace(probe.EndpointPath))
+	probe.ExpectSchema = strings.TrimSpace(probe.ExpectSchema)
+	if probe.Params == nil {
+		probe.Params = map[string]string{}
+	}
+
+	if err := validateEndpointPath(probe.EndpointPath); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidHealthProbe, err)
+	}
+	if _, _, err := routing.Expand(probe.EndpointPath, probe.Params); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidHealthProbe, err)
+	}
+	if probe.ExpectSchema != "" {
+		if _, err := validator.ParseSchema([]byte(probe.ExpectSchema)); err != nil {
+			return fmt.Errorf("%w: expect_schema: %v", ErrInvalidHealthProbe, err)
+		}
+	}
+
+	existing, err := s.db.GetHealthProbes()
+	if err != nil {
+		return err
+	}
+	for _, other := range existing {
+		if other.ID != probe.ID && healthProbeKey(other.EndpointPath) == probe.EndpointPath {
+			return fmt.Errorf("%w: %s already has a probe", ErrInvalidHealthProbe, probe.EndpointPath)
+		}
+	}
+
+	return nil
+}
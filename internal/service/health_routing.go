@@ -0,0 +1,90 @@
+package service
+
+import (
+	"apicategorywithfallback/pkg/database"
+	"apicategorywithfallback/pkg/logger"
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidRoutingOverride is returned for an unknown routing override
+var ErrInvalidRoutingOverride = errors.New("invalid routing override")
+
+// Routing states reported on the dashboard health page
+const (
+	routingPreferred = "preferred" // Tried first
+	routingDemoted   = "demoted"   // Failing health checks, tried when preferred sources fail
+	routingDisabled  = "disabled"  // Forced off from the dashboard
+)
+
+// partitionByHealth splits sources into those routed normally and those that
+// failed their latest health check, which are only tried when the others fail.
+// Force-disabled sources are left out; force-enabled ones are always preferred.
+func (s *APIService) partitionByHealth(sources []database.APISource) (preferred, demoted []database.APISource) {
+	entries, err := s.sourceRouting()
+	if err != nil {
+		logger.Warnf("Failed to load source health for routing: %v", err)
+	}
+
+	for _, source := range sources {
+		switch s.routingState(entries[source.ID]) {
+		case routingDisabled:
+			logger.Infof("Skipping source %s (ID: %d): routing disabled", source.SourceName, source.ID)
+		case routingDemoted:
+			logger.Infof("Demoting source %s (ID: %d): failing health checks", source.SourceName, source.ID)
+			demoted = append(demoted, source)
+		default:
+			preferred = append(preferred, source)
+		}
+	}
+	return preferred, demoted
+}
+
+// sourceRouting returns the cached routing override and latest health by API source ID
+func (s *APIService) sourceRouting() (map[int]database.SourceRouting, error) {
+	return s.routing.Get(func() (map[int]database.SourceRouting, error) {
+		return s.db.GetSourceRouting(s.config.HealthRoutingMaxAge)
+	})
+}
+
+// routingState describes how requests are routed to an API source
+func (s *APIService) routingState(entry database.SourceRouting) string {
+	switch {
+	case entry.Override == database.RoutingForceDisable:
+		return routingDisabled
+	case entry.Override == database.RoutingForceEnable, !s.config.HealthRoutingEnabled:
+		return routingPreferred
+	case entry.HealthStatus != "" && entry.HealthStatus != "OK" && entry.HealthStatus != healthSkipped:
+		return routingDemoted
+	default:
+		return routingPreferred
+	}
+}
+
+// GetRoutingOverride returns the routing override of an API source
+func (s *APIService) GetRoutingOverride(apiSourceID int) (string, error) {
+	override, found, err := s.db.GetRoutingOverride(apiSourceID)
+	if err != nil {
+		return "", err
+	}
+	if !found {
+		return "", ErrAPISourceNotFound
+	}
+	return override, nil
+}
+
+// SetRoutingOverride stores the routing override of an API source
+func (s *APIService) SetRoutingOverride(apiSourceID int, override string) error {
+	switch override {
+	case database.RoutingAuto, database.RoutingForceEnable, database.RoutingForceDisable:
+	default:
+		return fmt.Errorf("%w: %q (use %s, %s or %s)", ErrInvalidRoutingOverride, override,
+			database.RoutingAuto, database.RoutingForceEnable, database.RoutingForceDisable)
+	}
+	if err := s.ensureAPISource(apiSourceID); err != nil {
+		return err
+	}
+
+	defer s.routing.Invalidate()
+	return s.db.SetRoutingOverride(apiSourceID, override)
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"apicategorywithfallback/internal/domain"
+	"apicategorywithfallback/pkg/config"
+	"apicategorywithfallback/pkg/database"
+	"apicategorywithfallback/pkg/logger"
+	"context"
+	"strings"
+	"time"
+)
+
+// hedgePolicy returns the hedge policy configured for an endpoint path
+func (s *APIService) hedgePolicy(endpoint string) (config.HedgePolicy, bool) {
+	policy, exists := s.config.HedgePolicies[strings.TrimSuffix(endpoint, "/")]
+	return policy, exists
+}
+
+// sourceOutcome is the result of a primary source and its fallbacks; resp is nil when all failed
+type sourceOutcome struct {
+	source string
+	resp   *domain.APIResponse
+}
+
+// tryHedged fans out to all primary sources like tryAllPrimaryAPIsWithFallback,
+// but returns once policy.MinSources sources returned valid data, or once
+// policy.Budget has passed and at least one has. Sources still running are
+// listed as late and keep the request's deadline but not its cancellation;
+// when they add data, refresh receives the response combining every source.
+func (s *APIService) tryHedged(ctx context.Context, sources []database.APISource, reqCtx *domain.RequestContext, policy config.HedgePolicy, refresh func(*domain.APIResponse)) *domain.FallbackResult {
+	fanoutCtx, cancel := detachedContext(ctx)
+	outcomes := make(chan sourceOutcome, len(sources))
+	for _, source := range sources {
+		go func(src database.APISource) {
+			results := make(chan *domain.APIResponse, 1)
+			s.trySourceWithFallback(fanoutCtx, src, reqCtx, results)
+			close(results)
+			outcomes <- sourceOutcome{source: src.SourceName, resp: <-results}
+		}(source)
+	}
+
+	var budget <-chan time.Time
+	if policy.Budget > 0 {
+		timer := time.NewTimer(policy.Budget)
+		defer timer.Stop()
+		budget = timer.C
+	}
+
+	var valid []sourceOutcome
+	finished := make(map[string]int)
+	remaining := len(sources)
+	budgetSpent := false
+
+collect:
+	for remaining > 0 {
+		select {
+		case outcome := <-outcomes:
+			remaining--
+			finished[outcome.source]++
+			if outcome.resp == nil {
+				continue
+			}
+			valid = append(valid, outcome)
+			if budgetSpent || (policy.MinSources > 0 && len(valid) >= policy.MinSources) {
+				break collect
+			}
+		case <-budget:
+			budgetSpent = true
+			if len(valid) > 0 {
+				break collect
+			}
+		case <-ctx.Done():
+			break collect
+		}
+	}
+
+	var late []string
+	for _, source := range sources {
+		if finished[source.SourceName] > 0 {
+			finished[source.SourceName]--
+			continue
+		}
+		late = append(late, source.SourceName)
+	}
+
+	if remaining == 0 {
+		cancel()
+	} else {
+		logger.Infof("Hedged %s after %d of %d sources, late: %v", reqCtx.Endpoint, len(sources)-remaining, len(sources), late)
+		go s.finishHedged(cancel, outcomes, remaining, append([]sourceOutcome(nil), valid...), sources, reqCtx, refresh)
+	}
+
+	if len(valid) == 0 {
+		logger.Warnf("All primary and fallback APIs failed for %s", reqCtx.Endpoint)
+		return &domain.FallbackResult{Success: false}
+	}
+
+	// Included sources are primary source names, like the late ones
+	successfulResponses, included := s.rankOutcomes(valid, sources)
+	result := s.combineResponses(successfulResponses, reqCtx.Endpoint)
+	result.Response.IncludedSources = included
+	result.Response.LateSources = late
+	return result
+}
+
+// finishHedged waits for the late sources of a hedged fan-out and, when any
+// of them returned valid data, passes the combined response to refresh
+func (s *APIService) finishHedged(cancel context.CancelFunc, outcomes <-chan sourceOutcome, remaining int, valid []sourceOutcome, sources []database.APISource, reqCtx *domain.RequestContext, refresh func(*domain.APIResponse)) {
+	defer cancel()
+
+	added := false
+	for ; remaining > 0; remaining-- {
+		outcome := <-outcomes
+		if outcome.resp != nil {
+			valid = append(valid, outcome)
+			added = true
+		}
+	}
+
+	if !added || refresh == nil {
+		return
+	}
+
+	successfulResponses, _ := s.rankOutcomes(valid, sources)
+	result := s.combineResponses(successfulResponses, reqCtx.Endpoint)
+	if result.Response == nil || result.Response.Data == nil {
+		return
+	}
+	refresh(result.Response)
+	logger.Infof("Refreshed %s with %d sources after hedging", reqCtx.Endpoint, len(successfulResponses))
+}
+
+// detachedContext keeps ctx's values and deadline but not its cancellation
+func detachedContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if deadline, exists := ctx.Deadline(); exists {
+		return context.WithDeadline(context.WithoutCancel(ctx), deadline)
+	}
+	return context.WithCancel(context.WithoutCancel(ctx))
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"apicategorywithfallback/pkg/config"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// homeUpstream answers /api/v1/home as source once release is closed (nil answers at once)
+func homeUpstream(source string, release <-chan struct{}) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if release != nil {
+			select {
+			case <-release:
+			case <-r.Context().Done():
+				return
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(homeBody(source))
+	}))
+}
+
+func sortedNames(names []string) string {
+	sorted := append([]string(nil), names...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, ",")
+}
+
+func TestHedgeReturnsAfterMinSourcesAndRefreshesWithLateOnes(t *testing.T) {
+	release := make(chan struct{})
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer failing.Close()
+	alphaFallback := homeUpstream("alpha", nil)
+	defer alphaFallback.Close()
+	beta := homeUpstream("beta", nil)
+	defer beta.Close()
+	gamma := homeUpstream("gamma", release)
+	defer gamma.Close()
+	defer close(release)
+
+	service := newUpstreamService(t, &config.Config{
+		HedgePolicies: map[string]config.HedgePolicy{"/api/v1/home": {MinSources: 2, Budget: 5 * time.Second}},
+	}, map[string]string{"alpha": failing.URL, "beta": beta.URL, "gamma": gamma.URL})
+
+	// alpha only answers through its fallback
+	sources, err := service.db.GetAPISourcesByEndpoint("/api/v1/home", "anime")
+	if err != nil {
+		t.Fatalf("Failed to get API sources: %v", err)
+	}
+	for _, source := range sources {
+		if source.SourceName == "alpha" {
+			if _, err := service.db.CreateFallbackAPI(source.ID, alphaFallback.URL, 1, true); err != nil {
+				t.Fatalf("Failed to create fallback: %v", err)
+			}
+		}
+	}
+
+	started := time.Now()
+	response, err := service.ProcessRequest(context.Background(), homeRequest())
+	if err != nil {
+		t.Fatalf("ProcessRequest failed: %v", err)
+	}
+	if elapsed := time.Since(started); elapsed > 2*time.Second {
+		t.Errorf("Expected the hedge to return once 2 sources answered, took %s", elapsed)
+	}
+
+	// Both lists name primary sources, even when a fallback answered
+	if got := sortedNames(response.IncludedSources); got != "alpha,beta" {
+		t.Errorf("Expected included sources alpha,beta, got %s", got)
+	}
+	if got := sortedNames(response.LateSources); got != "gamma" {
+		t.Errorf("Expected late source gamma, got %s", got)
+	}
+	if strings.Contains(string(response.Data), `"gamma"`) {
+		t.Error("Expected the hedged response not to include the late source")
+	}
+
+	release <- struct{}{}
+	cacheKey := service.cache.GenerateKey("anime", "/api/v1/home", map[string]string{})
+	refreshed := waitFor(t, 5*time.Second, func() bool {
+		entry, _ := service.cache.GetEntry(cacheKey)
+		return entry != nil && strings.Contains(string(entry.Value), `"gamma"`) && strings.Contains(string(entry.Value), `"beta"`)
+	})
+	if !refreshed {
+		t.Error("Expected the cache to be refreshed with the late source's data")
+	}
+}
+
+func TestHedgeReturnsAtBudgetWithOneValidSource(t *testing.T) {
+	release := make(chan struct{})
+	alpha := homeUpstream("alpha", nil)
+	defer alpha.Close()
+	beta := homeUpstream("beta", release)
+	defer beta.Close()
+	gamma := homeUpstream("gamma", release)
+	defer gamma.Close()
+	defer close(release)
+
+	const budget = 200 * time.Millisecond
+	service := newUpstreamService(t, &config.Config{
+		HedgePolicies: map[string]config.HedgePolicy{"/api/v1/home": {MinSources: 3, Budget: budget}},
+	}, map[string]string{"alpha": alpha.URL, "beta": beta.URL, "gamma": gamma.URL})
+
+	started := time.Now()
+	response, err := service.ProcessRequest(context.Background(), homeRequest())
+	if err != nil {
+		t.Fatalf("ProcessRequest failed: %v", err)
+	}
+	elapsed := time.Since(started)
+	if elapsed < budget || elapsed > budget+2*time.Second {
+		t.Errorf("Expected the hedge to return at its %s budget, took %s", budget, elapsed)
+	}
+
+	if got := sortedNames(response.IncludedSources); got != "alpha" {
+		t.Errorf("Expected included source alpha, got %s", got)
+	}
+	if got := sortedNames(response.LateSources); got != "beta,gamma" {
+		t.Errorf("Expected late sources beta,gamma, got %s", got)
+	}
+}
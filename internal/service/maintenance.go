@@ -0,0 +1,98 @@
+package service
+
+import (
+	"apicategorywithfallback/pkg/database"
+	"apicategorywithfallback/pkg/logger"
+	"apicategorywithfallback/pkg/sla"
+	"time"
+)
+
+// MaintenanceResult reports what a maintenance run pruned
+type MaintenanceResult struct {
+	RequestLogsDeleted    int64 `json:"request_logs_deleted"`
+	HealthChecksDeleted   int64 `json:"health_checks_deleted"`
+	SourceOutcomesDeleted int64 `json:"source_outcomes_deleted"`
+	HourlyRollupsDeleted  int64 `json:"hourly_rollups_deleted"`
+	DailyRollupsDeleted   int64 `json:"daily_rollups_deleted"`
+	DurationMs            int64 `json:"duration_ms"`
+}
+
+// StartMaintenance rolls up the request logs and prunes old rows right away
+// and then every MaintenanceInterval; a zero interval disables it
+func (s *APIService) StartMaintenance() {
+	if s.config.MaintenanceInterval <= 0 {
+		logger.Info("Database maintenance disabled")
+		return
+	}
+
+	s.warnShortRetention()
+
+	ticker := time.NewTicker(s.config.MaintenanceInterval)
+	defer ticker.Stop()
+
+	logger.Info("Starting database maintenance")
+
+	for {
+		if _, err := s.RunMaintenance(); err != nil {
+			logger.Errorf("Database maintenance failed: %v", err)
+		}
+		<-ticker.C
+	}
+}
+
+// warnShortRetention warns about retentions too short for the reports reading the data
+func (s *APIService) warnShortRetention() {
+	longestWindow := sla.Windows[len(sla.Windows)-1]
+	if retention := s.config.HealthCheckRetention; retention > 0 && retention < longestWindow.Length+s.slaCheckMaxAge() {
+		logger.Warnf("HEALTH_CHECK_RETENTION (%s) is shorter than the %s uptime report window", retention, longestWindow.Name)
+	}
+	if retention := s.config.HourlyRollupRetention; retention > 0 && retention < 7*24*time.Hour {
+		logger.Warnf("HOURLY_ROLLUP_RETENTION (%s) is shorter than the 7d traffic report window", retention)
+	}
+}
+
+// RunMaintenance refreshes the request rollups, then deletes the rows older
+// than their retention. Request logs and hourly rollups are only deleted once
+// rolled up, so no traffic goes missing from the reports.
+func (s *APIService) RunMaintenance() (*MaintenanceResult, error) {
+	s.maintenance.Lock()
+	defer s.maintenance.Unlock()
+
+	started := time.Now()
+	if err := s.db.RollUpRequestLogs(); err != nil {
+		return nil, err
+	}
+
+	result := &MaintenanceResult{}
+	prunes := []struct {
+		retention time.Duration
+		deleted   *int64
+		delete    func(before time.Time) (int64, error)
+	}{
+		{s.config.RequestLogRetention, &result.RequestLogsDeleted, s.db.DeleteRequestLogsBefore},
+		{s.config.HealthCheckRetention, &result.HealthChecksDeleted, s.db.DeleteHealthChecksBefore},
+		{s.config.HealthCheckRetention, &result.SourceOutcomesDeleted, s.db.DeleteSourceOutcomesBefore},
+		{s.config.HourlyRollupRetention, &result.HourlyRollupsDeleted, func(before time.Time) (int64, error) {
+			return s.db.DeleteRequestRollupsBefore(database.RollupHourly, before)
+		}},
+		{s.config.DailyRollupRetention, &result.DailyRollupsDeleted, func(before time.Time) (int64, error) {
+			return s.db.DeleteRequestRollupsBefore(database.RollupDaily, before)
+		}},
+	}
+	for _, prune := range prunes {
+		if prune.retention <= 0 {
+			continue
+		}
+		deleted, err := prune.delete(started.Add(-prune.retention))
+		*prune.deleted += deleted
+		if err != nil {
+			return result, err
+		}
+	}
+
+	result.DurationMs = time.Since(started).Milliseconds()
+	logger.Infof("Database maintenance done in %dms: deleted %d request logs, %d health checks, %d source outcomes, %d hourly and %d daily rollups",
+		result.DurationMs, result.RequestLogsDeleted, result.HealthChecksDeleted, result.SourceOutcomesDeleted,
+		result.HourlyRollupsDeleted, result.DailyRollupsDeleted)
+	return result, nil
+}
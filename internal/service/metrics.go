@@ -0,0 +1,81 @@
+package service
+
+import (
+	"apicategorywithfallback/internal/domain"
+	"apicategorywithfallback/pkg/logger"
+	"apicategorywithfallback/pkg/metrics"
+)
+
+// MetricsToken returns the bearer token required to scrape /metrics (empty = open)
+func (s *APIService) MetricsToken() string {
+	return s.config.MetricsToken
+}
+
+// metricCategory returns the category label of a request: its category once
+// it names a configured one (or "all"), metrics.CategoryUnknown otherwise, so
+// that clients cannot create series with arbitrary category values
+func (s *APIService) metricCategory(reqCtx *domain.RequestContext) string {
+	if reqCtx.Category == "all" {
+		return reqCtx.Category
+	}
+	tables, err := s.routeTables()
+	if err != nil {
+		logger.Warnf("Failed to load categories for metrics: %v", err)
+	}
+	if _, exists := tables[reqCtx.Category]; exists {
+		return reqCtx.Category
+	}
+	return metrics.CategoryUnknown
+}
+
+// observeUpstream records the outcome of one upstream attempt. source is the
+// primary source name; fallbacks are told apart by the role label.
+func (s *APIService) observeUpstream(reqCtx *domain.RequestContext, source string, resp *domain.APIResponse, validationErr error) {
+	role := metrics.RolePrimary
+	if resp.IsFallback {
+		role = metrics.RoleFallback
+	}
+
+	outcome := metrics.OutcomeSuccess
+	switch {
+	case validationErr != nil:
+		outcome = metrics.OutcomeInvalid
+	case isCancellation(resp.Error):
+		outcome = metrics.OutcomeCancelled
+	case resp.Error != nil || resp.Data == nil:
+		outcome = metrics.OutcomeError
+	}
+
+	metrics.ObserveUpstream(reqCtx.RoutePath(), s.metricCategory(reqCtx), source, role, outcome, resp.ResponseTime)
+	s.observeAdaptive(reqCtx, source, role, outcome, resp.ResponseTime)
+	s.observeOutcome(reqCtx, source, role, outcome)
+}
+
+// observeResult records whether a primary source, a fallback or nothing served a fetch
+func (s *APIService) observeResult(reqCtx *domain.RequestContext, result *domain.FallbackResult) {
+	category := s.metricCategory(reqCtx)
+	switch {
+	case !result.Success:
+		metrics.ObserveResult(reqCtx.RoutePath(), category, metrics.ResultFailed)
+	case result.FallbackUsed:
+		metrics.ObserveResult(reqCtx.RoutePath(), category, metrics.ResultFallback)
+	default:
+		metrics.ObserveResult(reqCtx.RoutePath(), category, metrics.ResultPrimary)
+	}
+}
+
+// observeBruteforceWin records the source whose response won a bruteforce race
+func (s *APIService) observeBruteforceWin(reqCtx *domain.RequestContext, sources []bruteforceSource, winner *domain.APIResponse) {
+	for _, src := range sources {
+		if src.SourceName != winner.SourceName {
+			continue
+		}
+
+		role := metrics.RolePrimary
+		if src.IsFallback {
+			role = metrics.RoleFallback
+		}
+		metrics.ObserveBruteforceWin(reqCtx.RoutePath(), s.metricCategory(reqCtx), src.PrimaryName, role)
+		return
+	}
+}
@@ -0,0 +1,234 @@
+package service
+
+import (
+	"apicategorywithfallback/pkg/database"
+	"apicategorywithfallback/pkg/logger"
+	"apicategorywithfallback/pkg/notifier"
+	"context"
+	"errors"
+	"fmt"
+	neturl "net/url"
+	"strings"
+	"time"
+)
+
+// ErrNotificationChannelNotFound is returned when a notification channel does not exist
+var ErrNotificationChannelNotFound = errors.New("notification channel not found")
+
+// ErrInvalidNotificationChannel is returned when a notification channel fails validation
+var ErrInvalidNotificationChannel = errors.New("invalid notification channel")
+
+// ErrNotificationFailed is returned when a test notification could not be delivered
+var ErrNotificationFailed = errors.New("notification failed")
+
+// notificationTimeout bounds a single delivery to a channel
+const notificationTimeout = 10 * time.Second
+
+// notify sends an event to the matching active channels in the background
+func (s *APIService) notify(event notifier.Event) {
+	channels, err := s.notificationChannels()
+	if err != nil {
+		logger.Warnf("Failed to load notification channels: %v", err)
+	}
+	if len(channels) == 0 {
+		return
+	}
+
+	if event.At.IsZero() {
+		event.At = time.Now()
+	}
+	s.notifier.Notify(event, channels, func(channel notifier.Channel, err error) {
+		logger.Warnf("Failed to send %s notification to channel %s: %v", event.Type, channel.Name, err)
+	})
+}
+
+// notificationChannels returns the cached active channels
+func (s *APIService) notificationChannels() ([]notifier.Channel, error) {
+	return s.channels.Get(func() ([]notifier.Channel, error) {
+		stored, err := s.db.GetNotificationChannels()
+		if err != nil {
+			return nil, err
+		}
+
+		channels := []notifier.Channel{}
+		for _, channel := range stored {
+			if channel.IsActive {
+				channels = append(channels, notifierChannel(channel))
+			}
+		}
+		return channels, nil
+	})
+}
+
+func notifierChannel(channel database.NotificationChannel) notifier.Channel {
+	return notifier.Channel{
+		ID:         channel.ID,
+		Name:       channel.Name,
+		Kind:       channel.Kind,
+		URL:        channel.URL,
+		ChatID:     channel.ChatID,
+		EventTypes: channel.EventTypes,
+		Sources:    channel.Sources,
+		Endpoints:  channel.Endpoints,
+		Debounce:   time.Duration(channel.DebounceSeconds) * time.Second,
+	}
+}
+
+// notifyHealthChange alerts on a change of a source's health check status;
+// the first check of a source is not a change
+func (s *APIService) notifyHealthChange(source database.APISource, endpoint, previous, status, message string) {
+	if previous == "" || previous == status || previous == healthSkipped || status == healthSkipped {
+		return
+	}
+	s.notify(notifier.Event{
+		Type:     notifier.EventHealthChanged,
+		Source:   source.SourceName,
+		Endpoint: endpoint,
+		From:     previous,
+		To:       status,
+		Message:  message,
+	})
+}
+
+// GetNotificationChannels returns all notification channels
+func (s *APIService) GetNotificationChannels() ([]database.NotificationChannel, error) {
+	return s.db.GetNotificationChannels()
+}
+
+// CreateNotificationChannel validates and stores a new notification channel
+func (s *APIService) CreateNotificationChannel(channel *database.NotificationChannel) error {
+	if err := s.validateNotificationChannel(channel); err != nil {
+		return err
+	}
+
+	defer s.channels.Invalidate()
+	return s.db.CreateNotificationChannel(channel)
+}
+
+// UpdateNotificationChannel validates and replaces a notification channel
+func (s *APIService) UpdateNotificationChannel(channel *database.NotificationChannel) error {
+	existing, err := s.db.GetNotificationChannel(channel.ID)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return ErrNotificationChannelNotFound
+	}
+	if err := s.validateNotificationChannel(channel); err != nil {
+		return err
+	}
+
+	defer s.channels.Invalidate()
+	return s.db.UpdateNotificationChannel(channel)
+}
+
+// DeleteNotificationChannel deletes a notification channel
+func (s *APIService) DeleteNotificationChannel(id int) error {
+	existing, err := s.db.GetNotificationChannel(id)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return ErrNotificationChannelNotFound
+	}
+
+	defer s.channels.Invalidate()
+	return s.db.DeleteNotificationChannel(id)
+}
+
+// TestNotificationChannel sends a test event to a channel, active or not, and
+// reports whether it was delivered
+func (s *APIService) TestNotificationChannel(id int) error {
+	channel, err := s.db.GetNotificationChannel(id)
+	if err != nil {
+		return err
+	}
+	if channel == nil {
+		return ErrNotificationChannelNotFound
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), notificationTimeout)
+	defer cancel()
+
+	if err := s.notifier.Send(ctx, notifierChannel(*channel), notifier.Event{Type: notifier.EventTest, At: time.Now()}); err != nil {
+		return fmt.Errorf("%w: %v", ErrNotificationFailed, err)
+	}
+	return nil
+}
+
+// validateNotificationChannel trims and checks a channel before it is stored;
+// channel names are unique
+func (s *APIService) validateNotificationChannel(channel *database.NotificationChannel) error {
+	channel.Name = strings.TrimSpace(channel.Name)
+	channel.Kind = strings.ToLower(strings.TrimSpace(channel.Kind))
+	channel.URL = strings.TrimSpace(channel.URL)
+	channel.ChatID = strings.TrimSpace(channel.ChatID)
+	channel.EventTypes = trimList(channel.EventTypes)
+	channel.Sources = trimList(channel.Sources)
+	channel.Endpoints = trimList(channel.Endpoints)
+
+	if channel.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidNotificationChannel)
+	}
+	switch channel.Kind {
+	case notifier.KindWebhook, notifier.KindSlack:
+	case notifier.KindTelegram:
+		if channel.ChatID == "" {
+			return fmt.Errorf("%w: chat_id is required for telegram channels", ErrInvalidNotificationChannel)
+		}
+	default:
+		return fmt.Errorf("%w: kind must be %s, %s or %s", ErrInvalidNotificationChannel,
+			notifier.KindWebhook, notifier.KindSlack, notifier.KindTelegram)
+	}
+
+	parsed, err := neturl.Parse(channel.URL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("%w: url must be an absolute http(s) URL", ErrInvalidNotificationChannel)
+	}
+
+	for _, eventType := range channel.EventTypes {
+		if !containsString(notifier.EventTypes, eventType) {
+			return fmt.Errorf("%w: unknown event type %q (use %s)", ErrInvalidNotificationChannel,
+				eventType, strings.Join(notifier.EventTypes, ", "))
+		}
+	}
+	for _, endpoint := range channel.Endpoints {
+		if err := validateEndpointPath(endpoint); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidNotificationChannel, err)
+		}
+	}
+	if channel.DebounceSeconds < 0 {
+		return fmt.Errorf("%w: debounce_seconds must not be negative", ErrInvalidNotificationChannel)
+	}
+
+	existing, err := s.db.GetNotificationChannels()
+	if err != nil {
+		return err
+	}
+	for _, other := range existing {
+		if other.ID != channel.ID && strings.EqualFold(other.Name, channel.Name) {
+			return fmt.Errorf("%w: a channel named %s already exists", ErrInvalidNotificationChannel, channel.Name)
+		}
+	}
+	return nil
+}
+
+// trimList trims every value and drops empty ones
+func trimList(values []string) []string {
+	trimmed := []string{}
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			trimmed = append(trimmed, value)
+		}
+	}
+	return trimmed
+}
+
+func containsString(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,175 @@
+package service
+
+import (
+	"apicategorywithfallback/pkg/auth"
+	"apicategorywithfallback/pkg/database"
+	"apicategorywithfallback/pkg/logger"
+	"apicategorywithfallback/pkg/ratelimit"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrInvalidAPIKey is returned for unknown or disabled API keys
+	ErrInvalidAPIKey = errors.New("invalid or inactive API key")
+	// ErrAPIKeyRequired is returned for anonymous requests when API_KEY_REQUIRED is set
+	ErrAPIKeyRequired = errors.New("API key required")
+)
+
+// apiKeyPrefix is prepended to generated API keys
+const apiKeyPrefix = "ak_"
+
+// apiKeyCacheTTL is how long API key lookups are cached in memory
+const apiKeyCacheTTL = 30 * time.Second
+
+// RateLimitResult is the outcome of the rate limit and quota checks for one request
+type RateLimitResult struct {
+	ratelimit.Decision
+	APIKey        *database.APIKey // nil for anonymous requests
+	QuotaLimit    int              // Daily quota of the API key, 0 = unlimited
+	QuotaUsed     int              // Requests counted against today's quota
+	QuotaExceeded bool
+}
+
+// apiKeyCache caches API key lookups by key hash. Unknown hashes are not
+// cached, so it holds at most one entry per stored key.
+type apiKeyCache struct {
+	mu      sync.Mutex
+	entries map[string]apiKeyCacheEntry
+}
+
+type apiKeyCacheEntry struct {
+	key       *database.APIKey
+	expiresAt time.Time
+}
+
+// CheckRateLimit identifies the client by API key (or by IP when no key is given),
+// counts the request against its per-minute limit and daily quota, and reports
+// whether it may proceed
+func (s *APIService) CheckRateLimit(rawAPIKey, clientIP string) (*RateLimitResult, error) {
+	if rawAPIKey == "" {
+		if s.config.APIKeyRequired {
+			return nil, ErrAPIKeyRequired
+		}
+
+		decision := s.limiter.Allow("ip:"+clientIP, s.config.RateLimit, s.config.RateLimitWindow)
+		return &RateLimitResult{Decision: decision}, nil
+	}
+
+	key, err := s.lookupAPIKey(rawAPIKey)
+	if err != nil {
+		return nil, err
+	}
+	if key == nil || !key.IsActive {
+		return nil, ErrInvalidAPIKey
+	}
+
+	result := &RateLimitResult{
+		Decision:   s.limiter.Allow(fmt.Sprintf("key:%d", key.ID), key.RateLimit, time.Minute),
+		APIKey:     key,
+		QuotaLimit: key.DailyQuota,
+	}
+	if !result.Allowed {
+		return result, nil
+	}
+
+	used, ok, err := s.db.ConsumeAPIKeyQuota(key.ID, key.DailyQuota)
+	if err != nil {
+		// Do not block traffic because usage accounting failed
+		logger.Errorf("Failed to count quota for API key %s: %v", key.Name, err)
+		return result, nil
+	}
+
+	result.QuotaUsed = used
+	if !ok {
+		now := time.Now().UTC()
+		midnight := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+
+		result.Allowed = false
+		result.QuotaExceeded = true
+		result.Reset = midnight
+		result.RetryAfter = midnight.Sub(now)
+		logger.Warnf("Daily quota of %d exhausted for API key %s", key.DailyQuota, key.Name)
+	}
+
+	return result, nil
+}
+
+// lookupAPIKey resolves a raw API key, caching known keys briefly
+func (s *APIService) lookupAPIKey(rawAPIKey string) (*database.APIKey, error) {
+	hash := auth.HashToken(rawAPIKey)
+
+	s.apiKeys.mu.Lock()
+	entry, exists := s.apiKeys.entries[hash]
+	s.apiKeys.mu.Unlock()
+
+	if exists && time.Now().Before(entry.expiresAt) {
+		return entry.key, nil
+	}
+
+	key, err := s.db.GetAPIKeyByHash(hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up API key: %w", err)
+	}
+	if key == nil {
+		return nil, nil
+	}
+
+	s.apiKeys.mu.Lock()
+	s.apiKeys.entries[hash] = apiKeyCacheEntry{key: key, expiresAt: time.Now().Add(apiKeyCacheTTL)}
+	s.apiKeys.mu.Unlock()
+
+	return key, nil
+}
+
+// invalidateAPIKeyCache drops all cached API key lookups after a change
+func (s *APIService) invalidateAPIKeyCache() {
+	s.apiKeys.mu.Lock()
+	s.apiKeys.entries = make(map[string]apiKeyCacheEntry)
+	s.apiKeys.mu.Unlock()
+}
+
+// GetAllAPIKeys returns all API keys
+func (s *APIService) GetAllAPIKeys() ([]database.APIKey, error) {
+	return s.db.GetAllAPIKeys()
+}
+
+// CreateAPIKey generates a new API key. The raw key is returned only here.
+func (s *APIService) CreateAPIKey(name string, rateLimit, dailyQuota int) (*database.APIKey, string, error) {
+	rawKey, err := auth.GenerateToken(apiKeyPrefix)
+	if err != nil {
+		return nil, "", err
+	}
+
+	key, err := s.db.CreateAPIKey(name, auth.HashToken(rawKey), auth.DisplayPrefix(rawKey), rateLimit, dailyQuota)
+	if err != nil {
+		return nil, "", err
+	}
+
+	s.invalidateAPIKeyCache()
+	return key, rawKey, nil
+}
+
+// UpdateAPIKey updates an API key's name, limits and status
+func (s *APIService) UpdateAPIKey(id int, name string, rateLimit, dailyQuota int, isActive bool) error {
+	if err := s.db.UpdateAPIKey(id, name, rateLimit, dailyQuota, isActive); err != nil {
+		return err
+	}
+
+	s.limiter.Reset(fmt.Sprintf("key:%d", id))
+	s.invalidateAPIKeyCache()
+	return nil
+}
+
+// DeleteAPIKey deletes an API key
+func (s *APIService) DeleteAPIKey(id int) error {
+	if err := s.db.DeleteAPIKey(id); err != nil {
+		return err
+	}
+
+	s.limiter.Reset(fmt.Sprintf("key:%d", id))
+	s.invalidateAPIKeyCache()
+	return nil
+}
@@ -0,0 +1,177 @@
+package service
+
+import (
+	"apicategorywithfallback/pkg/database"
+	"apicategorywithfallback/pkg/logger"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ErrRequestMappingNotFound is returned when a request mapping does not exist
+var ErrRequestMappingNotFound = errors.New("request mapping not found")
+
+// ErrInvalidRequestMapping is returned when a request mapping fails validation
+var ErrInvalidRequestMapping = errors.New("invalid request mapping")
+
+// anySource is the source name of mappings that apply to every source
+const anySource = "*"
+
+func requestMappingKey(sourceName, endpoint string) string {
+	return sourceName + " " + strings.TrimSuffix(endpoint, "/")
+}
+
+// requestMapping returns the rules for calling a source's endpoint: the "*" rule
+// for the endpoint overlaid with the source's own rule. It never returns nil.
+func (s *APIService) requestMapping(sourceName, endpoint string) *database.RequestMapping {
+	entries, err := s.requestMappings()
+	if err != nil {
+		logger.Warnf("Failed to load request mappings: %v", err)
+	}
+
+	effective := &database.RequestMapping{
+		SourceName:    sourceName,
+		EndpointPath:  endpoint,
+		ParamRenames:  map[string]string{},
+		DefaultParams: map[string]string{},
+		Headers:       map[string]string{},
+		IsActive:      true,
+	}
+	for _, name := range []string{anySource, sourceName} {
+		mapping, exists := entries[requestMappingKey(name, endpoint)]
+		if !exists {
+			continue
+		}
+		for key, value := range mapping.ParamRenames {
+			effective.ParamRenames[key] = value
+		}
+		for key, value := range mapping.DefaultParams {
+			effective.DefaultParams[key] = value
+		}
+		for key, value := range mapping.Headers {
+			effective.Headers[key] = value
+		}
+		if mapping.PathSuffix != "" {
+			effective.PathSuffix = mapping.PathSuffix
+		}
+	}
+
+	return effective
+}
+
+// requestMappings returns the cached active mappings by source and endpoint
+func (s *APIService) requestMappings() (map[string]database.RequestMapping, error) {
+	return s.mappings.Get(func() (map[string]database.RequestMapping, error) {
+		mappings, err := s.db.GetRequestMappings()
+		if err != nil {
+			return nil, err
+		}
+
+		entries := make(map[string]database.RequestMapping)
+		for _, mapping := range mappings {
+			if mapping.IsActive {
+				entries[requestMappingKey(mapping.SourceName, mapping.EndpointPath)] = mapping
+			}
+		}
+		return entries, nil
+	})
+}
+
+// GetRequestMappings returns all request mappings
+func (s *APIService) GetRequestMappings() ([]database.RequestMapping, error) {
+	return s.db.GetRequestMappings()
+}
+
+// CreateRequestMapping validates and stores a new request mapping
+func (s *APIService) CreateRequestMapping(mapping *database.RequestMapping) error {
+	if err := s.validateRequestMapping(mapping); err != nil {
+		return err
+	}
+
+	defer s.mappings.Invalidate()
+	return s.db.CreateRequestMapping(mapping)
+}
+
+// UpdateRequestMapping validates and replaces a request mapping
+func (s *APIService) UpdateRequestMapping(mapping *database.RequestMapping) error {
+	existing, err := s.db.GetRequestMapping(mapping.ID)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return ErrRequestMappingNotFound
+	}
+	if err := s.validateRequestMapping(mapping); err != nil {
+		return err
+	}
+
+	defer s.mappings.Invalidate()
+	return s.db.UpdateRequestMapping(mapping)
+}
+
+// DeleteRequestMapping deletes a request mapping
+func (s *APIService) DeleteRequestMapping(id int) error {
+	existing, err := s.db.GetRequestMapping(id)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return ErrRequestMappingNotFound
+	}
+
+	defer s.mappings.Invalidate()
+	return s.db.DeleteRequestMapping(id)
+}
+
+// validateRequestMapping trims and checks a mapping before it is stored; each
+// source and endpoint pair may have only one mapping
+func (s *APIService) validateRequestMapping(mapping *database.RequestMapping) error {
+	mapping.SourceName = strings.TrimSpace(mapping.SourceName)
+	mapping.EndpointPath = strings.TrimSpace(mapping.EndpointPath)
+	if mapping.ParamRenames == nil {
+		mapping.ParamRenames = map[string]string{}
+	}
+	if mapping.DefaultParams == nil {
+		mapping.DefaultParams = map[string]string{}
+	}
+	if mapping.Headers == nil {
+		mapping.Headers = map[string]string{}
+	}
+
+	if mapping.SourceName == "" {
+		return fmt.Errorf("%w: source_name is required (use %q for every source)", ErrInvalidRequestMapping, anySource)
+	}
+	if err := validateEndpointPath(mapping.EndpointPath); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidRequestMapping, err)
+	}
+	if strings.ContainsAny(mapping.PathSuffix, "?#") {
+		return fmt.Errorf("%w: path_suffix must not contain a query or fragment", ErrInvalidRequestMapping)
+	}
+	for from, to := range mapping.ParamRenames {
+		if from == "" || to == "" {
+			return fmt.Errorf("%w: param_renames entries need non-empty names", ErrInvalidRequestMapping)
+		}
+	}
+	for name := range mapping.Headers {
+		if name == "" || strings.ContainsAny(name, " :\r\n") {
+			return fmt.Errorf("%w: invalid header name %q", ErrInvalidRequestMapping, name)
+		}
+		if strings.EqualFold(http.CanonicalHeaderKey(name), "Traceparent") {
+			return fmt.Errorf("%w: header %s is set by the gateway", ErrInvalidRequestMapping, name)
+		}
+	}
+
+	existing, err := s.db.GetRequestMappings()
+	if err != nil {
+		return err
+	}
+	key := requestMappingKey(mapping.SourceName, mapping.EndpointPath)
+	for _, other := range existing {
+		if other.ID != mapping.ID && requestMappingKey(other.SourceName, other.EndpointPath) == key {
+			return fmt.Errorf("%w: source %s already has a mapping for %s", ErrInvalidRequestMapping, mapping.SourceName, mapping.EndpointPath)
+		}
+	}
+
+	return nil
+}
@@ -5,13 +5,11 @@ import (
 	"testing"
 
 	"apicategorywithfallback/pkg/config"
-	"apicategorywithfallback/pkg/database"
 )
 
 func TestNormalizeResponseStructure(t *testing.T) {
 	// Create a test service
 	cfg := &config.Config{}
-	db := &database.DB{} // This would normally be properly initialized
 	service := &APIService{
 		config: cfg,
 	}
@@ -200,17 +198,16 @@ func TestNormalizeDataFields(t *testing.T) {
 		expected   map[string]interface{}
 	}{
 		{
-			name: "Should add missing confidence_score and source",
+			name: "Should add missing source but not a confidence_score",
 			input: map[string]interface{}{
 				"anime_slug": "test-anime",
 				"cover":      "https://example.com/cover.jpg",
 			},
 			sourceName: "test-source",
 			expected: map[string]interface{}{
-				"anime_slug":       "test-anime",
-				"cover":            "https://example.com/cover.jpg",
-				"confidence_score": 1.0,
-				"source":           "test-source",
+				"anime_slug": "test-anime",
+				"cover":      "https://example.com/cover.jpg",
+				"source":     "test-source",
 			},
 		},
 		{
@@ -233,6 +230,13 @@ func TestNormalizeDataFields(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			result := service.normalizeDataFields(tt.input, tt.sourceName)
 
+			// A missing score is left to the confidence policy
+			if _, inInput := tt.input["confidence_score"]; !inInput {
+				if _, exists := result["confidence_score"]; exists {
+					t.Errorf("confidence_score should not be added")
+				}
+			}
+
 			for key, expectedValue := range tt.expected {
 				actualValue, exists := result[key]
 				if !exists {
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"apicategorywithfallback/pkg/logger"
+	"apicategorywithfallback/pkg/routing"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ErrEndpointNotFound is returned when no configured endpoint matches a request path
+var ErrEndpointNotFound = errors.New("endpoint not found")
+
+// ErrInvalidEndpointPath is returned when an endpoint path is not a valid template
+var ErrInvalidEndpointPath = errors.New("invalid endpoint path")
+
+// ResolveEndpoint matches a request path against the endpoints configured for a
+// category and returns the matched template with its path parameters. Category
+// "all" matches against every category.
+func (s *APIService) ResolveEndpoint(category, path string) (string, map[string]string, error) {
+	tables, err := s.routeTables()
+	if err != nil {
+		return "", nil, err
+	}
+
+	if category == "all" {
+		names := make([]string, 0, len(tables))
+		for name := range tables {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			if route, params, ok := tables[name].Match(path); ok {
+				return route, params, nil
+			}
+		}
+		return "", nil, ErrEndpointNotFound
+	}
+
+	table, exists := tables[category]
+	if !exists {
+		return "", nil, ErrEndpointNotFound
+	}
+	route, params, ok := table.Match(path)
+	if !ok {
+		return "", nil, ErrEndpointNotFound
+	}
+	return route, params, nil
+}
+
+// routeTables returns the cached endpoints table as one routing table per category
+func (s *APIService) routeTables() (map[string]*routing.Table, error) {
+	return s.routes.Get(func() (map[string]*routing.Table, error) {
+		endpoints, err := s.db.GetAllEndpoints()
+		if err != nil {
+			return nil, err
+		}
+
+		tables := make(map[string]*routing.Table)
+		for _, endpoint := range endpoints {
+			table, exists := tables[endpoint.CategoryName]
+			if !exists {
+				table = routing.NewTable()
+				tables[endpoint.CategoryName] = table
+			}
+			if err := table.Add(endpoint.Path); err != nil {
+				logger.Warnf("Skipping endpoint %d in category %s: %v", endpoint.ID, endpoint.CategoryName, err)
+			}
+		}
+		return tables, nil
+	})
+}
+
+// validateEndpointPath checks an endpoint path before it is stored
+func validateEndpointPath(path string) error {
+	if err := routing.Validate(path); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidEndpointPath, err)
+	}
+	return nil
+}
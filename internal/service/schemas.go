@@ -0,0 +1,121 @@
+package service
+
+import (
+	"apicategorywithfallback/pkg/database"
+	"apicategorywithfallback/pkg/logger"
+	"apicategorywithfallback/pkg/routing"
+	"apicategorywithfallback/pkg/tracing"
+	"apicategorywithfallback/pkg/validator"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrEndpointSchemaNotFound is returned when a stored endpoint schema does not exist
+var ErrEndpointSchemaNotFound = errors.New("endpoint schema not found")
+
+// ErrInvalidEndpointSchema is returned when an endpoint schema fails validation
+var ErrInvalidEndpointSchema = errors.New("invalid endpoint schema")
+
+// validateResponse validates an upstream response inside its own span
+func (s *APIService) validateResponse(ctx context.Context, endpoint string, data []byte, policy validator.ConfidencePolicy) error {
+	_, span := tracing.Start(ctx, "response.validate")
+	err := s.schemaRegistry().ValidateWithPolicy(endpoint, data, policy)
+	tracing.End(span, err)
+	return err
+}
+
+// schemaRegistry returns the cached response schemas: built-in, then
+// SCHEMA_DIR files, then database rows, each overriding the previous for the
+// same endpoint. Without the database rows it falls back to the previous
+// registry, or to the built-in and file schemas.
+func (s *APIService) schemaRegistry() *validator.Registry {
+	registry, err := s.schemas.Get(s.loadSchemaRegistry)
+	if err != nil {
+		logger.Warnf("Failed to load endpoint schemas: %v", err)
+	}
+	return registry
+}
+
+// loadSchemaRegistry builds the schema registry; on a database error it
+// returns the registry without the database rows along with the error
+func (s *APIService) loadSchemaRegistry() (*validator.Registry, error) {
+	registry := validator.NewRegistry()
+
+	if s.config.SchemaDir != "" {
+		files, err := validator.LoadSchemaDir(s.config.SchemaDir)
+		if err != nil {
+			logger.Warnf("Failed to load schemas from %s: %v", s.config.SchemaDir, err)
+		}
+		for _, schema := range files {
+			if err := registry.Register(schema); err != nil {
+				logger.Warnf("Ignoring schema file for %s: %v", schema.Endpoint, err)
+			}
+		}
+	}
+
+	stored, err := s.db.GetEndpointSchemas()
+	if err != nil {
+		return registry, err
+	}
+	for _, row := range stored {
+		if !row.IsActive {
+			continue
+		}
+		schema, err := validator.ParseSchema([]byte(row.Schema))
+		if err != nil {
+			logger.Warnf("Ignoring schema of endpoint %s: %v", row.EndpointPath, err)
+			continue
+		}
+		entry := validator.EndpointSchema{Endpoint: row.EndpointPath, Schema: schema, Origin: validator.OriginDatabase}
+		if err := registry.Register(entry); err != nil {
+			logger.Warnf("Ignoring schema of endpoint %s: %v", row.EndpointPath, err)
+		}
+	}
+
+	return registry, nil
+}
+
+// GetEffectiveSchemas returns the schema in use for each endpoint, with its origin
+func (s *APIService) GetEffectiveSchemas() []validator.EndpointSchema {
+	return s.schemaRegistry().Schemas()
+}
+
+// GetEndpointSchemas returns the schemas stored in the database
+func (s *APIService) GetEndpointSchemas() ([]database.EndpointSchema, error) {
+	return s.db.GetEndpointSchemas()
+}
+
+// SaveEndpointSchema validates and stores the schema of an endpoint path,
+// replacing any stored schema for the same path
+func (s *APIService) SaveEndpointSchema(schema *database.EndpointSchema) error {
+	schema.EndpointPath = strings.TrimSpace(schema.EndpointPath)
+	if err := routing.Validate(schema.EndpointPath); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidEndpointSchema, err)
+	}
+	if _, err := validator.ParseSchema([]byte(schema.Schema)); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidEndpointSchema, err)
+	}
+	if schema.EndpointPath != "/" {
+		schema.EndpointPath = strings.TrimRight(schema.EndpointPath, "/")
+	}
+
+	defer s.schemas.Invalidate()
+	return s.db.SaveEndpointSchema(schema)
+}
+
+// DeleteEndpointSchema deletes a stored schema; the endpoint falls back to its
+// file or built-in schema, if any
+func (s *APIService) DeleteEndpointSchema(id int) error {
+	existing, err := s.db.GetEndpointSchema(id)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return ErrEndpointSchemaNotFound
+	}
+
+	defer s.schemas.Invalidate()
+	return s.db.DeleteEndpointSchema(id)
+}
@@ -0,0 +1,230 @@
+package service
+
+import (
+	"apicategorywithfallback/internal/domain"
+	"apicategorywithfallback/pkg/database"
+	"apicategorywithfallback/pkg/logger"
+	"apicategorywithfallback/pkg/metrics"
+	"apicategorywithfallback/pkg/sla"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrInvalidSLAWindow is returned for an unknown uptime report window
+var ErrInvalidSLAWindow = errors.New("invalid SLA window")
+
+// outcomeFlushInterval bounds how long live request outcomes stay in memory
+// before they are added to the hourly counts
+const outcomeFlushInterval = time.Minute
+
+// outcomeKey identifies the hourly request counts of a source endpoint
+type outcomeKey struct {
+	source   string
+	endpoint string
+	hour     time.Time
+}
+
+// outcomeBuffer collects live request outcomes between flushes
+type outcomeBuffer struct {
+	mu        sync.Mutex
+	counts    map[outcomeKey]*database.SourceOutcome
+	flushedAt time.Time
+}
+
+// SourceSLA is the uptime report of a source, with the report of each of its endpoints
+type SourceSLA struct {
+	SourceName string `json:"source_name"`
+	sla.Report
+	Endpoints []EndpointSLA `json:"endpoints"`
+}
+
+// EndpointSLA is the uptime report of one endpoint of a source, without its timeline
+type EndpointSLA struct {
+	EndpointPath string `json:"endpoint_path"`
+	sla.Report
+}
+
+// observeOutcome counts a primary attempt towards its source's hourly
+// outcomes; cancelled attempts say nothing about the source
+func (s *APIService) observeOutcome(reqCtx *domain.RequestContext, source, role, outcome string) {
+	if role != metrics.RolePrimary || outcome == metrics.OutcomeCancelled {
+		return
+	}
+
+	now := time.Now()
+	key := outcomeKey{source: source, endpoint: reqCtx.RoutePath(), hour: now.UTC().Truncate(time.Hour)}
+
+	s.outcomes.mu.Lock()
+	if s.outcomes.counts == nil {
+		s.outcomes.counts = make(map[outcomeKey]*database.SourceOutcome)
+		s.outcomes.flushedAt = now
+	}
+	counts, exists := s.outcomes.counts[key]
+	if !exists {
+		counts = &database.SourceOutcome{SourceName: key.source, EndpointPath: key.endpoint, Hour: key.hour}
+		s.outcomes.counts[key] = counts
+	}
+	counts.Requests++
+	if outcome != metrics.OutcomeSuccess {
+		counts.Failures++
+	}
+	flush := now.Sub(s.outcomes.flushedAt) >= outcomeFlushInterval
+	if flush {
+		s.outcomes.flushedAt = now
+	}
+	s.outcomes.mu.Unlock()
+
+	if flush {
+		go s.flushOutcomes()
+	}
+}
+
+// flushOutcomes adds the buffered outcomes to the hourly counts; on failure
+// they are put back for the next flush
+func (s *APIService) flushOutcomes() {
+	s.outcomes.mu.Lock()
+	counts := s.outcomes.counts
+	s.outcomes.counts = make(map[outcomeKey]*database.SourceOutcome)
+	s.outcomes.mu.Unlock()
+
+	if len(counts) == 0 {
+		return
+	}
+
+	outcomes := make([]database.SourceOutcome, 0, len(counts))
+	for _, count := range counts {
+		outcomes = append(outcomes, *count)
+	}
+	if err := s.db.AddSourceOutcomes(outcomes); err != nil {
+		logger.Warnf("Failed to store request outcomes: %v", err)
+
+		s.outcomes.mu.Lock()
+		for key, count := range counts {
+			if current, exists := s.outcomes.counts[key]; exists {
+				current.Requests += count.Requests
+				current.Failures += count.Failures
+			} else {
+				s.outcomes.counts[key] = count
+			}
+		}
+		s.outcomes.mu.Unlock()
+	}
+}
+
+// slaCheckMaxAge is how long a health check's status holds when no newer
+// check follows: two health check intervals, so one missed run is tolerated
+func (s *APIService) slaCheckMaxAge() time.Duration {
+	if s.config.HealthCheckInterval <= 0 {
+		return 20 * time.Minute
+	}
+	return 2 * s.config.HealthCheckInterval
+}
+
+// GetSLAReports returns the uptime, MTTR and incidents of every source with
+// health checks or live requests in the window ("24h", "7d" or "30d"),
+// optionally only for one source
+func (s *APIService) GetSLAReports(windowName, sourceName string) ([]SourceSLA, error) {
+	window, ok := sla.WindowByName(windowName)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q (use 24h, 7d or 30d)", ErrInvalidSLAWindow, windowName)
+	}
+
+	s.flushOutcomes()
+
+	now := time.Now()
+	maxAge := s.slaCheckMaxAge()
+	start := window.Start(now)
+
+	history, err := s.db.GetHealthCheckHistory(start.Add(-maxAge))
+	if err != nil {
+		return nil, err
+	}
+	outcomes, err := s.db.GetSourceOutcomes(start)
+	if err != nil {
+		return nil, err
+	}
+
+	// Group both signals by source, then endpoint
+	checks := make(map[string]map[string][]sla.Check)
+	hourly := make(map[string]map[string][]sla.Outcomes)
+	for _, record := range history {
+		if (sourceName != "" && record.SourceName != sourceName) || record.Status == healthSkipped {
+			continue
+		}
+		if checks[record.SourceName] == nil {
+			checks[record.SourceName] = make(map[string][]sla.Check)
+		}
+		checks[record.SourceName][record.EndpointPath] = append(checks[record.SourceName][record.EndpointPath],
+			sla.Check{At: record.CheckedAt, Status: record.Status, Message: record.ErrorMessage})
+	}
+	for _, outcome := range outcomes {
+		if sourceName != "" && outcome.SourceName != sourceName {
+			continue
+		}
+		if hourly[outcome.SourceName] == nil {
+			hourly[outcome.SourceName] = make(map[string][]sla.Outcomes)
+		}
+		hourly[outcome.SourceName][outcome.EndpointPath] = append(hourly[outcome.SourceName][outcome.EndpointPath],
+			sla.Outcomes{Hour: outcome.Hour, Requests: outcome.Requests, Failures: outcome.Failures})
+	}
+
+	sources := make(map[string]bool)
+	for name := range checks {
+		sources[name] = true
+	}
+	for name := range hourly {
+		sources[name] = true
+	}
+
+	reports := make([]SourceSLA, 0, len(sources))
+	for name := range sources {
+		endpoints := make(map[string]sla.Report)
+		for endpoint, endpointChecks := range checks[name] {
+			endpoints[endpoint] = sla.Compute(now, window, endpointChecks, hourly[name][endpoint], maxAge)
+		}
+		for endpoint, endpointOutcomes := range hourly[name] {
+			if _, done := endpoints[endpoint]; !done {
+				endpoints[endpoint] = sla.Compute(now, window, nil, endpointOutcomes, maxAge)
+			}
+		}
+
+		report := SourceSLA{SourceName: name, Report: sla.Combine(endpoints), Endpoints: []EndpointSLA{}}
+		for endpoint, endpointReport := range endpoints {
+			endpointReport.Timeline = nil
+			report.Endpoints = append(report.Endpoints, EndpointSLA{EndpointPath: endpoint, Report: endpointReport})
+		}
+		sort.Slice(report.Endpoints, func(i, j int) bool {
+			return report.Endpoints[i].EndpointPath < report.Endpoints[j].EndpointPath
+		})
+		reports = append(reports, report)
+	}
+
+	sort.Slice(reports, func(i, j int) bool { return reports[i].SourceName < reports[j].SourceName })
+	return reports, nil
+}
+
+// overallUptime returns the mean 24h uptime of the sources with data, formatted
+// as a percentage, or "N/A" when there is none
+func (s *APIService) overallUptime() string {
+	reports, err := s.GetSLAReports("24h", "")
+	if err != nil {
+		logger.Warnf("Failed to compute uptime: %v", err)
+		return "N/A"
+	}
+
+	var total float64
+	count := 0
+	for _, report := range reports {
+		if report.Uptime != nil {
+			total += *report.Uptime
+			count++
+		}
+	}
+	if count == 0 {
+		return "N/A"
+	}
+	return fmt.Sprintf("%.1f%%", total/float64(count))
+}
@@ -0,0 +1,312 @@
+package service
+
+import (
+	"apicategorywithfallback/pkg/database"
+	"apicategorywithfallback/pkg/dedup"
+	"apicategorywithfallback/pkg/logger"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ErrCanonicalTitleNotFound is returned when a canonical title does not exist
+var ErrCanonicalTitleNotFound = errors.New("canonical title not found")
+
+// ErrInvalidCanonicalTitle is returned when a canonical title fails validation
+var ErrInvalidCanonicalTitle = errors.New("invalid canonical title")
+
+// slugParams are the request parameters that identify an anime
+var slugParams = []string{"id", "slug", "anime_slug"}
+
+// fallbackSuffix matches the suffixes added to the names of fallback responses
+var fallbackSuffix = regexp.MustCompile(`_fallback(_\d+)?$`)
+
+// translateSlugs returns params with anime identifiers replaced by the slug
+// the source uses for the same canonical title. params is not modified.
+func (s *APIService) translateSlugs(sourceName string, params map[string]string) map[string]string {
+	var translated map[string]string
+	for _, key := range slugParams {
+		value := params[key]
+		if value == "" {
+			continue
+		}
+		native, ok := s.nativeSlug(sourceName, value)
+		if !ok || native == value {
+			continue
+		}
+		if translated == nil {
+			translated = make(map[string]string, len(params))
+			for k, v := range params {
+				translated[k] = v
+			}
+		}
+		translated[key] = native
+	}
+
+	if translated == nil {
+		return params
+	}
+	logger.Debugf("Translated slug parameters for %s: %v", sourceName, translated)
+	return translated
+}
+
+// nativeSlug returns the slug a source uses for the title identified by a
+// canonical ID or by any source's slug
+func (s *APIService) nativeSlug(sourceName, value string) (string, bool) {
+	titles, err := s.canonicalTitles()
+	if err != nil {
+		logger.Warnf("Failed to load canonical titles: %v", err)
+	}
+
+	title, exists := titles[value]
+	if !exists {
+		return "", false
+	}
+	native, exists := title.Slugs[sourceName]
+	return native, exists
+}
+
+// canonicalTitles returns the cached canonical titles by canonical ID and by every source slug
+func (s *APIService) canonicalTitles() (map[string]*database.CanonicalTitle, error) {
+	return s.slugs.Get(func() (map[string]*database.CanonicalTitle, error) {
+		list, err := s.db.GetCanonicalTitles()
+		if err != nil {
+			return nil, err
+		}
+
+		titles := make(map[string]*database.CanonicalTitle)
+		for i := range list {
+			title := &list[i]
+			for _, slug := range title.Slugs {
+				titles[slug] = title
+			}
+		}
+		// Canonical IDs win over source slugs that happen to be equal
+		for i := range list {
+			titles[list[i].CanonicalID] = &list[i]
+		}
+		return titles, nil
+	})
+}
+
+// Bounds of the slug group writer: groups waiting to be written (more are
+// dropped until it catches up), and groups remembered as written so that
+// repeated lists cost no database writes
+const (
+	maxPendingSlugGroups  = 1000
+	maxRecordedSlugGroups = 10000
+)
+
+// slugGroupBuffer holds the slug groups of aggregated lists for a single
+// background writer
+type slugGroupBuffer struct {
+	mu       sync.Mutex
+	pending  map[string]slugGroup // By slugGroup.key
+	recorded map[string]bool      // Keys already written
+	writing  bool                 // A writer goroutine is running
+}
+
+// slugGroup is a title with the slug each source uses for it
+type slugGroup struct {
+	canonicalID string // Slug of the first source
+	title       string
+	slugs       map[string]string
+}
+
+// key identifies a group by its sorted source slugs
+func (g slugGroup) key() string {
+	pairs := make([]string, 0, len(g.slugs))
+	for source, slug := range g.slugs {
+		pairs = append(pairs, source+"="+slug)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// newSlugGroup returns the slugs of a list item that several sources returned,
+// one per primary source; ok is false when fewer than two sources have a slug,
+// or when the sources only gave similar titles, which is not enough to link
+// their slugs for good
+func newSlugGroup(group dedup.Group) (slugGroup, bool) {
+	if !group.Exact {
+		return slugGroup{}, false
+	}
+	g := slugGroup{title: group.Title, slugs: make(map[string]string)}
+	for _, alt := range group.Alternates {
+		sourceName := fallbackSuffix.ReplaceAllString(alt.Source, "")
+		if alt.Slug == "" || g.slugs[sourceName] != "" {
+			continue
+		}
+		g.slugs[sourceName] = alt.Slug
+		if g.canonicalID == "" {
+			g.canonicalID = alt.Slug
+		}
+	}
+	return g, len(g.slugs) >= 2
+}
+
+// queueSlugGroups hands the slug groups of an aggregated list to the
+// background writer, skipping groups already written
+func (s *APIService) queueSlugGroups(groups []dedup.Group) {
+	s.slugWrites.mu.Lock()
+	if s.slugWrites.pending == nil {
+		s.slugWrites.pending = make(map[string]slugGroup)
+	}
+	dropped := 0
+	for _, group := range groups {
+		g, ok := newSlugGroup(group)
+		if !ok {
+			continue
+		}
+		key := g.key()
+		if s.slugWrites.recorded[key] {
+			continue
+		}
+		if _, exists := s.slugWrites.pending[key]; !exists && len(s.slugWrites.pending) >= maxPendingSlugGroups {
+			dropped++
+			continue
+		}
+		s.slugWrites.pending[key] = g
+	}
+	start := len(s.slugWrites.pending) > 0 && !s.slugWrites.writing
+	if start {
+		s.slugWrites.writing = true
+	}
+	s.slugWrites.mu.Unlock()
+
+	if dropped > 0 {
+		logger.Warnf("Slug writer is behind, dropped %d slug groups", dropped)
+	}
+	if start {
+		go s.writeSlugGroups()
+	}
+}
+
+// writeSlugGroups stores the queued slug groups until none are left. Groups
+// that fail to store are not remembered, so a later list retries them.
+func (s *APIService) writeSlugGroups() {
+	for {
+		s.slugWrites.mu.Lock()
+		batch := s.slugWrites.pending
+		s.slugWrites.pending = make(map[string]slugGroup)
+		if len(batch) == 0 {
+			s.slugWrites.writing = false
+			s.slugWrites.mu.Unlock()
+			return
+		}
+		s.slugWrites.mu.Unlock()
+
+		changed := false
+		var written []string
+		for key, g := range batch {
+			stored, err := s.db.RecordSourceSlugs(g.canonicalID, g.title, g.slugs)
+			if err != nil {
+				logger.Warnf("Failed to record slugs for %s: %v", g.canonicalID, err)
+				continue
+			}
+			written = append(written, key)
+			changed = changed || stored
+		}
+		if changed {
+			s.slugs.Invalidate()
+		}
+
+		s.slugWrites.mu.Lock()
+		if s.slugWrites.recorded == nil || len(s.slugWrites.recorded)+len(written) > maxRecordedSlugGroups {
+			s.slugWrites.recorded = make(map[string]bool)
+		}
+		for _, key := range written {
+			s.slugWrites.recorded[key] = true
+		}
+		s.slugWrites.mu.Unlock()
+	}
+}
+
+// GetCanonicalTitles returns all canonical titles with their source slugs
+func (s *APIService) GetCanonicalTitles() ([]database.CanonicalTitle, error) {
+	return s.db.GetCanonicalTitles()
+}
+
+// CreateCanonicalTitle validates and stores a canonical title
+func (s *APIService) CreateCanonicalTitle(title *database.CanonicalTitle) error {
+	if err := s.validateCanonicalTitle(title); err != nil {
+		return err
+	}
+
+	defer s.slugs.Invalidate()
+	return s.db.CreateCanonicalTitle(title)
+}
+
+// UpdateCanonicalTitle validates and replaces a canonical title and its slugs
+func (s *APIService) UpdateCanonicalTitle(title *database.CanonicalTitle) error {
+	existing, err := s.db.GetCanonicalTitle(title.ID)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return ErrCanonicalTitleNotFound
+	}
+	if err := s.validateCanonicalTitle(title); err != nil {
+		return err
+	}
+
+	defer s.slugs.Invalidate()
+	return s.db.UpdateCanonicalTitle(title)
+}
+
+// DeleteCanonicalTitle deletes a canonical title and its slugs
+func (s *APIService) DeleteCanonicalTitle(id int) error {
+	existing, err := s.db.GetCanonicalTitle(id)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return ErrCanonicalTitleNotFound
+	}
+
+	defer s.slugs.Invalidate()
+	return s.db.DeleteCanonicalTitle(id)
+}
+
+// validateCanonicalTitle trims and checks a title edited from the dashboard; a
+// slug may belong to only one canonical title per source
+func (s *APIService) validateCanonicalTitle(title *database.CanonicalTitle) error {
+	title.CanonicalID = strings.TrimSpace(title.CanonicalID)
+	title.Title = strings.TrimSpace(title.Title)
+	title.IsManual = true
+	if title.Slugs == nil {
+		title.Slugs = map[string]string{}
+	}
+
+	if title.CanonicalID == "" {
+		return fmt.Errorf("%w: canonical_id is required", ErrInvalidCanonicalTitle)
+	}
+	for sourceName, slug := range title.Slugs {
+		if strings.TrimSpace(sourceName) == "" || strings.TrimSpace(slug) == "" {
+			return fmt.Errorf("%w: slugs need non-empty source names and slugs", ErrInvalidCanonicalTitle)
+		}
+	}
+
+	existing, err := s.db.GetCanonicalTitles()
+	if err != nil {
+		return err
+	}
+	for _, other := range existing {
+		if other.ID == title.ID {
+			continue
+		}
+		if other.CanonicalID == title.CanonicalID {
+			return fmt.Errorf("%w: canonical_id %s already exists", ErrInvalidCanonicalTitle, title.CanonicalID)
+		}
+		for sourceName, slug := range title.Slugs {
+			if other.Slugs[sourceName] == slug {
+				return fmt.Errorf("%w: %s slug %s already belongs to %s", ErrInvalidCanonicalTitle, sourceName, slug, other.CanonicalID)
+			}
+		}
+	}
+
+	return nil
+}
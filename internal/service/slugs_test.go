@@ -0,0 +1,69 @@
+package service
+
+import (
+	"apicategorywithfallback/pkg/config"
+	"apicategorywithfallback/pkg/dedup"
+	"testing"
+	"time"
+)
+
+// slugWriterIdle reports whether the slug writer has nothing left to write
+func (s *APIService) slugWriterIdle() bool {
+	s.slugWrites.mu.Lock()
+	defer s.slugWrites.mu.Unlock()
+	return !s.slugWrites.writing
+}
+
+func TestSlugGroupsAreWrittenOnce(t *testing.T) {
+	service := newUpstreamService(t, &config.Config{}, map[string]string{})
+	groups := []dedup.Group{{
+		Title: "One Piece",
+		Alternates: []dedup.Alternate{
+			{Source: "alpha", Slug: "one-piece"},
+			{Source: "beta_fallback", Slug: "1piece"},
+		},
+		Exact: true,
+	}}
+
+	service.queueSlugGroups(groups)
+	if !waitFor(t, 5*time.Second, service.slugWriterIdle) {
+		t.Fatal("Expected the slug writer to finish")
+	}
+	titles, err := service.db.GetCanonicalTitles()
+	if err != nil {
+		t.Fatalf("Failed to get canonical titles: %v", err)
+	}
+	if len(titles) != 1 || titles[0].CanonicalID != "one-piece" || titles[0].Slugs["beta"] != "1piece" {
+		t.Fatalf("Expected one-piece with beta's slug, got %+v", titles)
+	}
+
+	// The same list again costs no write, so the deleted title stays deleted
+	if err := service.db.DeleteCanonicalTitle(titles[0].ID); err != nil {
+		t.Fatalf("Failed to delete canonical title: %v", err)
+	}
+	service.queueSlugGroups(groups)
+	if !waitFor(t, 5*time.Second, service.slugWriterIdle) {
+		t.Fatal("Expected the slug writer to finish")
+	}
+	if titles, _ := service.db.GetCanonicalTitles(); len(titles) != 0 {
+		t.Errorf("Expected a repeated slug group not to be written again, got %+v", titles)
+	}
+}
+
+func TestSimilarSlugGroupsAreNotWritten(t *testing.T) {
+	service := newUpstreamService(t, &config.Config{}, map[string]string{})
+	service.queueSlugGroups([]dedup.Group{{
+		Title: "Shingeki no Kyojin",
+		Alternates: []dedup.Alternate{
+			{Source: "alpha", Slug: "shingeki-no-kyojin"},
+			{Source: "beta", Slug: "shingeki-no-kyojin-s"},
+		},
+	}})
+
+	if !service.slugWriterIdle() {
+		t.Fatal("Expected nothing to be queued")
+	}
+	if titles, _ := service.db.GetCanonicalTitles(); len(titles) != 0 {
+		t.Errorf("Expected a fuzzy slug group not to be written, got %+v", titles)
+	}
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"apicategorywithfallback/pkg/database"
+	"apicategorywithfallback/pkg/sla"
+	"apicategorywithfallback/pkg/traffic"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrInvalidTrafficGroup is returned for an unknown traffic report breakdown
+var ErrInvalidTrafficGroup = errors.New("invalid traffic breakdown")
+
+// TrafficReport is the request traffic of a window
+type TrafficReport struct {
+	Window string `json:"window"`
+	traffic.Report
+}
+
+// GetTrafficReport returns the requests, success rate and response times over
+// the window ("24h", "7d" or "30d") from the request rollups, in total and
+// broken down by endpoint, category or source ("" for no breakdown)
+func (s *APIService) GetTrafficReport(windowName, groupBy string) (*TrafficReport, error) {
+	window, ok := sla.WindowByName(windowName)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q (use 24h, 7d or 30d)", ErrInvalidSLAWindow, windowName)
+	}
+	if groupBy != "" && !containsString(traffic.Dimensions, groupBy) {
+		return nil, fmt.Errorf("%w: %q (use %s)", ErrInvalidTrafficGroup, groupBy, strings.Join(traffic.Dimensions, ", "))
+	}
+
+	granularity := database.RollupHourly
+	if window.Bucket >= 24*time.Hour {
+		granularity = database.RollupDaily
+	}
+
+	start := window.Start(time.Now())
+	rollups, err := s.db.GetRequestRollups(granularity, start)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]traffic.Row, len(rollups))
+	for i, rollup := range rollups {
+		rows[i] = traffic.Row{
+			Bucket:   rollup.Bucket,
+			Endpoint: rollup.Endpoint,
+			Category: rollup.Category,
+			Source:   rollup.Source,
+			Counts: traffic.Counts{
+				Requests:          rollup.Requests,
+				Successes:         rollup.Successes,
+				Failures:          rollup.Failures,
+				Fallbacks:         rollup.Fallbacks,
+				TimedRequests:     rollup.TimedRequests,
+				TotalResponseTime: rollup.TotalResponseTime,
+				MaxResponseTime:   rollup.MaxResponseTime,
+			},
+		}
+	}
+
+	buckets := int(window.Length / window.Bucket)
+	return &TrafficReport{Window: window.Name, Report: traffic.Build(start, window.Bucket, buckets, groupBy, rows)}, nil
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"apicategorywithfallback/pkg/database"
+	"apicategorywithfallback/pkg/logger"
+	"apicategorywithfallback/pkg/transform"
+	"apicategorywithfallback/pkg/validator"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidTransformSpec is returned when a transform spec or sample payload cannot be used
+var ErrInvalidTransformSpec = errors.New("invalid transform spec")
+
+// upstreamOptions holds a source's rules for building requests and reading responses
+type upstreamOptions struct {
+	mapping    *database.RequestMapping
+	transform  *transform.Spec
+	confidence validator.ConfidencePolicy
+}
+
+// TransformTestResult is the outcome of running a transform spec against a sample payload
+type TransformTestResult struct {
+	Output          json.RawMessage `json:"output"`
+	Valid           bool            `json:"valid"`                      // Output passes validation for the endpoint
+	ValidationError string          `json:"validation_error,omitempty"` // Set when validation fails
+}
+
+// upstreamOptions returns the request mapping, response transform and
+// confidence policy for calling a source's endpoint
+func (s *APIService) upstreamOptions(source database.APISource, endpoint string) upstreamOptions {
+	return upstreamOptions{
+		mapping:    s.requestMapping(source.SourceName, endpoint),
+		transform:  s.transformSpec(source.ID),
+		confidence: s.confidencePolicy(source.SourceName, endpoint),
+	}
+}
+
+// transformSpec returns the parsed transform spec of a source, or nil when it has none
+func (s *APIService) transformSpec(apiSourceID int) *transform.Spec {
+	specs, err := s.transforms.Get(func() (map[int]*transform.Spec, error) {
+		raw, err := s.db.GetTransformSpecs()
+		if err != nil {
+			return nil, err
+		}
+
+		specs := make(map[int]*transform.Spec, len(raw))
+		for id, text := range raw {
+			spec, err := transform.Parse(text)
+			if err != nil {
+				logger.Warnf("Ignoring transform spec of API source %d: %v", id, err)
+				continue
+			}
+			specs[id] = spec
+		}
+		return specs, nil
+	})
+	if err != nil {
+		logger.Warnf("Failed to load transform specs: %v", err)
+	}
+	return specs[apiSourceID]
+}
+
+// GetTransformSpec returns the response transform spec of an API source
+func (s *APIService) GetTransformSpec(apiSourceID int) (string, error) {
+	spec, found, err := s.db.GetTransformSpec(apiSourceID)
+	if err != nil {
+		return "", err
+	}
+	if !found {
+		return "", ErrAPISourceNotFound
+	}
+	return spec, nil
+}
+
+// SetTransformSpec validates and stores the response transform spec of an API
+// source; an empty spec removes it
+func (s *APIService) SetTransformSpec(apiSourceID int, raw string) error {
+	if err := s.ensureAPISource(apiSourceID); err != nil {
+		return err
+	}
+
+	raw = strings.TrimSpace(raw)
+	if _, err := transform.Parse(raw); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidTransformSpec, err)
+	}
+
+	defer s.transforms.Invalidate()
+	return s.db.SetTransformSpec(apiSourceID, raw)
+}
+
+// TestTransformSpec applies a spec to a sample payload the way live responses
+// are processed and, when an endpoint is given, validates the result
+func (s *APIService) TestTransformSpec(raw, payload, endpoint string) (*TransformTestResult, error) {
+	spec, err := transform.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTransformSpec, err)
+	}
+
+	output, err := spec.Apply([]byte(payload))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTransformSpec, err)
+	}
+	output, err = s.normalizeResponseStructure(output, "transform-test")
+	if err != nil {
+		return nil, err
+	}
+
+	result := &TransformTestResult{Output: output, Valid: true}
+	if endpoint != "" {
+		if err := s.validateResponse(context.Background(), endpoint, output, s.confidencePolicy(anySource, endpoint)); err != nil {
+			result.Valid = false
+			result.ValidationError = err.Error()
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"sync"
+	"time"
+)
+
+// configCacheTTL bounds how long configuration changes made outside the
+// dashboard take to apply; changes made through the service invalidate the
+// cache and apply at once
+const configCacheTTL = time.Minute
+
+// ttlCache holds a value built from database configuration, reloading it once
+// it is older than configCacheTTL or has been invalidated
+type ttlCache[T any] struct {
+	mu       sync.Mutex
+	value    T
+	loaded   bool
+	loadedAt time.Time // Zero after Invalidate
+}
+
+// Get returns the cached value, calling load when it is missing or expired.
+// When load fails the previous value is kept and returned with the error, or
+// load's own value when there is none.
+func (c *ttlCache[T]) Get(load func() (T, error)) (T, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.loaded && time.Since(c.loadedAt) < configCacheTTL {
+		return c.value, nil
+	}
+
+	value, err := load()
+	if err != nil {
+		if c.loaded {
+			return c.value, err
+		}
+		return value, err
+	}
+
+	c.value = value
+	c.loaded = true
+	c.loadedAt = time.Now()
+	return value, nil
+}
+
+// Invalidate makes the next Get reload the value
+func (c *ttlCache[T]) Invalidate() {
+	c.mu.Lock()
+	c.loadedAt = time.Time{}
+	c.mu.Unlock()
+}
@@ -0,0 +1,34 @@
+package service
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestTTLCache(t *testing.T) {
+	var cache ttlCache[int]
+	loads := 0
+	load := func() (int, error) {
+		loads++
+		return loads, nil
+	}
+	failing := func() (int, error) { return -1, errors.New("database is locked") }
+
+	if value, err := cache.Get(failing); err == nil || value != -1 {
+		t.Errorf("Expected load's value and error before anything was loaded, got %d, %v", value, err)
+	}
+	if value, _ := cache.Get(load); value != 1 {
+		t.Errorf("Expected the first load, got %d", value)
+	}
+	if value, _ := cache.Get(load); value != 1 || loads != 1 {
+		t.Errorf("Expected the cached value without reloading, got %d after %d loads", value, loads)
+	}
+
+	cache.Invalidate()
+	if value, err := cache.Get(failing); err == nil || value != 1 {
+		t.Errorf("Expected the previous value with the error, got %d, %v", value, err)
+	}
+	if value, _ := cache.Get(load); value != 2 {
+		t.Errorf("Expected a reload after the failed one, got %d", value)
+	}
+}
@@ -0,0 +1,201 @@
+package adaptive
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Outcome is the result of one upstream request
+type Outcome int
+
+const (
+	OutcomeSuccess Outcome = iota // Valid response
+	OutcomeInvalid                // Response received but rejected by validation
+	OutcomeError                  // No usable response (transport error, bad status, empty body)
+)
+
+const (
+	// NeutralScore is the score of sources with fewer than the minimum samples
+	NeutralScore = 0.5
+	// LatencyScale is the p95 latency that halves a score
+	LatencyScale = time.Second
+
+	// scoreBucket is the score resolution: closer scores tie and keep the static priority order
+	scoreBucket = 0.05
+	// maxSamples bounds the window of a single source and endpoint
+	maxSamples = 500
+)
+
+// Stats summarizes the window of a source and endpoint
+type Stats struct {
+	Source         string  `json:"source"`
+	Endpoint       string  `json:"endpoint"`
+	Samples        int     `json:"samples"`
+	SuccessRate    float64 `json:"success_rate"`    // Requests that returned a response
+	ValidationRate float64 `json:"validation_rate"` // Responses that passed validation
+	P95LatencyMs   int64   `json:"p95_latency_ms"`
+	Score          float64 `json:"score"`
+}
+
+// Candidate is a source to be ordered
+type Candidate struct {
+	Name     string
+	Priority int // Static priority, lower is better
+}
+
+type key struct {
+	source   string
+	endpoint string
+}
+
+type sample struct {
+	at      time.Time
+	outcome Outcome
+	latency time.Duration
+}
+
+// Tracker keeps a moving window of request outcomes per source and endpoint
+// and scores sources as success rate × validation rate × latency factor,
+// where the latency factor is LatencyScale / (LatencyScale + p95 latency)
+type Tracker struct {
+	mu         sync.Mutex
+	window     time.Duration
+	minSamples int
+	samples    map[key][]sample
+	now        func() time.Time
+}
+
+// New creates a tracker keeping samples for window; sources with fewer than
+// minSamples samples get NeutralScore
+func New(window time.Duration, minSamples int) *Tracker {
+	if minSamples < 1 {
+		minSamples = 1
+	}
+	return &Tracker{
+		window:     window,
+		minSamples: minSamples,
+		samples:    make(map[key][]sample),
+		now:        time.Now,
+	}
+}
+
+// Observe records the outcome and latency of a request
+func (t *Tracker) Observe(source, endpoint string, outcome Outcome, latency time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	k := key{source, endpoint}
+	samples := append(t.prune(t.samples[k]), sample{at: t.now(), outcome: outcome, latency: latency})
+	if len(samples) > maxSamples {
+		samples = samples[len(samples)-maxSamples:]
+	}
+	t.samples[k] = samples
+}
+
+// Stats returns the statistics of a source and endpoint
+func (t *Tracker) Stats(source, endpoint string) Stats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	k := key{source, endpoint}
+	t.samples[k] = t.prune(t.samples[k])
+	return t.stats(k)
+}
+
+// Score returns the score of a source for an endpoint, between 0 and 1
+func (t *Tracker) Score(source, endpoint string) float64 {
+	return t.Stats(source, endpoint).Score
+}
+
+// Snapshot returns the statistics of every source and endpoint with samples in the window
+func (t *Tracker) Snapshot() []Stats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var snapshot []Stats
+	for k, samples := range t.samples {
+		if samples = t.prune(samples); len(samples) == 0 {
+			delete(t.samples, k)
+			continue
+		}
+		t.samples[k] = samples
+		snapshot = append(snapshot, t.stats(k))
+	}
+
+	sort.Slice(snapshot, func(i, j int) bool {
+		if snapshot[i].Endpoint != snapshot[j].Endpoint {
+			return snapshot[i].Endpoint < snapshot[j].Endpoint
+		}
+		return snapshot[i].Score > snapshot[j].Score
+	})
+	return snapshot
+}
+
+// Order returns the indexes of candidates from the best to the worst score
+// for an endpoint. Candidates with similar scores keep their static priority order.
+func (t *Tracker) Order(endpoint string, candidates []Candidate) []int {
+	buckets := make([]int, len(candidates))
+	order := make([]int, len(candidates))
+	for i, candidate := range candidates {
+		buckets[i] = int(math.Floor(t.Score(candidate.Name, endpoint) / scoreBucket))
+		order[i] = i
+	}
+
+	sort.SliceStable(order, func(a, b int) bool {
+		i, j := order[a], order[b]
+		if buckets[i] != buckets[j] {
+			return buckets[i] > buckets[j]
+		}
+		return candidates[i].Priority < candidates[j].Priority
+	})
+	return order
+}
+
+// prune drops samples older than the window; callers hold mu
+func (t *Tracker) prune(samples []sample) []sample {
+	cutoff := t.now().Add(-t.window)
+	i := 0
+	for i < len(samples) && samples[i].at.Before(cutoff) {
+		i++
+	}
+	return samples[i:]
+}
+
+// stats computes the statistics of pruned samples; callers hold mu
+func (t *Tracker) stats(k key) Stats {
+	samples := t.samples[k]
+	stats := Stats{Source: k.source, Endpoint: k.endpoint, Samples: len(samples), Score: NeutralScore}
+	if len(samples) == 0 {
+		return stats
+	}
+
+	responses, valid := 0, 0
+	latencies := make([]time.Duration, len(samples))
+	for i, s := range samples {
+		latencies[i] = s.latency
+		switch s.outcome {
+		case OutcomeSuccess:
+			responses++
+			valid++
+		case OutcomeInvalid:
+			responses++
+		}
+	}
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	p95 := latencies[int(math.Ceil(0.95*float64(len(latencies))))-1]
+
+	stats.SuccessRate = float64(responses) / float64(len(samples))
+	if responses > 0 {
+		stats.ValidationRate = float64(valid) / float64(responses)
+	}
+	stats.P95LatencyMs = p95.Milliseconds()
+
+	if len(samples) >= t.minSamples {
+		latencyFactor := float64(LatencyScale) / float64(LatencyScale+p95)
+		stats.Score = stats.SuccessRate * stats.ValidationRate * latencyFactor
+	}
+	return stats
+}
@@ -0,0 +1,69 @@
+package adaptive
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScore(t *testing.T) {
+	tracker := New(time.Minute, 4)
+
+	if score := tracker.Score("a", "/api/v1/home"); score != NeutralScore {
+		t.Errorf("Score without samples = %v, want %v", score, NeutralScore)
+	}
+
+	tracker.Observe("a", "/api/v1/home", OutcomeSuccess, time.Second)
+	tracker.Observe("a", "/api/v1/home", OutcomeSuccess, time.Second)
+	tracker.Observe("a", "/api/v1/home", OutcomeInvalid, time.Second)
+	if score := tracker.Score("a", "/api/v1/home"); score != NeutralScore {
+		t.Errorf("Score below the minimum samples = %v, want %v", score, NeutralScore)
+	}
+
+	tracker.Observe("a", "/api/v1/home", OutcomeError, time.Second)
+	stats := tracker.Stats("a", "/api/v1/home")
+	if stats.SuccessRate != 0.75 || stats.P95LatencyMs != 1000 {
+		t.Errorf("Stats = %+v", stats)
+	}
+	// 3/4 responded, 2/3 valid, p95 of 1s halves the score
+	if want := 0.75 * 2.0 / 3.0 * 0.5; stats.Score < want-1e-9 || stats.Score > want+1e-9 {
+		t.Errorf("Score = %v, want %v", stats.Score, want)
+	}
+}
+
+func TestWindowExpiry(t *testing.T) {
+	now := time.Now()
+	tracker := New(time.Minute, 1)
+	tracker.now = func() time.Time { return now }
+
+	tracker.Observe("a", "/x", OutcomeError, 0)
+	now = now.Add(2 * time.Minute)
+	tracker.Observe("a", "/x", OutcomeSuccess, 0)
+
+	if stats := tracker.Stats("a", "/x"); stats.Samples != 1 || stats.Score != 1 {
+		t.Errorf("Stats after expiry = %+v", stats)
+	}
+}
+
+func TestOrder(t *testing.T) {
+	tracker := New(time.Minute, 1)
+	for i := 0; i < 10; i++ {
+		tracker.Observe("slow", "/x", OutcomeSuccess, 3*time.Second)
+		tracker.Observe("fast", "/x", OutcomeSuccess, 10*time.Millisecond)
+		tracker.Observe("also-fast", "/x", OutcomeSuccess, 20*time.Millisecond)
+	}
+
+	candidates := []Candidate{{"slow", 1}, {"also-fast", 3}, {"fast", 2}, {"new", 0}}
+	order := tracker.Order("/x", candidates)
+
+	var names []string
+	for _, i := range order {
+		names = append(names, candidates[i].Name)
+	}
+	// fast and also-fast tie on score and keep priority order; new sources are neutral
+	want := []string{"fast", "also-fast", "new", "slow"}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Fatalf("Order = %v, want %v", names, want)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateToken(t *testing.T) {
+	token1, err := GenerateToken("ak_")
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+	token2, err := GenerateToken("ak_")
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+
+	if !strings.HasPrefix(token1, "ak_") {
+		t.Errorf("Expected token to start with ak_, got %s", token1)
+	}
+	if len(token1) != len("ak_")+tokenBytes*2 {
+		t.Errorf("Unexpected token length %d", len(token1))
+	}
+	if token1 == token2 {
+		t.Errorf("Expected different tokens")
+	}
+}
+
+func TestHashToken(t *testing.T) {
+	hash := HashToken("ak_test")
+
+	if hash != HashToken("ak_test") {
+		t.Errorf("Expected same hash for same token")
+	}
+	if hash == HashToken("ak_other") {
+		t.Errorf("Expected different hash for different token")
+	}
+	if strings.Contains(hash, "ak_test") {
+		t.Errorf("Hash must not contain the token")
+	}
+}
+
+func TestDisplayPrefix(t *testing.T) {
+	if prefix := DisplayPrefix("ak_0123456789abcdef"); prefix != "ak_01234567" {
+		t.Errorf("Expected ak_01234567, got %s", prefix)
+	}
+	if prefix := DisplayPrefix("short"); prefix != "short" {
+		t.Errorf("Expected short, got %s", prefix)
+	}
+}
+
+func TestRoles(t *testing.T) {
+	if !RoleAdmin.Allows(RoleOperator) || !RoleAdmin.Allows(RoleViewer) {
+		t.Error("Expected admin to include operator and viewer")
+	}
+	if !RoleOperator.Allows(RoleViewer) {
+		t.Error("Expected operator to include viewer")
+	}
+	if RoleViewer.Allows(RoleOperator) || RoleOperator.Allows(RoleAdmin) {
+		t.Error("Expected lower roles not to include higher roles")
+	}
+	if Role("guest").Allows(RoleViewer) {
+		t.Error("Expected unknown role to allow nothing")
+	}
+
+	if _, err := ParseRole("operator"); err != nil {
+		t.Errorf("Expected operator to parse, got %v", err)
+	}
+	if _, err := ParseRole("root"); err == nil {
+		t.Error("Expected unknown role to be rejected")
+	}
+}
+
+func TestPassword(t *testing.T) {
+	if _, err := HashPassword("short"); err != ErrPasswordTooShort {
+		t.Errorf("Expected ErrPasswordTooShort, got %v", err)
+	}
+
+	hash, err := HashPassword("correct horse")
+	if err != nil {
+		t.Fatalf("Failed to hash password: %v", err)
+	}
+	if !CheckPassword(hash, "correct horse") {
+		t.Error("Expected password to match its hash")
+	}
+	if CheckPassword(hash, "wrong horse") {
+		t.Error("Expected wrong password not to match")
+	}
+}
@@ -0,0 +1,50 @@
+package auth
+
+import "fmt"
+
+// Role is a dashboard access level. Each role includes the permissions of the roles below it.
+type Role string
+
+const (
+	// RoleViewer can read health, logs, statistics and configuration
+	RoleViewer Role = "viewer"
+	// RoleOperator can additionally create and update configuration and clear the cache
+	RoleOperator Role = "operator"
+	// RoleAdmin can additionally delete configuration and manage users, tokens and API keys
+	RoleAdmin Role = "admin"
+)
+
+var roleLevels = map[Role]int{
+	RoleViewer:   1,
+	RoleOperator: 2,
+	RoleAdmin:    3,
+}
+
+// ParseRole validates a role name
+func ParseRole(name string) (Role, error) {
+	role := Role(name)
+	if _, ok := roleLevels[role]; !ok {
+		return "", fmt.Errorf("unknown role %q (expected viewer, operator or admin)", name)
+	}
+	return role, nil
+}
+
+// Allows reports whether the role grants at least the required access level
+func (r Role) Allows(required Role) bool {
+	level, ok := roleLevels[r]
+	return ok && level >= roleLevels[required]
+}
+
+// Principal kinds
+const (
+	PrincipalUser  = "user"
+	PrincipalToken = "token"
+)
+
+// Principal is an authenticated dashboard user or bearer token
+type Principal struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	Role Role   `json:"role"`
+	Kind string `json:"kind"` // PrincipalUser or PrincipalToken
+}
@@ -0,0 +1,37 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// tokenBytes is the amount of randomness in generated tokens
+const tokenBytes = 32
+
+// displayPrefixLength is how many characters of a token are kept for display
+const displayPrefixLength = 11
+
+// GenerateToken returns a new random token with the given prefix (e.g. "ak_")
+func GenerateToken(prefix string) (string, error) {
+	buf := make([]byte, tokenBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	return prefix + hex.EncodeToString(buf), nil
+}
+
+// HashToken returns the hex sha256 of a token; only the hash is ever stored
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// DisplayPrefix returns the leading characters of a token, safe to show in the dashboard
+func DisplayPrefix(token string) string {
+	if len(token) <= displayPrefixLength {
+		return token
+	}
+	return token[:displayPrefixLength]
+}
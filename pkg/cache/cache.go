@@ -1,8 +1,10 @@
 package cache
 
 import (
+	"bytes"
 	"context"
 	"crypto/md5"
+	"encoding/binary"
 	"encoding/json"
 	"fmt"
 	"sync"
@@ -12,12 +14,36 @@ import (
 )
 
 type Cache interface {
+	// Get returns the value only while it is fresh (before its soft expiry)
 	Get(key string) ([]byte, error)
+	// Set stores a value that is fresh for ttl and then removed
 	Set(key string, value []byte, ttl time.Duration) error
+	// GetEntry returns the entry until its hard expiry, including stale entries
+	GetEntry(key string) (*Entry, error)
+	// SetEntry stores a value that is fresh for softTTL and kept (as stale) until hardTTL
+	SetEntry(key string, value []byte, softTTL, hardTTL time.Duration) error
 	Delete(key string) error
 	GenerateKey(category, endpoint string, params map[string]string) string
 }
 
+// Entry is a cached value with soft and hard expiry
+type Entry struct {
+	Value         []byte
+	StoredAt      time.Time
+	SoftExpiresAt time.Time // After this the entry is stale but may still be served
+	ExpiresAt     time.Time // After this the entry is gone
+}
+
+// IsStale reports whether the entry is past its soft expiry
+func (e *Entry) IsStale() bool {
+	return time.Now().After(e.SoftExpiresAt)
+}
+
+// Age returns how long ago the entry was stored
+func (e *Entry) Age() time.Duration {
+	return time.Since(e.StoredAt)
+}
+
 // RedisCache implements Cache interface using Redis
 type RedisCache struct {
 	client *redis.Client
@@ -31,8 +57,10 @@ type MemoryCache struct {
 }
 
 type cacheItem struct {
-	value     []byte
-	expiresAt time.Time
+	value         []byte
+	storedAt      time.Time
+	softExpiresAt time.Time
+	expiresAt     time.Time
 }
 
 // NewRedisCache creates a new Redis cache instance
@@ -62,18 +90,33 @@ func NewMemoryCache() *MemoryCache {
 
 // Redis Cache Implementation
 func (r *RedisCache) Get(key string) ([]byte, error) {
-	val, err := r.client.Get(r.ctx, key).Result()
+	return getFresh(r, key)
+}
+
+func (r *RedisCache) Set(key string, value []byte, ttl time.Duration) error {
+	return r.SetEntry(key, value, ttl, ttl)
+}
+
+func (r *RedisCache) GetEntry(key string) (*Entry, error) {
+	val, err := r.client.Get(r.ctx, key).Bytes()
 	if err != nil {
 		if err == redis.Nil {
 			return nil, nil // Key not found
 		}
 		return nil, err
 	}
-	return []byte(val), nil
+	return decodeEntry(val), nil
 }
 
-func (r *RedisCache) Set(key string, value []byte, ttl time.Duration) error {
-	return r.client.Set(r.ctx, key, value, ttl).Err()
+func (r *RedisCache) SetEntry(key string, value []byte, softTTL, hardTTL time.Duration) error {
+	now := time.Now()
+	entry := &Entry{
+		Value:         value,
+		StoredAt:      now,
+		SoftExpiresAt: now.Add(softTTL),
+		ExpiresAt:     now.Add(hardTTL),
+	}
+	return r.client.Set(r.ctx, key, encodeEntry(entry), hardTTL).Err()
 }
 
 func (r *RedisCache) Delete(key string) error {
@@ -86,29 +129,51 @@ func (r *RedisCache) GenerateKey(category, endpoint string, params map[string]st
 
 // Memory Cache Implementation
 func (m *MemoryCache) Get(key string) ([]byte, error) {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
+	return getFresh(m, key)
+}
 
+func (m *MemoryCache) Set(key string, value []byte, ttl time.Duration) error {
+	return m.SetEntry(key, value, ttl, ttl)
+}
+
+func (m *MemoryCache) GetEntry(key string) (*Entry, error) {
+	m.mu.RLock()
 	item, exists := m.data[key]
+	m.mu.RUnlock()
+
 	if !exists {
 		return nil, nil // Key not found
 	}
 
 	if time.Now().After(item.expiresAt) {
-		delete(m.data, key)
+		// Only delete the entry seen above: a SetEntry may have replaced it
+		// between the two locks
+		m.mu.Lock()
+		if current, ok := m.data[key]; ok && !time.Now().Before(current.expiresAt) {
+			delete(m.data, key)
+		}
+		m.mu.Unlock()
 		return nil, nil // Expired
 	}
 
-	return item.value, nil
+	return &Entry{
+		Value:         item.value,
+		StoredAt:      item.storedAt,
+		SoftExpiresAt: item.softExpiresAt,
+		ExpiresAt:     item.expiresAt,
+	}, nil
 }
 
-func (m *MemoryCache) Set(key string, value []byte, ttl time.Duration) error {
+func (m *MemoryCache) SetEntry(key string, value []byte, softTTL, hardTTL time.Duration) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	now := time.Now()
 	m.data[key] = cacheItem{
-		value:     value,
-		expiresAt: time.Now().Add(ttl),
+		value:         value,
+		storedAt:      now,
+		softExpiresAt: now.Add(softTTL),
+		expiresAt:     now.Add(hardTTL),
 	}
 
 	return nil
@@ -146,6 +211,53 @@ func (m *MemoryCache) cleanup() {
 	}
 }
 
+// getFresh returns the entry value only if it has not passed its soft expiry
+func getFresh(c Cache, key string) ([]byte, error) {
+	entry, err := c.GetEntry(key)
+	if err != nil || entry == nil {
+		return nil, err
+	}
+	if entry.IsStale() {
+		return nil, nil
+	}
+	return entry.Value, nil
+}
+
+// entryMagic prefixes values stored with expiry metadata in Redis
+var entryMagic = []byte("\x00swr1")
+
+const entryHeaderSize = 8 * 3
+
+// encodeEntry serializes an entry as magic + storedAt + softExpiresAt + expiresAt (unix nanos) + value
+func encodeEntry(e *Entry) []byte {
+	buf := make([]byte, 0, len(entryMagic)+entryHeaderSize+len(e.Value))
+	buf = append(buf, entryMagic...)
+	buf = binary.BigEndian.AppendUint64(buf, uint64(e.StoredAt.UnixNano()))
+	buf = binary.BigEndian.AppendUint64(buf, uint64(e.SoftExpiresAt.UnixNano()))
+	buf = binary.BigEndian.AppendUint64(buf, uint64(e.ExpiresAt.UnixNano()))
+	return append(buf, e.Value...)
+}
+
+// decodeEntry parses an encoded entry; values written before expiry metadata
+// existed are treated as fresh until Redis expires them
+func decodeEntry(data []byte) *Entry {
+	if !bytes.HasPrefix(data, entryMagic) || len(data) < len(entryMagic)+entryHeaderSize {
+		return &Entry{
+			Value:         data,
+			SoftExpiresAt: time.Now().Add(time.Hour),
+			ExpiresAt:     time.Now().Add(time.Hour),
+		}
+	}
+
+	header := data[len(entryMagic):]
+	return &Entry{
+		Value:         header[entryHeaderSize:],
+		StoredAt:      time.Unix(0, int64(binary.BigEndian.Uint64(header[0:8]))),
+		SoftExpiresAt: time.Unix(0, int64(binary.BigEndian.Uint64(header[8:16]))),
+		ExpiresAt:     time.Unix(0, int64(binary.BigEndian.Uint64(header[16:24]))),
+	}
+}
+
 // generateCacheKey creates a cache key from category, endpoint, and parameters
 func generateCacheKey(category, endpoint string, params map[string]string) string {
 	// Create a consistent hash of parameters
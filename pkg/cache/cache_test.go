@@ -134,3 +134,85 @@ func TestNewCache(t *testing.T) {
 		t.Errorf("Expected %s, got %s", string(value), string(retrieved))
 	}
 }
+
+func TestMemoryCacheStaleEntry(t *testing.T) {
+	cache := NewMemoryCache()
+
+	key := "stale_test"
+	value := []byte("stale_value")
+
+	err := cache.SetEntry(key, value, 50*time.Millisecond, 200*time.Millisecond)
+	if err != nil {
+		t.Errorf("Failed to set cache entry: %v", err)
+	}
+
+	entry, err := cache.GetEntry(key)
+	if err != nil || entry == nil {
+		t.Fatalf("Expected entry, got %v (err: %v)", entry, err)
+	}
+	if entry.IsStale() {
+		t.Errorf("Expected fresh entry immediately after set")
+	}
+
+	// Wait for soft expiry
+	time.Sleep(100 * time.Millisecond)
+
+	// Get only returns fresh values
+	retrieved, err := cache.Get(key)
+	if err != nil {
+		t.Errorf("Failed to get cache: %v", err)
+	}
+	if retrieved != nil {
+		t.Errorf("Expected nil from Get after soft expiry, got %s", string(retrieved))
+	}
+
+	// GetEntry still returns the stale entry
+	entry, err = cache.GetEntry(key)
+	if err != nil || entry == nil {
+		t.Fatalf("Expected stale entry, got %v (err: %v)", entry, err)
+	}
+	if !entry.IsStale() {
+		t.Errorf("Expected entry to be stale after soft expiry")
+	}
+	if string(entry.Value) != string(value) {
+		t.Errorf("Expected %s, got %s", string(value), string(entry.Value))
+	}
+
+	// Wait for hard expiry
+	time.Sleep(150 * time.Millisecond)
+
+	entry, err = cache.GetEntry(key)
+	if err != nil {
+		t.Errorf("Failed to get cache entry after expiration: %v", err)
+	}
+	if entry != nil {
+		t.Errorf("Expected nil entry after hard expiry, got %s", string(entry.Value))
+	}
+}
+
+func TestEntryEncoding(t *testing.T) {
+	now := time.Now()
+	entry := &Entry{
+		Value:         []byte(`{"data":[]}`),
+		StoredAt:      now,
+		SoftExpiresAt: now.Add(time.Minute),
+		ExpiresAt:     now.Add(time.Hour),
+	}
+
+	decoded := decodeEntry(encodeEntry(entry))
+	if string(decoded.Value) != string(entry.Value) {
+		t.Errorf("Expected %s, got %s", string(entry.Value), string(decoded.Value))
+	}
+	if !decoded.StoredAt.Equal(entry.StoredAt) || !decoded.SoftExpiresAt.Equal(entry.SoftExpiresAt) || !decoded.ExpiresAt.Equal(entry.ExpiresAt) {
+		t.Errorf("Expected timestamps to round-trip, got %+v", decoded)
+	}
+
+	// Values written before expiry metadata existed decode as fresh
+	legacy := decodeEntry([]byte(`{"legacy":true}`))
+	if string(legacy.Value) != `{"legacy":true}` {
+		t.Errorf("Expected legacy value to be returned as-is, got %s", string(legacy.Value))
+	}
+	if legacy.IsStale() {
+		t.Errorf("Expected legacy value to be treated as fresh")
+	}
+}
@@ -0,0 +1,290 @@
+package circuitbreaker
+
+import (
+	"sync"
+	"time"
+)
+
+// State represents the state of a circuit breaker
+type State string
+
+const (
+	StateClosed   State = "closed"    // Requests flow normally
+	StateOpen     State = "open"      // Requests are rejected until the open timeout elapses
+	StateHalfOpen State = "half-open" // A limited number of probe requests are allowed through
+)
+
+// Settings controls when a breaker trips and how it recovers
+type Settings struct {
+	// FailureThreshold is the number of consecutive failures that trips the breaker.
+	// A value <= 0 disables the breaker (requests are always allowed).
+	FailureThreshold int
+	// OpenTimeout is how long the breaker stays open before allowing probes
+	OpenTimeout time.Duration
+	// HalfOpenMaxProbes is the number of concurrent probe requests allowed in half-open state
+	HalfOpenMaxProbes int
+}
+
+// Transition describes a state change of a breaker
+type Transition struct {
+	ID     int
+	Name   string
+	From   State
+	To     State
+	Reason string
+	At     time.Time
+}
+
+// Snapshot is a point-in-time view of a breaker
+type Snapshot struct {
+	ID                  int       `json:"id"`
+	Name                string    `json:"name"`
+	State               State     `json:"state"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	Trips               int       `json:"trips"`
+	OpenedAt            time.Time `json:"opened_at,omitempty"`
+	LastError           string    `json:"last_error,omitempty"`
+}
+
+// Breaker is a per-source circuit breaker (closed/open/half-open)
+type Breaker struct {
+	id       int
+	name     string
+	settings Settings
+	notify   func(Transition)
+	now      func() time.Time
+
+	mu             sync.Mutex
+	state          State
+	failures       int
+	trips          int
+	openedAt       time.Time
+	probesInFlight int
+	lastError      string
+}
+
+func newBreaker(id int, name string, settings Settings, notify func(Transition), now func() time.Time) *Breaker {
+	if settings.HalfOpenMaxProbes <= 0 {
+		settings.HalfOpenMaxProbes = 1
+	}
+	return &Breaker{
+		id:       id,
+		name:     name,
+		settings: settings,
+		notify:   notify,
+		now:      now,
+		state:    StateClosed,
+	}
+}
+
+// Allow reports whether a request may be sent. In half-open state it admits
+// up to HalfOpenMaxProbes concurrent probes; every admitted request must be
+// followed by RecordSuccess, RecordFailure or Release.
+func (b *Breaker) Allow() bool {
+	if b.settings.FailureThreshold <= 0 {
+		return true
+	}
+
+	b.mu.Lock()
+	var transition *Transition
+
+	allowed := false
+	switch b.state {
+	case StateClosed:
+		allowed = true
+	case StateOpen:
+		if b.now().Sub(b.openedAt) >= b.settings.OpenTimeout {
+			transition = b.setState(StateHalfOpen, "open timeout elapsed, probing")
+			b.probesInFlight = 1
+			allowed = true
+		}
+	case StateHalfOpen:
+		if b.probesInFlight < b.settings.HalfOpenMaxProbes {
+			b.probesInFlight++
+			allowed = true
+		}
+	}
+	b.mu.Unlock()
+
+	b.emit(transition)
+	return allowed
+}
+
+// RecordSuccess records a successful request and closes the breaker if it was probing
+func (b *Breaker) RecordSuccess() {
+	if b.settings.FailureThreshold <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	var transition *Transition
+
+	b.failures = 0
+	b.lastError = ""
+	if b.state == StateHalfOpen {
+		b.probesInFlight = 0
+		transition = b.setState(StateClosed, "probe succeeded")
+	}
+	b.mu.Unlock()
+
+	b.emit(transition)
+}
+
+// RecordFailure records a failed request and trips the breaker when the
+// threshold is reached or when a half-open probe fails
+func (b *Breaker) RecordFailure(reason string) {
+	if b.settings.FailureThreshold <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	var transition *Transition
+
+	b.failures++
+	b.lastError = reason
+	switch b.state {
+	case StateClosed:
+		if b.failures >= b.settings.FailureThreshold {
+			transition = b.trip(reason)
+		}
+	case StateHalfOpen:
+		b.probesInFlight = 0
+		transition = b.trip("probe failed: " + reason)
+	}
+	b.mu.Unlock()
+
+	b.emit(transition)
+}
+
+// Release gives back an admitted request without recording an outcome,
+// e.g. when the request was cancelled before it completed
+func (b *Breaker) Release() {
+	if b.settings.FailureThreshold <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	if b.state == StateHalfOpen && b.probesInFlight > 0 {
+		b.probesInFlight--
+	}
+	b.mu.Unlock()
+}
+
+// Snapshot returns the current state of the breaker
+func (b *Breaker) Snapshot() Snapshot {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return Snapshot{
+		ID:                  b.id,
+		Name:                b.name,
+		State:               b.state,
+		ConsecutiveFailures: b.failures,
+		Trips:               b.trips,
+		OpenedAt:            b.openedAt,
+		LastError:           b.lastError,
+	}
+}
+
+// trip opens the breaker; caller must hold the lock
+func (b *Breaker) trip(reason string) *Transition {
+	b.trips++
+	b.openedAt = b.now()
+	return b.setState(StateOpen, reason)
+}
+
+// setState changes the state and returns the transition; caller must hold the lock
+func (b *Breaker) setState(to State, reason string) *Transition {
+	from := b.state
+	b.state = to
+	return &Transition{
+		ID:     b.id,
+		Name:   b.name,
+		From:   from,
+		To:     to,
+		Reason: reason,
+		At:     b.now(),
+	}
+}
+
+func (b *Breaker) emit(transition *Transition) {
+	if transition != nil && b.notify != nil {
+		b.notify(*transition)
+	}
+}
+
+// Registry holds one breaker per API source ID
+type Registry struct {
+	settings     Settings
+	onTransition func(Transition)
+	now          func() time.Time
+
+	mu       sync.Mutex
+	breakers map[int]*Breaker
+}
+
+// NewRegistry creates a breaker registry; onTransition (optional) is called
+// after every state change, outside of any breaker lock
+func NewRegistry(settings Settings, onTransition func(Transition)) *Registry {
+	return &Registry{
+		settings:     settings,
+		onTransition: onTransition,
+		now:          time.Now,
+		breakers:     make(map[int]*Breaker),
+	}
+}
+
+// Get returns the breaker for an API source, creating it if needed
+func (r *Registry) Get(id int, name string) *Breaker {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if b, exists := r.breakers[id]; exists {
+		return b
+	}
+
+	b := newBreaker(id, name, r.settings, r.onTransition, r.now)
+	r.breakers[id] = b
+	return b
+}
+
+// Restore puts a breaker back into the open state, e.g. after a restart.
+// The open timeout is measured from openedAt.
+func (r *Registry) Restore(id int, name string, openedAt time.Time, reason string) {
+	b := r.Get(id, name)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state = StateOpen
+	b.openedAt = openedAt
+	b.lastError = reason
+}
+
+// Snapshot returns the state of the breaker for an API source, if one exists
+func (r *Registry) Snapshot(id int) (Snapshot, bool) {
+	r.mu.Lock()
+	b, exists := r.breakers[id]
+	r.mu.Unlock()
+
+	if !exists {
+		return Snapshot{}, false
+	}
+	return b.Snapshot(), true
+}
+
+// Snapshots returns the state of all known breakers
+func (r *Registry) Snapshots() []Snapshot {
+	r.mu.Lock()
+	breakers := make([]*Breaker, 0, len(r.breakers))
+	for _, b := range r.breakers {
+		breakers = append(breakers, b)
+	}
+	r.mu.Unlock()
+
+	snapshots := make([]Snapshot, 0, len(breakers))
+	for _, b := range breakers {
+		snapshots = append(snapshots, b.Snapshot())
+	}
+	return snapshots
+}
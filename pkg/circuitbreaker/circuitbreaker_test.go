@@ -0,0 +1,173 @@
+package circuitbreaker
+
+import (
+	"testing"
+	"time"
+)
+
+func newTestRegistry(settings Settings, transitions *[]Transition) (*Registry, *time.Time) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	registry := NewRegistry(settings, func(t Transition) {
+		*transitions = append(*transitions, t)
+	})
+	registry.now = func() time.Time { return now }
+	return registry, &now
+}
+
+func TestBreakerTripsAfterThreshold(t *testing.T) {
+	var transitions []Transition
+	registry, _ := newTestRegistry(Settings{FailureThreshold: 3, OpenTimeout: time.Minute}, &transitions)
+	breaker := registry.Get(1, "samehadaku")
+
+	for i := 0; i < 2; i++ {
+		if !breaker.Allow() {
+			t.Fatalf("Expected request %d to be allowed", i)
+		}
+		breaker.RecordFailure("timeout")
+	}
+
+	if state := breaker.Snapshot().State; state != StateClosed {
+		t.Errorf("Expected closed after 2 failures, got %s", state)
+	}
+
+	breaker.Allow()
+	breaker.RecordFailure("timeout")
+
+	snapshot := breaker.Snapshot()
+	if snapshot.State != StateOpen {
+		t.Errorf("Expected open after 3 failures, got %s", snapshot.State)
+	}
+	if snapshot.Trips != 1 {
+		t.Errorf("Expected 1 trip, got %d", snapshot.Trips)
+	}
+	if breaker.Allow() {
+		t.Error("Expected open breaker to reject requests")
+	}
+	if len(transitions) != 1 || transitions[0].From != StateClosed || transitions[0].To != StateOpen {
+		t.Errorf("Expected a single closed->open transition, got %+v", transitions)
+	}
+}
+
+func TestBreakerSuccessResetsFailures(t *testing.T) {
+	var transitions []Transition
+	registry, _ := newTestRegistry(Settings{FailureThreshold: 2, OpenTimeout: time.Minute}, &transitions)
+	breaker := registry.Get(1, "otakudesu")
+
+	breaker.RecordFailure("status 500")
+	breaker.RecordSuccess()
+	breaker.RecordFailure("status 500")
+
+	if state := breaker.Snapshot().State; state != StateClosed {
+		t.Errorf("Expected closed, got %s", state)
+	}
+}
+
+func TestBreakerHalfOpenProbe(t *testing.T) {
+	var transitions []Transition
+	registry, now := newTestRegistry(Settings{FailureThreshold: 1, OpenTimeout: time.Minute, HalfOpenMaxProbes: 1}, &transitions)
+	breaker := registry.Get(1, "kusonime")
+
+	breaker.RecordFailure("timeout")
+	if breaker.Allow() {
+		t.Fatal("Expected open breaker to reject requests")
+	}
+
+	*now = now.Add(time.Minute)
+
+	if !breaker.Allow() {
+		t.Fatal("Expected a probe to be allowed after the open timeout")
+	}
+	if breaker.Allow() {
+		t.Error("Expected only one concurrent probe in half-open state")
+	}
+	if state := breaker.Snapshot().State; state != StateHalfOpen {
+		t.Errorf("Expected half-open, got %s", state)
+	}
+
+	// A failed probe reopens the breaker
+	breaker.RecordFailure("timeout")
+	if state := breaker.Snapshot().State; state != StateOpen {
+		t.Errorf("Expected open after failed probe, got %s", state)
+	}
+
+	*now = now.Add(time.Minute)
+
+	// A successful probe closes it
+	if !breaker.Allow() {
+		t.Fatal("Expected a probe to be allowed after the open timeout")
+	}
+	breaker.RecordSuccess()
+
+	snapshot := breaker.Snapshot()
+	if snapshot.State != StateClosed {
+		t.Errorf("Expected closed after successful probe, got %s", snapshot.State)
+	}
+	if snapshot.Trips != 2 {
+		t.Errorf("Expected 2 trips, got %d", snapshot.Trips)
+	}
+
+	expected := []State{StateOpen, StateHalfOpen, StateOpen, StateHalfOpen, StateClosed}
+	if len(transitions) != len(expected) {
+		t.Fatalf("Expected %d transitions, got %d", len(expected), len(transitions))
+	}
+	for i, state := range expected {
+		if transitions[i].To != state {
+			t.Errorf("Transition %d: expected %s, got %s", i, state, transitions[i].To)
+		}
+	}
+}
+
+func TestBreakerReleaseFreesProbe(t *testing.T) {
+	var transitions []Transition
+	registry, now := newTestRegistry(Settings{FailureThreshold: 1, OpenTimeout: time.Second}, &transitions)
+	breaker := registry.Get(1, "gomunime")
+
+	breaker.RecordFailure("timeout")
+	*now = now.Add(time.Second)
+
+	if !breaker.Allow() {
+		t.Fatal("Expected probe to be allowed")
+	}
+	breaker.Release()
+
+	if !breaker.Allow() {
+		t.Error("Expected released probe slot to be reusable")
+	}
+}
+
+func TestBreakerDisabled(t *testing.T) {
+	var transitions []Transition
+	registry, _ := newTestRegistry(Settings{FailureThreshold: 0}, &transitions)
+	breaker := registry.Get(1, "winbutv")
+
+	for i := 0; i < 10; i++ {
+		breaker.RecordFailure("timeout")
+	}
+
+	if !breaker.Allow() {
+		t.Error("Expected disabled breaker to always allow requests")
+	}
+	if len(transitions) != 0 {
+		t.Errorf("Expected no transitions, got %d", len(transitions))
+	}
+}
+
+func TestRegistryRestore(t *testing.T) {
+	var transitions []Transition
+	registry, now := newTestRegistry(Settings{FailureThreshold: 3, OpenTimeout: time.Minute}, &transitions)
+
+	registry.Restore(7, "samehadaku", now.Add(-30*time.Second), "timeout")
+
+	snapshot, ok := registry.Snapshot(7)
+	if !ok || snapshot.State != StateOpen {
+		t.Fatalf("Expected restored breaker to be open, got %+v", snapshot)
+	}
+	if registry.Get(7, "samehadaku").Allow() {
+		t.Error("Expected restored breaker to reject requests until the timeout elapses")
+	}
+
+	*now = now.Add(30 * time.Second)
+	if !registry.Get(7, "samehadaku").Allow() {
+		t.Error("Expected restored breaker to allow a probe after the timeout")
+	}
+}
@@ -15,16 +15,74 @@ type Config struct {
 	RedisDB      int
 
 	// API Configuration
-	APITimeout     time.Duration
-	MaxConcurrency int
-	CacheTTL       map[string]time.Duration
+	APITimeout      time.Duration
+	RequestDeadline time.Duration // Budget for the whole fallback chain of one request (0 = no budget)
+	MaxConcurrency  int
+	CacheTTL        map[string]time.Duration
 
-	// Rate Limiting
+	// Stale cache serving: entries are kept for CacheStaleTTL past their TTL
+	CacheStaleTTL             time.Duration
+	CacheStaleWhileRevalidate bool
+	CacheStaleIfError         bool
+
+	// Rate Limiting (anonymous clients are limited per IP, API keys use their own limits)
 	RateLimit       int
 	RateLimitWindow time.Duration
+	APIKeyRequired  bool
 
 	// Health Check
-	HealthCheckInterval time.Duration
+	HealthCheckInterval  time.Duration
+	HealthRoutingEnabled bool          // Try sources failing their latest health check last
+	HealthRoutingMaxAge  time.Duration // Health checks older than this are ignored for routing
+
+	// Database maintenance: request logs are rolled up hourly and daily, and
+	// rows older than their retention are pruned (0 keeps them forever)
+	MaintenanceInterval   time.Duration // How often rollups are refreshed and old rows pruned; 0 disables
+	RequestLogRetention   time.Duration
+	HealthCheckRetention  time.Duration // Also bounds the hourly source outcomes of uptime reports
+	HourlyRollupRetention time.Duration
+	DailyRollupRetention  time.Duration
+
+	// Circuit Breaker (per API source)
+	CircuitBreakerThreshold      int
+	CircuitBreakerOpenTimeout    time.Duration
+	CircuitBreakerHalfOpenProbes int
+
+	// Dashboard authentication
+	DashboardAuthEnabled bool
+	DashboardSessionTTL  time.Duration
+	AdminUsername        string // Initial admin, created when no dashboard users exist
+	AdminPassword        string // Generated and logged once when empty
+
+	// Prometheus metrics (/metrics is open when the token is empty)
+	MetricsToken string
+
+	// OpenTelemetry tracing (exporter endpoint via OTEL_EXPORTER_OTLP_ENDPOINT)
+	TracingEnabled     bool
+	TracingServiceName string
+	TracingSampleRatio float64
+
+	// Field-level merge of detail responses from several sources
+	DetailMergeEnabled    bool
+	DetailMergeWait       time.Duration     // How long to wait for more sources after the first valid response
+	DetailMergeStrategies map[string]string // Field name -> merge strategy, overriding the defaults
+
+	// Deduplication of aggregated list items
+	DedupMatcher       string   // exact or fuzzy
+	DedupThreshold     float64  // Minimum title similarity for fuzzy matches
+	DedupTitleSuffixes []string // Stripped from titles before comparing; nil uses the defaults
+
+	// Adaptive source ordering from recent success rate, validation rate and p95 latency
+	AdaptiveOrderingEnabled bool
+	AdaptiveWindow          time.Duration // How far back requests are scored
+	AdaptiveMinSamples      int           // Requests needed before a source is scored
+	AdaptiveWait            time.Duration // How long a valid detail response waits for better-ranked sources
+
+	// Hedged list fan-outs by endpoint path: answer before every source has responded
+	HedgePolicies map[string]HedgePolicy
+
+	// Directory of JSON Schema files overriding the built-in response schemas
+	SchemaDir string
 
 	// Dynamic API Sources Configuration
 	// This allows unlimited API sources to be configured via environment variables
@@ -39,13 +97,59 @@ func Load() *Config {
 		RedisAddr:    getEnv("REDIS_ADDR", "localhost:6379"),
 		RedisDB:      getEnvInt("REDIS_DB", 0),
 
-		APITimeout:     getEnvDuration("API_TIMEOUT", 20*time.Second),
-		MaxConcurrency: getEnvInt("MAX_CONCURRENCY", 10),
+		APITimeout:      getEnvDuration("API_TIMEOUT", 20*time.Second),
+		RequestDeadline: getEnvDuration("REQUEST_DEADLINE", 45*time.Second),
+		MaxConcurrency:  getEnvInt("MAX_CONCURRENCY", 10),
+
+		CacheStaleTTL:             getEnvDuration("CACHE_STALE_TTL", time.Hour),
+		CacheStaleWhileRevalidate: getEnvBool("CACHE_STALE_WHILE_REVALIDATE", true),
+		CacheStaleIfError:         getEnvBool("CACHE_STALE_IF_ERROR", true),
 
 		RateLimit:       getEnvInt("RATE_LIMIT", 100),
 		RateLimitWindow: getEnvDuration("RATE_LIMIT_WINDOW", time.Minute),
+		APIKeyRequired:  getEnvBool("API_KEY_REQUIRED", false),
+
+		HealthCheckInterval:  getEnvDuration("HEALTH_CHECK_INTERVAL", 10*time.Minute),
+		HealthRoutingEnabled: getEnvBool("HEALTH_ROUTING_ENABLED", true),
+		HealthRoutingMaxAge:  getEnvDuration("HEALTH_ROUTING_MAX_AGE", 30*time.Minute),
+
+		MaintenanceInterval:   getEnvDuration("MAINTENANCE_INTERVAL", time.Hour),
+		RequestLogRetention:   getEnvDuration("REQUEST_LOG_RETENTION", 7*24*time.Hour),
+		HealthCheckRetention:  getEnvDuration("HEALTH_CHECK_RETENTION", 35*24*time.Hour),
+		HourlyRollupRetention: getEnvDuration("HOURLY_ROLLUP_RETENTION", 30*24*time.Hour),
+		DailyRollupRetention:  getEnvDuration("DAILY_ROLLUP_RETENTION", 365*24*time.Hour),
 
-		HealthCheckInterval: getEnvDuration("HEALTH_CHECK_INTERVAL", 10*time.Minute),
+		CircuitBreakerThreshold:      getEnvInt("CIRCUIT_BREAKER_THRESHOLD", 5),
+		CircuitBreakerOpenTimeout:    getEnvDuration("CIRCUIT_BREAKER_OPEN_TIMEOUT", time.Minute),
+		CircuitBreakerHalfOpenProbes: getEnvInt("CIRCUIT_BREAKER_HALF_OPEN_PROBES", 1),
+
+		DashboardAuthEnabled: getEnvBool("DASHBOARD_AUTH_ENABLED", true),
+		DashboardSessionTTL:  getEnvDuration("DASHBOARD_SESSION_TTL", 24*time.Hour),
+		AdminUsername:        getEnv("ADMIN_USERNAME", "admin"),
+		AdminPassword:        os.Getenv("ADMIN_PASSWORD"),
+
+		MetricsToken: os.Getenv("METRICS_TOKEN"),
+
+		TracingEnabled:     getEnvBool("TRACING_ENABLED", false),
+		TracingServiceName: getEnv("OTEL_SERVICE_NAME", "apicategorywithfallback"),
+		TracingSampleRatio: getEnvFloat("TRACING_SAMPLE_RATIO", 1.0),
+
+		DetailMergeEnabled:    getEnvBool("DETAIL_MERGE_ENABLED", false),
+		DetailMergeWait:       getEnvDuration("DETAIL_MERGE_WAIT", 3*time.Second),
+		DetailMergeStrategies: getEnvStringMap("DETAIL_MERGE_STRATEGIES_JSON"),
+
+		DedupMatcher:       getEnv("DEDUP_MATCHER", "fuzzy"),
+		DedupThreshold:     getEnvFloat("DEDUP_SIMILARITY_THRESHOLD", 0.85),
+		DedupTitleSuffixes: getEnvList("DEDUP_TITLE_SUFFIXES"),
+
+		AdaptiveOrderingEnabled: getEnvBool("ADAPTIVE_ORDERING_ENABLED", false),
+		AdaptiveWindow:          getEnvDuration("ADAPTIVE_WINDOW", 5*time.Minute),
+		AdaptiveMinSamples:      getEnvInt("ADAPTIVE_MIN_SAMPLES", 5),
+		AdaptiveWait:            getEnvDuration("ADAPTIVE_WAIT", 500*time.Millisecond),
+
+		HedgePolicies: getEnvHedgePolicies("HEDGE_POLICIES_JSON"),
+
+		SchemaDir: os.Getenv("SCHEMA_DIR"),
 
 		// Load dynamic API sources
 		APISources: loadAPISources(),
@@ -81,6 +185,24 @@ func getEnvInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+func getEnvBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return defaultValue
+}
+
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatValue
+		}
+	}
+	return defaultValue
+}
+
 func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
 	if value := os.Getenv(key); value != "" {
 		if duration, err := time.ParseDuration(value); err == nil {
@@ -90,6 +212,75 @@ func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
 	return defaultValue
 }
 
+// getEnvList splits a comma-separated list, returning nil when unset
+func getEnvList(key string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return nil
+	}
+	var values []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.ToLower(strings.TrimSpace(item)); item != "" {
+			values = append(values, item)
+		}
+	}
+	return values
+}
+
+// getEnvStringMap parses a JSON object of strings, returning nil when unset or invalid
+func getEnvStringMap(key string) map[string]string {
+	value := os.Getenv(key)
+	if value == "" {
+		return nil
+	}
+	var values map[string]string
+	if err := json.Unmarshal([]byte(value), &values); err != nil {
+		return nil
+	}
+	return values
+}
+
+// HedgePolicy ends the fan-out of a list endpoint once MinSources sources
+// returned valid data, or once Budget has passed and at least one has
+type HedgePolicy struct {
+	MinSources int           // 0 waits for every source
+	Budget     time.Duration // 0 has no latency budget
+}
+
+// getEnvHedgePolicies parses a JSON object of endpoint path to
+// {"min_sources": 2, "budget": "800ms"}, skipping invalid policies and
+// returning nil when unset or invalid
+func getEnvHedgePolicies(key string) map[string]HedgePolicy {
+	value := os.Getenv(key)
+	if value == "" {
+		return nil
+	}
+	var raw map[string]struct {
+		MinSources int    `json:"min_sources"`
+		Budget     string `json:"budget"`
+	}
+	if err := json.Unmarshal([]byte(value), &raw); err != nil {
+		return nil
+	}
+
+	policies := make(map[string]HedgePolicy, len(raw))
+	for endpoint, policy := range raw {
+		var budget time.Duration
+		if policy.Budget != "" {
+			parsed, err := time.ParseDuration(policy.Budget)
+			if err != nil || parsed < 0 {
+				continue
+			}
+			budget = parsed
+		}
+		if policy.MinSources < 0 || (policy.MinSources == 0 && budget == 0) {
+			continue
+		}
+		policies[strings.TrimSuffix(endpoint, "/")] = HedgePolicy{MinSources: policy.MinSources, Budget: budget}
+	}
+	return policies
+}
+
 // loadAPISources loads API sources dynamically from environment variables
 // Supports multiple formats:
 // 1. API_SOURCES_JSON: JSON string with all sources
@@ -34,6 +34,11 @@ func TestLoadConfig(t *testing.T) {
 	if cfg.RateLimit != 100 {
 		t.Errorf("Expected default rate limit 100, got %d", cfg.RateLimit)
 	}
+
+	if cfg.RequestLogRetention != 7*24*time.Hour || cfg.HealthCheckRetention != 35*24*time.Hour {
+		t.Errorf("Expected default retention 168h for request logs and 840h for health checks, got %v and %v",
+			cfg.RequestLogRetention, cfg.HealthCheckRetention)
+	}
 }
 
 func TestLoadConfigWithEnvVars(t *testing.T) {
@@ -88,3 +93,18 @@ func TestCacheTTLConfiguration(t *testing.T) {
 		}
 	}
 }
+
+func TestHedgePolicies(t *testing.T) {
+	os.Setenv("HEDGE_POLICIES_JSON", `{"/api/v1/home/": {"min_sources": 2, "budget": "800ms"}, "/api/v1/movie": {"budget": "oops"}, "/api/v1/search": {}}`)
+	defer os.Unsetenv("HEDGE_POLICIES_JSON")
+
+	cfg := Load()
+
+	if len(cfg.HedgePolicies) != 1 {
+		t.Fatalf("Expected 1 valid hedge policy, got %v", cfg.HedgePolicies)
+	}
+	policy := cfg.HedgePolicies["/api/v1/home"]
+	if policy.MinSources != 2 || policy.Budget != 800*time.Millisecond {
+		t.Errorf("Expected min_sources 2 and budget 800ms, got %+v", policy)
+	}
+}
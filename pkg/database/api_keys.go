@@ -0,0 +1,143 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+)
+
+// APIKey represents a client API key with its own rate limit and daily quota
+type APIKey struct {
+	ID         int    `json:"id"`
+	Name       string `json:"name"`
+	KeyPrefix  string `json:"key_prefix"`
+	RateLimit  int    `json:"rate_limit"`  // Requests per minute, 0 = unlimited
+	DailyQuota int    `json:"daily_quota"` // Requests per UTC day, 0 = unlimited
+	IsActive   bool   `json:"is_active"`
+	UsageToday int    `json:"usage_today"`
+	CreatedAt  string `json:"created_at"`
+}
+
+// CreateAPIKey stores a new API key; only the hash of the key is persisted
+func (db *DB) CreateAPIKey(name, keyHash, keyPrefix string, rateLimit, dailyQuota int) (*APIKey, error) {
+	query := `INSERT INTO api_keys (name, key_hash, key_prefix, rate_limit, daily_quota, is_active) VALUES (?, ?, ?, ?, ?, TRUE)`
+	result, err := db.Exec(query, name, keyHash, keyPrefix, rateLimit, dailyQuota)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	return &APIKey{
+		ID:         int(id),
+		Name:       name,
+		KeyPrefix:  keyPrefix,
+		RateLimit:  rateLimit,
+		DailyQuota: dailyQuota,
+		IsActive:   true,
+	}, nil
+}
+
+// GetAPIKeyByHash returns the API key with the given hash, or nil if it does not exist
+func (db *DB) GetAPIKeyByHash(keyHash string) (*APIKey, error) {
+	query := `
+		SELECT id, name, key_prefix, rate_limit, daily_quota, is_active, created_at
+		FROM api_keys
+		WHERE key_hash = ?
+	`
+
+	var key APIKey
+	err := db.QueryRow(query, keyHash).Scan(&key.ID, &key.Name, &key.KeyPrefix, &key.RateLimit, &key.DailyQuota, &key.IsActive, &key.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &key, nil
+}
+
+// GetAllAPIKeys returns all API keys with today's usage
+func (db *DB) GetAllAPIKeys() ([]APIKey, error) {
+	query := `
+		SELECT k.id, k.name, k.key_prefix, k.rate_limit, k.daily_quota, k.is_active,
+			COALESCE(u.request_count, 0), k.created_at
+		FROM api_keys k
+		LEFT JOIN api_key_usage u ON u.api_key_id = k.id AND u.day = ?
+		ORDER BY k.name
+	`
+
+	rows, err := db.Query(query, usageDay(time.Now()))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []APIKey
+	for rows.Next() {
+		var key APIKey
+		err := rows.Scan(&key.ID, &key.Name, &key.KeyPrefix, &key.RateLimit, &key.DailyQuota, &key.IsActive, &key.UsageToday, &key.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+// UpdateAPIKey updates an API key's name, limits and status
+func (db *DB) UpdateAPIKey(id int, name string, rateLimit, dailyQuota int, isActive bool) error {
+	query := `UPDATE api_keys SET name = ?, rate_limit = ?, daily_quota = ?, is_active = ?, updated_at = datetime('now') WHERE id = ?`
+	_, err := db.Exec(query, name, rateLimit, dailyQuota, isActive, id)
+	return err
+}
+
+// DeleteAPIKey deletes an API key and its usage history
+func (db *DB) DeleteAPIKey(id int) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM api_key_usage WHERE api_key_id = ?`, id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM api_keys WHERE id = ?`, id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ConsumeAPIKeyQuota counts one request against today's quota of an API key.
+// It returns the number of requests used today and false (without counting)
+// when the quota is already exhausted. A quota <= 0 is unlimited.
+func (db *DB) ConsumeAPIKeyQuota(apiKeyID, dailyQuota int) (int, bool, error) {
+	query := `
+		INSERT INTO api_key_usage (api_key_id, day, request_count) VALUES (?, ?, 1)
+		ON CONFLICT (api_key_id, day) DO UPDATE SET request_count = request_count + 1
+		WHERE ? <= 0 OR request_count < ?
+		RETURNING request_count
+	`
+
+	var used int
+	err := db.QueryRow(query, apiKeyID, usageDay(time.Now()), dailyQuota, dailyQuota).Scan(&used)
+	if err == sql.ErrNoRows {
+		return dailyQuota, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+
+	return used, true, nil
+}
+
+// usageDay returns the quota bucket (UTC date) for a point in time
+func usageDay(t time.Time) string {
+	return t.UTC().Format("2006-01-02")
+}
@@ -0,0 +1,232 @@
+package database
+
+import (
+	"database/sql"
+	"sort"
+)
+
+// CanonicalTitle groups the native slugs sources use for the same anime
+type CanonicalTitle struct {
+	ID          int               `json:"id"`
+	CanonicalID string            `json:"canonical_id"`
+	Title       string            `json:"title"`
+	IsManual    bool              `json:"is_manual"` // Edited from the dashboard
+	Slugs       map[string]string `json:"slugs"`     // Source name -> native slug
+}
+
+// GetCanonicalTitles returns all canonical titles with their source slugs
+func (db *DB) GetCanonicalTitles() ([]CanonicalTitle, error) {
+	rows, err := db.Query(`SELECT id, canonical_id, title, is_manual FROM canonical_titles ORDER BY canonical_id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	titles := []CanonicalTitle{}
+	index := make(map[int]int)
+	for rows.Next() {
+		title := CanonicalTitle{Slugs: map[string]string{}}
+		if err := rows.Scan(&title.ID, &title.CanonicalID, &title.Title, &title.IsManual); err != nil {
+			return nil, err
+		}
+		index[title.ID] = len(titles)
+		titles = append(titles, title)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	slugRows, err := db.Query(`SELECT canonical_title_id, source_name, slug FROM source_slugs`)
+	if err != nil {
+		return nil, err
+	}
+	defer slugRows.Close()
+
+	for slugRows.Next() {
+		var titleID int
+		var sourceName, slug string
+		if err := slugRows.Scan(&titleID, &sourceName, &slug); err != nil {
+			return nil, err
+		}
+		if i, exists := index[titleID]; exists {
+			titles[i].Slugs[sourceName] = slug
+		}
+	}
+
+	return titles, slugRows.Err()
+}
+
+// GetCanonicalTitle returns the canonical title with the given ID, or nil if it does not exist
+func (db *DB) GetCanonicalTitle(id int) (*CanonicalTitle, error) {
+	title := CanonicalTitle{Slugs: map[string]string{}}
+	err := db.QueryRow(`SELECT id, canonical_id, title, is_manual FROM canonical_titles WHERE id = ?`, id).
+		Scan(&title.ID, &title.CanonicalID, &title.Title, &title.IsManual)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(`SELECT source_name, slug FROM source_slugs WHERE canonical_title_id = ?`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var sourceName, slug string
+		if err := rows.Scan(&sourceName, &slug); err != nil {
+			return nil, err
+		}
+		title.Slugs[sourceName] = slug
+	}
+
+	return &title, rows.Err()
+}
+
+// CreateCanonicalTitle stores a new canonical title with its slugs and sets its ID
+func (db *DB) CreateCanonicalTitle(title *CanonicalTitle) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`INSERT INTO canonical_titles (canonical_id, title, is_manual) VALUES (?, ?, ?)`,
+		title.CanonicalID, title.Title, title.IsManual)
+	if err != nil {
+		return err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	if _, err := insertSourceSlugs(tx, int(id), title.Slugs, false); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	title.ID = int(id)
+	return nil
+}
+
+// UpdateCanonicalTitle replaces a canonical title and its slugs
+func (db *DB) UpdateCanonicalTitle(title *CanonicalTitle) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE canonical_titles SET canonical_id = ?, title = ?, is_manual = ?, updated_at = datetime('now') WHERE id = ?`
+	if _, err := tx.Exec(query, title.CanonicalID, title.Title, title.IsManual, title.ID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM source_slugs WHERE canonical_title_id = ?`, title.ID); err != nil {
+		return err
+	}
+	if _, err := insertSourceSlugs(tx, title.ID, title.Slugs, false); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// DeleteCanonicalTitle deletes a canonical title and its slugs
+func (db *DB) DeleteCanonicalTitle(id int) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM source_slugs WHERE canonical_title_id = ?`, id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM canonical_titles WHERE id = ?`, id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// RecordSourceSlugs links slugs that several sources use for the same title.
+// The slugs join the canonical title one of them already belongs to, or a new
+// one named canonicalID; sources that already have a slug for the title keep it.
+// It reports whether anything was stored.
+func (db *DB) RecordSourceSlugs(canonicalID, title string, slugs map[string]string) (bool, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	values := make([]interface{}, 0, len(slugs)+1)
+	placeholders := "?"
+	values = append(values, canonicalID)
+	for _, slug := range slugs {
+		placeholders += ", ?"
+		values = append(values, slug)
+	}
+
+	var titleID int
+	created := false
+	query := `
+		SELECT id FROM canonical_titles WHERE canonical_id IN (` + placeholders + `)
+		UNION ALL
+		SELECT canonical_title_id FROM source_slugs WHERE slug IN (` + placeholders + `)
+		LIMIT 1
+	`
+	err = tx.QueryRow(query, append(values, values...)...).Scan(&titleID)
+	if err == sql.ErrNoRows {
+		result, err := tx.Exec(`INSERT INTO canonical_titles (canonical_id, title) VALUES (?, ?)`, canonicalID, title)
+		if err != nil {
+			return false, err
+		}
+		id, err := result.LastInsertId()
+		if err != nil {
+			return false, err
+		}
+		titleID = int(id)
+		created = true
+	} else if err != nil {
+		return false, err
+	}
+
+	inserted, err := insertSourceSlugs(tx, titleID, slugs, true)
+	if err != nil {
+		return false, err
+	}
+	return created || inserted > 0, tx.Commit()
+}
+
+// insertSourceSlugs stores slugs in source name order and returns how many
+// were inserted; ignoreExisting keeps the slugs sources already have for the title
+func insertSourceSlugs(tx *sql.Tx, titleID int, slugs map[string]string, ignoreExisting bool) (int, error) {
+	query := `INSERT INTO source_slugs (canonical_title_id, source_name, slug) VALUES (?, ?, ?)`
+	if ignoreExisting {
+		query = `INSERT OR IGNORE INTO source_slugs (canonical_title_id, source_name, slug) VALUES (?, ?, ?)`
+	}
+
+	sources := make([]string, 0, len(slugs))
+	for sourceName := range slugs {
+		sources = append(sources, sourceName)
+	}
+	sort.Strings(sources)
+
+	inserted := 0
+	for _, sourceName := range sources {
+		result, err := tx.Exec(query, titleID, sourceName, slugs[sourceName])
+		if err != nil {
+			return inserted, err
+		}
+		if affected, err := result.RowsAffected(); err == nil {
+			inserted += int(affected)
+		}
+	}
+	return inserted, nil
+}
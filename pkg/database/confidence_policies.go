@@ -0,0 +1,90 @@
+package database
+
+import "database/sql"
+
+// ConfidencePolicy is the minimum confidence score for an endpoint and source.
+// "*" in EndpointPath or SourceName matches every endpoint or source.
+type ConfidencePolicy struct {
+	ID           int     `json:"id"`
+	EndpointPath string  `json:"endpoint_path"`
+	SourceName   string  `json:"source_name"`
+	MinScore     float64 `json:"min_score"`
+	MissingScore string  `json:"missing_score"` // reject, assume or derive
+	AssumedScore float64 `json:"assumed_score"` // Used by "assume"
+	IsActive     bool    `json:"is_active"`
+}
+
+const confidencePolicyColumns = `id, endpoint_path, source_name, min_score, missing_score, assumed_score, is_active`
+
+// GetConfidencePolicies returns all confidence policies
+func (db *DB) GetConfidencePolicies() ([]ConfidencePolicy, error) {
+	rows, err := db.Query(`SELECT ` + confidencePolicyColumns + ` FROM confidence_policies ORDER BY endpoint_path, source_name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	policies := []ConfidencePolicy{}
+	for rows.Next() {
+		policy, err := scanConfidencePolicy(rows)
+		if err != nil {
+			return nil, err
+		}
+		policies = append(policies, *policy)
+	}
+
+	return policies, rows.Err()
+}
+
+// GetConfidencePolicy returns the confidence policy with the given ID, or nil if it does not exist
+func (db *DB) GetConfidencePolicy(id int) (*ConfidencePolicy, error) {
+	policy, err := scanConfidencePolicy(db.QueryRow(`SELECT `+confidencePolicyColumns+` FROM confidence_policies WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return policy, err
+}
+
+// CreateConfidencePolicy stores a new confidence policy and sets its ID
+func (db *DB) CreateConfidencePolicy(policy *ConfidencePolicy) error {
+	query := `
+		INSERT INTO confidence_policies (endpoint_path, source_name, min_score, missing_score, assumed_score, is_active)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+	result, err := db.Exec(query, policy.EndpointPath, policy.SourceName, policy.MinScore, policy.MissingScore, policy.AssumedScore, policy.IsActive)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	policy.ID = int(id)
+	return nil
+}
+
+// UpdateConfidencePolicy replaces a confidence policy
+func (db *DB) UpdateConfidencePolicy(policy *ConfidencePolicy) error {
+	query := `
+		UPDATE confidence_policies
+		SET endpoint_path = ?, source_name = ?, min_score = ?, missing_score = ?, assumed_score = ?, is_active = ?, updated_at = datetime('now')
+		WHERE id = ?
+	`
+	_, err := db.Exec(query, policy.EndpointPath, policy.SourceName, policy.MinScore, policy.MissingScore, policy.AssumedScore, policy.IsActive, policy.ID)
+	return err
+}
+
+// DeleteConfidencePolicy deletes a confidence policy
+func (db *DB) DeleteConfidencePolicy(id int) error {
+	_, err := db.Exec(`DELETE FROM confidence_policies WHERE id = ?`, id)
+	return err
+}
+
+func scanConfidencePolicy(row rowScanner) (*ConfidencePolicy, error) {
+	var policy ConfidencePolicy
+	if err := row.Scan(&policy.ID, &policy.EndpointPath, &policy.SourceName, &policy.MinScore, &policy.MissingScore, &policy.AssumedScore, &policy.IsActive); err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
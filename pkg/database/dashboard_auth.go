@@ -0,0 +1,301 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+)
+
+// DashboardUser is a person who can log in to the dashboard
+type DashboardUser struct {
+	ID           int    `json:"id"`
+	Username     string `json:"username"`
+	PasswordHash string `json:"-"`
+	Role         string `json:"role"`
+	IsActive     bool   `json:"is_active"`
+	CreatedAt    string `json:"created_at"`
+}
+
+// DashboardToken is a bearer token for scripted access to the dashboard JSON API
+type DashboardToken struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	TokenPrefix string `json:"token_prefix"`
+	Role        string `json:"role"`
+	LastUsedAt  string `json:"last_used_at,omitempty"`
+	CreatedAt   string `json:"created_at"`
+}
+
+// CountDashboardUsers returns the number of dashboard users
+func (db *DB) CountDashboardUsers() (int, error) {
+	var count int
+	err := db.QueryRow(`SELECT COUNT(*) FROM dashboard_users`).Scan(&count)
+	return count, err
+}
+
+// CountActiveAdmins returns the number of active admin users other than excludeID
+func (db *DB) CountActiveAdmins(excludeID int) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM dashboard_users WHERE role = 'admin' AND is_active = TRUE AND id != ?`
+	err := db.QueryRow(query, excludeID).Scan(&count)
+	return count, err
+}
+
+// CreateDashboardUser creates a new dashboard user
+func (db *DB) CreateDashboardUser(username, passwordHash, role string) (*DashboardUser, error) {
+	query := `INSERT INTO dashboard_users (username, password_hash, role, is_active) VALUES (?, ?, ?, TRUE)`
+	result, err := db.Exec(query, username, passwordHash, role)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	return &DashboardUser{
+		ID:       int(id),
+		Username: username,
+		Role:     role,
+		IsActive: true,
+	}, nil
+}
+
+// GetDashboardUserByUsername returns the user with the given username, or nil if it does not exist
+func (db *DB) GetDashboardUserByUsername(username string) (*DashboardUser, error) {
+	query := `
+		SELECT id, username, password_hash, role, is_active, created_at
+		FROM dashboard_users
+		WHERE username = ?
+	`
+
+	var user DashboardUser
+	err := db.QueryRow(query, username).Scan(&user.ID, &user.Username, &user.PasswordHash, &user.Role, &user.IsActive, &user.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// GetDashboardUser returns the user with the given ID, or nil if it does not exist
+func (db *DB) GetDashboardUser(id int) (*DashboardUser, error) {
+	query := `
+		SELECT id, username, password_hash, role, is_active, created_at
+		FROM dashboard_users
+		WHERE id = ?
+	`
+
+	var user DashboardUser
+	err := db.QueryRow(query, id).Scan(&user.ID, &user.Username, &user.PasswordHash, &user.Role, &user.IsActive, &user.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// GetAllDashboardUsers returns all dashboard users
+func (db *DB) GetAllDashboardUsers() ([]DashboardUser, error) {
+	query := `SELECT id, username, role, is_active, created_at FROM dashboard_users ORDER BY username`
+
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []DashboardUser
+	for rows.Next() {
+		var user DashboardUser
+		if err := rows.Scan(&user.ID, &user.Username, &user.Role, &user.IsActive, &user.CreatedAt); err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+
+	return users, nil
+}
+
+// UpdateDashboardUser updates a user's role and status. Deactivated users lose their sessions.
+func (db *DB) UpdateDashboardUser(id int, role string, isActive bool) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE dashboard_users SET role = ?, is_active = ?, updated_at = datetime('now') WHERE id = ?`
+	if _, err := tx.Exec(query, role, isActive, id); err != nil {
+		return err
+	}
+	if !isActive {
+		if _, err := tx.Exec(`DELETE FROM dashboard_sessions WHERE user_id = ?`, id); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// UpdateDashboardUserPassword replaces a user's password hash and ends their sessions
+func (db *DB) UpdateDashboardUserPassword(id int, passwordHash string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE dashboard_users SET password_hash = ?, updated_at = datetime('now') WHERE id = ?`
+	if _, err := tx.Exec(query, passwordHash, id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM dashboard_sessions WHERE user_id = ?`, id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// DeleteDashboardUser deletes a user and their sessions
+func (db *DB) DeleteDashboardUser(id int) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM dashboard_sessions WHERE user_id = ?`, id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM dashboard_users WHERE id = ?`, id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// CreateDashboardSession stores a login session; only the hash of the session token is persisted
+func (db *DB) CreateDashboardSession(tokenHash string, userID int, expiresAt time.Time) error {
+	query := `INSERT INTO dashboard_sessions (token_hash, user_id, expires_at) VALUES (?, ?, ?)`
+	_, err := db.Exec(query, tokenHash, userID, expiresAt.Unix())
+	return err
+}
+
+// GetDashboardSessionUser returns the active user of an unexpired session, or nil
+func (db *DB) GetDashboardSessionUser(tokenHash string) (*DashboardUser, error) {
+	query := `
+		SELECT u.id, u.username, u.role, u.is_active, u.created_at
+		FROM dashboard_sessions s
+		JOIN dashboard_users u ON u.id = s.user_id
+		WHERE s.token_hash = ? AND s.expires_at > ? AND u.is_active = TRUE
+	`
+
+	var user DashboardUser
+	err := db.QueryRow(query, tokenHash, time.Now().Unix()).Scan(&user.ID, &user.Username, &user.Role, &user.IsActive, &user.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// DeleteDashboardSession ends a login session
+func (db *DB) DeleteDashboardSession(tokenHash string) error {
+	_, err := db.Exec(`DELETE FROM dashboard_sessions WHERE token_hash = ?`, tokenHash)
+	return err
+}
+
+// DeleteExpiredDashboardSessions removes sessions past their expiry
+func (db *DB) DeleteExpiredDashboardSessions() error {
+	_, err := db.Exec(`DELETE FROM dashboard_sessions WHERE expires_at <= ?`, time.Now().Unix())
+	return err
+}
+
+// CreateDashboardToken stores a new bearer token; only the hash of the token is persisted
+func (db *DB) CreateDashboardToken(name, tokenHash, tokenPrefix, role string) (*DashboardToken, error) {
+	query := `INSERT INTO dashboard_tokens (name, token_hash, token_prefix, role) VALUES (?, ?, ?, ?)`
+	result, err := db.Exec(query, name, tokenHash, tokenPrefix, role)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	return &DashboardToken{
+		ID:          int(id),
+		Name:        name,
+		TokenPrefix: tokenPrefix,
+		Role:        role,
+	}, nil
+}
+
+// GetDashboardTokenByHash returns the token with the given hash, or nil if it does not exist
+func (db *DB) GetDashboardTokenByHash(tokenHash string) (*DashboardToken, error) {
+	query := `
+		SELECT id, name, token_prefix, role, COALESCE(last_used_at, ''), created_at
+		FROM dashboard_tokens
+		WHERE token_hash = ?
+	`
+
+	var token DashboardToken
+	err := db.QueryRow(query, tokenHash).Scan(&token.ID, &token.Name, &token.TokenPrefix, &token.Role, &token.LastUsedAt, &token.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// GetAllDashboardTokens returns all bearer tokens
+func (db *DB) GetAllDashboardTokens() ([]DashboardToken, error) {
+	query := `
+		SELECT id, name, token_prefix, role, COALESCE(last_used_at, ''), created_at
+		FROM dashboard_tokens
+		ORDER BY name
+	`
+
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tokens []DashboardToken
+	for rows.Next() {
+		var token DashboardToken
+		if err := rows.Scan(&token.ID, &token.Name, &token.TokenPrefix, &token.Role, &token.LastUsedAt, &token.CreatedAt); err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, token)
+	}
+
+	return tokens, nil
+}
+
+// TouchDashboardToken records that a bearer token was used
+func (db *DB) TouchDashboardToken(id int) error {
+	_, err := db.Exec(`UPDATE dashboard_tokens SET last_used_at = datetime('now') WHERE id = ?`, id)
+	return err
+}
+
+// DeleteDashboardToken revokes a bearer token
+func (db *DB) DeleteDashboardToken(id int) error {
+	_, err := db.Exec(`DELETE FROM dashboard_tokens WHERE id = ?`, id)
+	return err
+}
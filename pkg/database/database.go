@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 
 	_ "modernc.org/sqlite"
 )
@@ -14,8 +15,16 @@ type DB struct {
 	*sql.DB
 }
 
-func Init(dbPath string, cfg *config.Config) (*DB, error) {
-	db, err := sql.Open("sqlite", dbPath)
+// Open opens the database without touching the schema
+func Open(dbPath string) (*DB, error) {
+	// Writers wait for each other instead of failing with SQLITE_BUSY, so
+	// request logging is not lost while the maintenance job prunes
+	dsn := dbPath
+	if !strings.Contains(dsn, "?") {
+		dsn += "?_pragma=busy_timeout(5000)"
+	}
+
+	db, err := sql.Open("sqlite", dsn)
 	if err != nil {
 		return nil, err
 	}
@@ -24,8 +33,17 @@ func Init(dbPath string, cfg *config.Config) (*DB, error) {
 		return nil, err
 	}
 
-	dbWrapper := &DB{db}
-	if err := dbWrapper.createTables(); err != nil {
+	return &DB{db}, nil
+}
+
+func Init(dbPath string, cfg *config.Config) (*DB, error) {
+	dbWrapper, err := Open(dbPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Bring the schema up to date
+	if err := dbWrapper.migrate(); err != nil {
 		return nil, err
 	}
 
@@ -37,75 +55,31 @@ func Init(dbPath string, cfg *config.Config) (*DB, error) {
 	return dbWrapper, nil
 }
 
-func (db *DB) createTables() error {
-	queries := []string{
-		`CREATE TABLE IF NOT EXISTS categories (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			name TEXT UNIQUE NOT NULL,
-			is_active BOOLEAN DEFAULT TRUE,
-			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
-		)`,
-		`CREATE TABLE IF NOT EXISTS endpoints (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			category_id INTEGER,
-			path TEXT NOT NULL,
-			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-			FOREIGN KEY (category_id) REFERENCES categories (id)
-		)`,
-		`CREATE TABLE IF NOT EXISTS api_sources (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			endpoint_id INTEGER,
-			source_name TEXT NOT NULL,
-			base_url TEXT NOT NULL,
-			priority INTEGER DEFAULT 1,
-			is_primary BOOLEAN DEFAULT TRUE,
-			is_active BOOLEAN DEFAULT TRUE,
-			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-			FOREIGN KEY (endpoint_id) REFERENCES endpoints (id)
-		)`,
-		`CREATE TABLE IF NOT EXISTS fallback_apis (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			api_source_id INTEGER,
-			fallback_url TEXT NOT NULL,
-			priority INTEGER DEFAULT 1,
-			is_active BOOLEAN DEFAULT TRUE,
-			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-			FOREIGN KEY (api_source_id) REFERENCES api_sources (id)
-		)`,
-		`CREATE TABLE IF NOT EXISTS health_checks (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			api_source_id INTEGER,
-			status TEXT NOT NULL, -- OK, TIMEOUT, ERROR
-			response_time INTEGER, -- in milliseconds
-			error_message TEXT,
-			checked_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-			FOREIGN KEY (api_source_id) REFERENCES api_sources (id)
-		)`,
-		`CREATE TABLE IF NOT EXISTS request_logs (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			endpoint TEXT NOT NULL,
-			category TEXT NOT NULL,
-			source_used TEXT,
-			fallback_used BOOLEAN DEFAULT FALSE,
-			response_time INTEGER,
-			status_code INTEGER,
-			client_ip TEXT,
-			user_agent TEXT,
-			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
-		)`,
-	}
-
-	for _, query := range queries {
-		if _, err := db.Exec(query); err != nil {
+// addColumnIfMissing adds a column to an existing table unless it is already there
+func (db *DB) addColumnIfMissing(table, column, definition string) error {
+	rows, err := db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var cid, notNull, pk int
+		var name, colType string
+		var defaultValue sql.NullString
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &defaultValue, &pk); err != nil {
 			return err
 		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
 	}
 
-	return nil
+	_, err = db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
+	return err
 }
 
 func (db *DB) insertDefaultData(cfg *config.Config) error {
@@ -240,7 +214,8 @@ func (db *DB) insertDefaultData(cfg *config.Config) error {
 		}
 	}
 
-	return nil
+	// The migrations ran before these sources existed
+	return db.seedSamehadakuMappings()
 }
 
 // Category represents a category in the database
@@ -299,20 +274,33 @@ type RequestLog struct {
 	StatusCode   int    `json:"status_code"`
 	ClientIP     string `json:"client_ip"`
 	UserAgent    string `json:"user_agent"`
+	APIKeyID     int    `json:"api_key_id,omitempty"` // 0 for anonymous requests
 	CreatedAt    string `json:"created_at"`
 }
 
+// CircuitBreakerEvent represents a circuit breaker state transition
+type CircuitBreakerEvent struct {
+	ID          int       `json:"id"`
+	APISourceID int       `json:"api_source_id"`
+	SourceName  string    `json:"source_name"`
+	FromState   string    `json:"from_state"`
+	ToState     string    `json:"to_state"`
+	Reason      string    `json:"reason"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
 // APISourceWithDetails represents an API source with additional details
 type APISourceWithDetails struct {
-	ID           int    `json:"id"`
-	EndpointID   int    `json:"endpoint_id"`
-	SourceName   string `json:"source_name"`
-	BaseURL      string `json:"base_url"`
-	Priority     int    `json:"priority"`
-	IsPrimary    bool   `json:"is_primary"`
-	IsActive     bool   `json:"is_active"`
-	EndpointPath string `json:"endpoint_path"`
-	CategoryName string `json:"category_name"`
+	ID           int           `json:"id"`
+	EndpointID   int           `json:"endpoint_id"`
+	SourceName   string        `json:"source_name"`
+	BaseURL      string        `json:"base_url"`
+	Priority     int           `json:"priority"`
+	IsPrimary    bool          `json:"is_primary"`
+	IsActive     bool          `json:"is_active"`
+	EndpointPath string        `json:"endpoint_path"`
+	CategoryName string        `json:"category_name"`
+	Fallbacks    []FallbackAPI `json:"fallbacks,omitempty"`
 }
 
 // EndpointWithDetails represents an endpoint with category details
@@ -336,10 +324,14 @@ func (db *DB) LogHealthCheck(apiSourceID int, status string, responseTime int, e
 // LogRequest logs an API request
 func (db *DB) LogRequest(log RequestLog) error {
 	query := `
-		INSERT INTO request_logs (endpoint, category, source_used, fallback_used, response_time, status_code, client_ip, user_agent, created_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, datetime('now'))
+		INSERT INTO request_logs (endpoint, category, source_used, fallback_used, response_time, status_code, client_ip, user_agent, api_key_id, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, datetime('now'))
 	`
-	_, err := db.Exec(query, log.Endpoint, log.Category, log.SourceUsed, log.FallbackUsed, log.ResponseTime, log.StatusCode, log.ClientIP, log.UserAgent)
+	var apiKeyID interface{}
+	if log.APIKeyID > 0 {
+		apiKeyID = log.APIKeyID
+	}
+	_, err := db.Exec(query, log.Endpoint, log.Category, log.SourceUsed, log.FallbackUsed, log.ResponseTime, log.StatusCode, log.ClientIP, log.UserAgent, apiKeyID)
 	return err
 }
 
@@ -550,6 +542,22 @@ func (db *DB) UpdateHealthCheck(apiSourceID int, status string, responseTime int
 	return err
 }
 
+// GetLatestHealthStatus returns the status of an API source's latest health
+// check, or an empty string when it has never been checked
+func (db *DB) GetLatestHealthStatus(apiSourceID int) (string, error) {
+	var status string
+	err := db.QueryRow(`
+		SELECT status FROM health_checks
+		WHERE api_source_id = ?
+		ORDER BY checked_at DESC, id DESC
+		LIMIT 1
+	`, apiSourceID).Scan(&status)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return status, err
+}
+
 // GetHealthChecks returns recent health checks
 func (db *DB) GetHealthChecks(limit int) ([]HealthCheck, error) {
 	query := `
@@ -581,7 +589,7 @@ func (db *DB) GetHealthChecks(limit int) ([]HealthCheck, error) {
 // GetRequestLogs returns recent request logs
 func (db *DB) GetRequestLogs(limit int) ([]RequestLog, error) {
 	query := `
-		SELECT id, endpoint, category, source_used, fallback_used, response_time, status_code, client_ip, user_agent, created_at
+		SELECT id, endpoint, category, source_used, fallback_used, response_time, status_code, client_ip, user_agent, COALESCE(api_key_id, 0), created_at
 		FROM request_logs
 		ORDER BY created_at DESC
 		LIMIT ?
@@ -596,7 +604,7 @@ func (db *DB) GetRequestLogs(limit int) ([]RequestLog, error) {
 	var logs []RequestLog
 	for rows.Next() {
 		var rl RequestLog
-		err := rows.Scan(&rl.ID, &rl.Endpoint, &rl.Category, &rl.SourceUsed, &rl.FallbackUsed, &rl.ResponseTime, &rl.StatusCode, &rl.ClientIP, &rl.UserAgent, &rl.CreatedAt)
+		err := rows.Scan(&rl.ID, &rl.Endpoint, &rl.Category, &rl.SourceUsed, &rl.FallbackUsed, &rl.ResponseTime, &rl.StatusCode, &rl.ClientIP, &rl.UserAgent, &rl.APIKeyID, &rl.CreatedAt)
 		if err != nil {
 			return nil, err
 		}
@@ -641,21 +649,48 @@ func (db *DB) DeleteCategory(id int) error {
 // CreateAPISource creates a new API source
 func (db *DB) CreateAPISource(endpointID int, sourceName, baseURL string, priority int, isPrimary bool) error {
 	query := `INSERT INTO api_sources (endpoint_id, source_name, base_url, priority, is_primary, is_active) VALUES (?, ?, ?, ?, ?, TRUE)`
-	_, err := db.Exec(query, endpointID, sourceName, baseURL, priority, isPrimary)
-	return err
+	if _, err := db.Exec(query, endpointID, sourceName, baseURL, priority, isPrimary); err != nil {
+		return err
+	}
+	return db.seedSamehadakuMappingsFor(baseURL)
 }
 
 // UpdateAPISource updates an existing API source
 func (db *DB) UpdateAPISource(id int, sourceName, baseURL string, priority int, isPrimary, isActive bool) error {
 	query := `UPDATE api_sources SET source_name = ?, base_url = ?, priority = ?, is_primary = ?, is_active = ? WHERE id = ?`
-	_, err := db.Exec(query, sourceName, baseURL, priority, isPrimary, isActive, id)
-	return err
+	if _, err := db.Exec(query, sourceName, baseURL, priority, isPrimary, isActive, id); err != nil {
+		return err
+	}
+	return db.seedSamehadakuMappingsFor(baseURL)
 }
 
 // DeleteAPISource deletes an API source
 func (db *DB) DeleteAPISource(id int) error {
-	_, err := db.Exec(`DELETE FROM api_sources WHERE id = ?`, id)
-	return err
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := deleteAPISourceRows(tx, id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM api_sources WHERE id = ?`, id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// deleteAPISourceRows deletes the rows that belong to an API source: its
+// fallback URLs, health checks and circuit breaker events
+func deleteAPISourceRows(tx *sql.Tx, id int) error {
+	for _, table := range []string{"fallback_apis", "health_checks", "circuit_breaker_events"} {
+		if _, err := tx.Exec(`DELETE FROM `+table+` WHERE api_source_id = ?`, id); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // DeleteAPISourceByName deletes all API sources with the given source name
@@ -687,10 +722,9 @@ func (db *DB) DeleteAPISourceByName(sourceName string) error {
 		return fmt.Errorf("no API sources found with name: %s", sourceName)
 	}
 
-	// Delete related health checks
+	// Delete related fallback URLs, health checks and circuit breaker events
 	for _, id := range apiSourceIDs {
-		_, err = tx.Exec(`DELETE FROM health_checks WHERE api_source_id = ?`, id)
-		if err != nil {
+		if err := deleteAPISourceRows(tx, id); err != nil {
 			return err
 		}
 	}
@@ -768,76 +802,42 @@ func (db *DB) GetAllAPISources() ([]APISourceWithDetails, error) {
 	return sources, nil
 }
 
-// CreateFallbackAPI creates a new fallback API
-func (db *DB) CreateFallbackAPI(apiSourceID int, fallbackURL string, priority int) error {
-	query := `INSERT INTO fallback_apis (api_source_id, fallback_url, priority) VALUES (?, ?, ?)`
-	_, err := db.Exec(query, apiSourceID, fallbackURL, priority)
-	return err
-}
-
-// GetStatistics returns real statistics from database
+// GetStatistics returns request statistics for the last 24 hourly buckets,
+// read from the hourly rollups and the request logs not rolled up yet
 func (db *DB) GetStatistics() (map[string]interface{}, error) {
-	stats := make(map[string]interface{})
-
-	// Total requests in last 24 hours
-	var totalRequests int
-	err := db.QueryRow(`SELECT COUNT(*) FROM request_logs WHERE created_at >= datetime('now', '-24 hours')`).Scan(&totalRequests)
+	since := time.Now().UTC().Truncate(time.Hour).Add(-23 * time.Hour)
+	rollups, err := db.GetRequestRollups(RollupHourly, since)
 	if err != nil {
-		totalRequests = 0
-	}
-
-	// Successful requests
-	var successfulRequests int
-	err = db.QueryRow(`SELECT COUNT(*) FROM request_logs WHERE status_code >= 200 AND status_code < 300 AND created_at >= datetime('now', '-24 hours')`).Scan(&successfulRequests)
-	if err != nil {
-		successfulRequests = 0
-	}
-
-	// Failed requests
-	var failedRequests int
-	err = db.QueryRow(`SELECT COUNT(*) FROM request_logs WHERE status_code >= 400 AND created_at >= datetime('now', '-24 hours')`).Scan(&failedRequests)
-	if err != nil {
-		failedRequests = 0
+		return nil, err
 	}
 
-	// Fallback usage
-	var fallbackUsage int
-	err = db.QueryRow(`SELECT COUNT(*) FROM request_logs WHERE fallback_used = TRUE AND created_at >= datetime('now', '-24 hours')`).Scan(&fallbackUsage)
-	if err != nil {
-		fallbackUsage = 0
+	var totalRequests, successfulRequests, failedRequests, fallbackUsage, timedRequests int
+	var totalResponseTime int64
+	for _, rollup := range rollups {
+		totalRequests += rollup.Requests
+		successfulRequests += rollup.Successes
+		failedRequests += rollup.Failures
+		fallbackUsage += rollup.Fallbacks
+		timedRequests += rollup.TimedRequests
+		totalResponseTime += rollup.TotalResponseTime
 	}
 
-	// Average response time
-	var avgResponseTime float64
-	err = db.QueryRow(`SELECT AVG(response_time) FROM request_logs WHERE created_at >= datetime('now', '-24 hours') AND response_time > 0`).Scan(&avgResponseTime)
-	if err != nil {
-		avgResponseTime = 0
+	// Average response time and success rate
+	var avgResponseTime, successRate float64
+	if timedRequests > 0 {
+		avgResponseTime = float64(totalResponseTime) / float64(timedRequests)
 	}
-
-	// Calculate success rate
-	var successRate float64
 	if totalRequests > 0 {
 		successRate = (float64(successfulRequests) / float64(totalRequests)) * 100
 	}
 
-	// If no data in request_logs, provide sample data for demonstration
-	if totalRequests == 0 {
-		// Generate sample statistics for demo purposes
-		totalRequests = 150
-		successfulRequests = 142
-		failedRequests = 8
-		fallbackUsage = 12
-		avgResponseTime = 245.5
-		successRate = 94.7
-	}
-
+	stats := make(map[string]interface{})
 	stats["total_requests"] = totalRequests
 	stats["successful_requests"] = successfulRequests
 	stats["failed_requests"] = failedRequests
 	stats["fallback_usage"] = fallbackUsage
 	stats["avg_response_time"] = int(avgResponseTime)
 	stats["success_rate"] = int(successRate)
-	stats["uptime"] = "99.9%"
 
 	return stats, nil
 }
@@ -941,6 +941,10 @@ func (db *DB) GetHealthStatusWithDetails() ([]map[string]interface{}, error) {
 		mappedStatus := "unhealthy"
 		if status == "OK" {
 			mappedStatus = "healthy"
+		} else if status == "DEGRADED" {
+			mappedStatus = "degraded" // Reachable, but its data fails validation
+		} else if status == "SKIPPED" {
+			mappedStatus = "unconfigured" // Not probed; error_message says why
 		} else if status == "UNKNOWN" {
 			mappedStatus = "healthy" // Assume healthy for demo
 		}
@@ -951,15 +955,38 @@ func (db *DB) GetHealthStatusWithDetails() ([]map[string]interface{}, error) {
 			responseTimeStr = fmt.Sprintf("%dms", responseTime)
 		}
 
+		// Get latest circuit breaker state and recent trips for this source
+		circuitState, circuitReason, circuitChangedAt := "closed", "", ""
+		circuitQuery := `
+			SELECT to_state, COALESCE(reason, ''), created_at
+			FROM circuit_breaker_events
+			WHERE api_source_id = ?
+			ORDER BY id DESC
+			LIMIT 1
+		`
+		if err := db.QueryRow(circuitQuery, apiSourceID).Scan(&circuitState, &circuitReason, &circuitChangedAt); err != nil {
+			circuitState, circuitReason, circuitChangedAt = "closed", "", ""
+		}
+
+		var circuitTrips int
+		db.QueryRow(`
+			SELECT COUNT(*) FROM circuit_breaker_events
+			WHERE api_source_id = ? AND to_state = 'open' AND created_at > datetime('now', '-24 hours')
+		`, apiSourceID).Scan(&circuitTrips)
+
 		result := map[string]interface{}{
-			"api_source_id": apiSourceID,
-			"status":        mappedStatus,
-			"response_time": responseTimeStr,
-			"error_message": errorMessage,
-			"last_checked":  checkedAt,
-			"source_name":   sourceName,
-			"base_url":      baseURL,
-			"endpoint_path": endpointPath,
+			"api_source_id":      apiSourceID,
+			"status":             mappedStatus,
+			"response_time":      responseTimeStr,
+			"error_message":      errorMessage,
+			"last_checked":       checkedAt,
+			"source_name":        sourceName,
+			"base_url":           baseURL,
+			"endpoint_path":      endpointPath,
+			"circuit_state":      circuitState,
+			"circuit_reason":     circuitReason,
+			"circuit_changed_at": circuitChangedAt,
+			"circuit_trips_24h":  circuitTrips,
 		}
 		results = append(results, result)
 	}
@@ -1004,6 +1031,45 @@ func (db *DB) GetHealthStatusWithDetails() ([]map[string]interface{}, error) {
 	return results, nil
 }
 
+// LogCircuitBreakerEvent records a circuit breaker state transition for an API source
+func (db *DB) LogCircuitBreakerEvent(apiSourceID int, fromState, toState, reason string) error {
+	query := `
+		INSERT INTO circuit_breaker_events (api_source_id, from_state, to_state, reason, created_at)
+		VALUES (?, ?, ?, ?, datetime('now'))
+	`
+	_, err := db.Exec(query, apiSourceID, fromState, toState, reason)
+	return err
+}
+
+// GetOpenCircuitBreakers returns the sources whose latest circuit breaker event left them open or half-open
+func (db *DB) GetOpenCircuitBreakers() ([]CircuitBreakerEvent, error) {
+	query := `
+		SELECT e.id, e.api_source_id, COALESCE(a.source_name, ''), e.from_state, e.to_state, COALESCE(e.reason, ''), e.created_at
+		FROM circuit_breaker_events e
+		LEFT JOIN api_sources a ON e.api_source_id = a.id
+		WHERE e.id IN (SELECT MAX(id) FROM circuit_breaker_events GROUP BY api_source_id)
+		AND e.to_state IN ('open', 'half-open')
+	`
+
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []CircuitBreakerEvent
+	for rows.Next() {
+		var ev CircuitBreakerEvent
+		err := rows.Scan(&ev.ID, &ev.APISourceID, &ev.SourceName, &ev.FromState, &ev.ToState, &ev.Reason, &ev.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, ev)
+	}
+
+	return events, nil
+}
+
 // GetAllEndpoints returns all endpoints with category details
 func (db *DB) GetAllEndpoints() ([]EndpointWithDetails, error) {
 	query := `
@@ -1,17 +1,25 @@
 package database
 
 import (
+	"apicategorywithfallback/pkg/config"
+	"apicategorywithfallback/pkg/logger"
 	"os"
 	"testing"
 	"time"
 )
 
+func TestMain(m *testing.M) {
+	// Migrations log through the shared logger
+	logger.Init()
+	os.Exit(m.Run())
+}
+
 func TestInit(t *testing.T) {
 	// Use temporary database file
 	dbPath := "/tmp/test_api_fallback.db"
 	defer os.Remove(dbPath)
 
-	db, err := Init(dbPath)
+	db, err := Init(dbPath, &config.Config{})
 	if err != nil {
 		t.Fatalf("Failed to initialize database: %v", err)
 	}
@@ -55,7 +63,7 @@ func TestGetEndpointsByCategory(t *testing.T) {
 	dbPath := "/tmp/test_endpoints.db"
 	defer os.Remove(dbPath)
 
-	db, err := Init(dbPath)
+	db, err := Init(dbPath, &config.Config{})
 	if err != nil {
 		t.Fatalf("Failed to initialize database: %v", err)
 	}
@@ -97,7 +105,11 @@ func TestGetAPISourcesByEndpoint(t *testing.T) {
 	dbPath := "/tmp/test_api_sources.db"
 	defer os.Remove(dbPath)
 
-	db, err := Init(dbPath)
+	cfg := &config.Config{APISources: map[string]string{
+		"alpha": "http://alpha.test",
+		"beta":  "http://beta.test",
+	}}
+	db, err := Init(dbPath, cfg)
 	if err != nil {
 		t.Fatalf("Failed to initialize database: %v", err)
 	}
@@ -123,11 +135,45 @@ func TestGetAPISourcesByEndpoint(t *testing.T) {
 	}
 }
 
+func TestSamehadakuURLsGetSearchMapping(t *testing.T) {
+	dbPath := "/tmp/test_samehadaku_mappings.db"
+	defer os.Remove(dbPath)
+
+	cfg := &config.Config{APISources: map[string]string{
+		"mirror": "https://samehadaku.example",
+		"alpha":  "http://alpha.test",
+	}}
+	db, err := Init(dbPath, cfg)
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer db.Close()
+
+	mappings, err := db.GetRequestMappings()
+	if err != nil {
+		t.Fatalf("Failed to get request mappings: %v", err)
+	}
+
+	seeded := make(map[string]RequestMapping)
+	for _, mapping := range mappings {
+		if mapping.EndpointPath == "/api/v1/search" {
+			seeded[mapping.SourceName] = mapping
+		}
+	}
+	mirror, exists := seeded["mirror"]
+	if !exists || mirror.PathSuffix != "/" || mirror.DefaultParams["force_refresh"] != "false" {
+		t.Errorf("Expected the samehadaku URL to get the search mapping, got %+v", mirror)
+	}
+	if _, exists := seeded["alpha"]; exists {
+		t.Error("Expected no search mapping for a source on another URL")
+	}
+}
+
 func TestLogRequest(t *testing.T) {
 	dbPath := "/tmp/test_request_log.db"
 	defer os.Remove(dbPath)
 
-	db, err := Init(dbPath)
+	db, err := Init(dbPath, &config.Config{})
 	if err != nil {
 		t.Fatalf("Failed to initialize database: %v", err)
 	}
@@ -164,7 +210,7 @@ func TestLogHealthCheck(t *testing.T) {
 	dbPath := "/tmp/test_health_check.db"
 	defer os.Remove(dbPath)
 
-	db, err := Init(dbPath)
+	db, err := Init(dbPath, &config.Config{})
 	if err != nil {
 		t.Fatalf("Failed to initialize database: %v", err)
 	}
@@ -190,7 +236,7 @@ func TestGetHealthChecks(t *testing.T) {
 	dbPath := "/tmp/test_get_health_checks.db"
 	defer os.Remove(dbPath)
 
-	db, err := Init(dbPath)
+	db, err := Init(dbPath, &config.Config{})
 	if err != nil {
 		t.Fatalf("Failed to initialize database: %v", err)
 	}
@@ -223,7 +269,7 @@ func TestGetRequestLogs(t *testing.T) {
 	dbPath := "/tmp/test_get_request_logs.db"
 	defer os.Remove(dbPath)
 
-	db, err := Init(dbPath)
+	db, err := Init(dbPath, &config.Config{})
 	if err != nil {
 		t.Fatalf("Failed to initialize database: %v", err)
 	}
@@ -272,3 +318,39 @@ func TestGetRequestLogs(t *testing.T) {
 		}
 	}
 }
+
+func TestDeleteAPISourceDeletesItsRows(t *testing.T) {
+	dbPath := "/tmp/test_delete_api_source.db"
+	defer os.Remove(dbPath)
+
+	db, err := Init(dbPath, &config.Config{APISources: map[string]string{"alpha": "http://alpha.test"}})
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer db.Close()
+
+	sources, err := db.GetAPISourcesByName("alpha")
+	if err != nil || len(sources) == 0 {
+		t.Fatalf("Expected alpha's sources, got %v, %v", sources, err)
+	}
+	id := sources[0].ID
+	if err := db.LogHealthCheck(id, "ERROR", 0, "connection refused"); err != nil {
+		t.Fatalf("Failed to log health check: %v", err)
+	}
+	if err := db.LogCircuitBreakerEvent(id, "closed", "open", "too many failures"); err != nil {
+		t.Fatalf("Failed to log circuit breaker event: %v", err)
+	}
+
+	if err := db.DeleteAPISource(id); err != nil {
+		t.Fatalf("DeleteAPISource failed: %v", err)
+	}
+	for _, table := range []string{"health_checks", "circuit_breaker_events", "fallback_apis"} {
+		var count int
+		if err := db.QueryRow(`SELECT COUNT(*) FROM `+table+` WHERE api_source_id = ?`, id).Scan(&count); err != nil {
+			t.Fatalf("Failed to count %s: %v", table, err)
+		}
+		if count != 0 {
+			t.Errorf("Expected the deleted source's %s to be deleted, got %d rows", table, count)
+		}
+	}
+}
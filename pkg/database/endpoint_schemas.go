@@ -0,0 +1,65 @@
+package database
+
+import "database/sql"
+
+// EndpointSchema is a stored JSON Schema for an endpoint's responses
+type EndpointSchema struct {
+	ID           int    `json:"id"`
+	EndpointPath string `json:"endpoint_path"`
+	Schema       string `json:"schema"`
+	IsActive     bool   `json:"is_active"`
+}
+
+// GetEndpointSchemas returns all stored endpoint schemas
+func (db *DB) GetEndpointSchemas() ([]EndpointSchema, error) {
+	rows, err := db.Query(`SELECT id, endpoint_path, schema, is_active FROM endpoint_schemas ORDER BY endpoint_path`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	schemas := []EndpointSchema{}
+	for rows.Next() {
+		var schema EndpointSchema
+		if err := rows.Scan(&schema.ID, &schema.EndpointPath, &schema.Schema, &schema.IsActive); err != nil {
+			return nil, err
+		}
+		schemas = append(schemas, schema)
+	}
+
+	return schemas, rows.Err()
+}
+
+// GetEndpointSchema returns the endpoint schema with the given ID, or nil if it does not exist
+func (db *DB) GetEndpointSchema(id int) (*EndpointSchema, error) {
+	var schema EndpointSchema
+	err := db.QueryRow(`SELECT id, endpoint_path, schema, is_active FROM endpoint_schemas WHERE id = ?`, id).
+		Scan(&schema.ID, &schema.EndpointPath, &schema.Schema, &schema.IsActive)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &schema, nil
+}
+
+// SaveEndpointSchema creates or replaces the schema of an endpoint path and sets its ID
+func (db *DB) SaveEndpointSchema(schema *EndpointSchema) error {
+	query := `
+		INSERT INTO endpoint_schemas (endpoint_path, schema, is_active)
+		VALUES (?, ?, ?)
+		ON CONFLICT (endpoint_path) DO UPDATE
+		SET schema = excluded.schema, is_active = excluded.is_active, updated_at = datetime('now')
+	`
+	if _, err := db.Exec(query, schema.EndpointPath, schema.Schema, schema.IsActive); err != nil {
+		return err
+	}
+	return db.QueryRow(`SELECT id FROM endpoint_schemas WHERE endpoint_path = ?`, schema.EndpointPath).Scan(&schema.ID)
+}
+
+// DeleteEndpointSchema deletes an endpoint schema
+func (db *DB) DeleteEndpointSchema(id int) error {
+	_, err := db.Exec(`DELETE FROM endpoint_schemas WHERE id = ?`, id)
+	return err
+}
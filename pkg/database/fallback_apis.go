@@ -0,0 +1,193 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// GetAPISource returns the API source with the given ID, or nil if it does not exist
+func (db *DB) GetAPISource(id int) (*APISource, error) {
+	query := `
+		SELECT a.id, a.endpoint_id, a.source_name, a.base_url, a.priority, a.is_primary, a.is_active, COALESCE(e.path, '')
+		FROM api_sources a
+		LEFT JOIN endpoints e ON e.id = a.endpoint_id
+		WHERE a.id = ?
+	`
+
+	var src APISource
+	err := db.QueryRow(query, id).Scan(&src.ID, &src.EndpointID, &src.SourceName, &src.BaseURL, &src.Priority, &src.IsPrimary, &src.IsActive, &src.EndpointPath)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &src, nil
+}
+
+// GetFallbackChain returns all fallback APIs of a source, including disabled ones, in priority order
+func (db *DB) GetFallbackChain(apiSourceID int) ([]FallbackAPI, error) {
+	query := `
+		SELECT id, api_source_id, fallback_url, priority, is_active
+		FROM fallback_apis
+		WHERE api_source_id = ?
+		ORDER BY priority ASC, id ASC
+	`
+
+	rows, err := db.Query(query, apiSourceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	fallbacks := []FallbackAPI{}
+	for rows.Next() {
+		var fb FallbackAPI
+		if err := rows.Scan(&fb.ID, &fb.APISourceID, &fb.FallbackURL, &fb.Priority, &fb.IsActive); err != nil {
+			return nil, err
+		}
+		fallbacks = append(fallbacks, fb)
+	}
+
+	return fallbacks, rows.Err()
+}
+
+// GetAllFallbackChains returns the fallback chains of all sources, keyed by API source ID
+func (db *DB) GetAllFallbackChains() (map[int][]FallbackAPI, error) {
+	query := `
+		SELECT id, api_source_id, fallback_url, priority, is_active
+		FROM fallback_apis
+		ORDER BY api_source_id, priority ASC, id ASC
+	`
+
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	chains := make(map[int][]FallbackAPI)
+	for rows.Next() {
+		var fb FallbackAPI
+		if err := rows.Scan(&fb.ID, &fb.APISourceID, &fb.FallbackURL, &fb.Priority, &fb.IsActive); err != nil {
+			return nil, err
+		}
+		chains[fb.APISourceID] = append(chains[fb.APISourceID], fb)
+	}
+
+	return chains, rows.Err()
+}
+
+// GetFallbackAPI returns the fallback API with the given ID, or nil if it does not exist
+func (db *DB) GetFallbackAPI(id int) (*FallbackAPI, error) {
+	query := `
+		SELECT id, api_source_id, fallback_url, priority, is_active
+		FROM fallback_apis
+		WHERE id = ?
+	`
+
+	var fb FallbackAPI
+	err := db.QueryRow(query, id).Scan(&fb.ID, &fb.APISourceID, &fb.FallbackURL, &fb.Priority, &fb.IsActive)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &fb, nil
+}
+
+// CreateFallbackAPI appends a fallback API to a source's chain. A priority of 0
+// places it after the existing fallbacks.
+func (db *DB) CreateFallbackAPI(apiSourceID int, fallbackURL string, priority int, isActive bool) (*FallbackAPI, error) {
+	if priority <= 0 {
+		err := db.QueryRow(`SELECT COALESCE(MAX(priority), 0) + 1 FROM fallback_apis WHERE api_source_id = ?`, apiSourceID).Scan(&priority)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	query := `INSERT INTO fallback_apis (api_source_id, fallback_url, priority, is_active) VALUES (?, ?, ?, ?)`
+	result, err := db.Exec(query, apiSourceID, fallbackURL, priority, isActive)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	return &FallbackAPI{
+		ID:          int(id),
+		APISourceID: apiSourceID,
+		FallbackURL: fallbackURL,
+		Priority:    priority,
+		IsActive:    isActive,
+	}, nil
+}
+
+// UpdateFallbackAPI updates a fallback API's URL, priority and status
+func (db *DB) UpdateFallbackAPI(id int, fallbackURL string, priority int, isActive bool) error {
+	query := `UPDATE fallback_apis SET fallback_url = ?, priority = ?, is_active = ?, updated_at = datetime('now') WHERE id = ?`
+	_, err := db.Exec(query, fallbackURL, priority, isActive, id)
+	return err
+}
+
+// DeleteFallbackAPI deletes a fallback API
+func (db *DB) DeleteFallbackAPI(id int) error {
+	_, err := db.Exec(`DELETE FROM fallback_apis WHERE id = ?`, id)
+	return err
+}
+
+// ReorderFallbackAPIs sets the priorities of a source's fallbacks to their position
+// in orderedIDs (1-based). orderedIDs must list every fallback of the source exactly once.
+func (db *DB) ReorderFallbackAPIs(apiSourceID int, orderedIDs []int) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(`SELECT id FROM fallback_apis WHERE api_source_id = ?`, apiSourceID)
+	if err != nil {
+		return err
+	}
+	existing := make(map[int]bool)
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		existing[id] = false
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if len(orderedIDs) != len(existing) {
+		return fmt.Errorf("expected %d fallback IDs, got %d", len(existing), len(orderedIDs))
+	}
+	for _, id := range orderedIDs {
+		seen, ok := existing[id]
+		if !ok {
+			return fmt.Errorf("fallback %d does not belong to API source %d", id, apiSourceID)
+		}
+		if seen {
+			return fmt.Errorf("fallback %d listed more than once", id)
+		}
+		existing[id] = true
+	}
+
+	for i, id := range orderedIDs {
+		if _, err := tx.Exec(`UPDATE fallback_apis SET priority = ?, updated_at = datetime('now') WHERE id = ?`, i+1, id); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,102 @@
+package database
+
+import "database/sql"
+
+// HealthProbe describes the synthetic request health checks send to every
+// source of an endpoint and what the response must look like
+type HealthProbe struct {
+	ID           int               `json:"id"`
+	EndpointPath string            `json:"endpoint_path"`
+	Params       map[string]string `json:"params"`        // Request parameters, including path parameters
+	ExpectSchema string            `json:"expect_schema"` // Extra JSON Schema for the response, empty for none
+	IsActive     bool              `json:"is_active"`
+}
+
+const healthProbeColumns = `id, endpoint_path, params, expect_schema, is_active`
+
+// GetHealthProbes returns all health probes
+func (db *DB) GetHealthProbes() ([]HealthProbe, error) {
+	rows, err := db.Query(`SELECT ` + healthProbeColumns + ` FROM health_probes ORDER BY endpoint_path`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	probes := []HealthProbe{}
+	for rows.Next() {
+		probe, err := scanHealthProbe(rows)
+		if err != nil {
+			return nil, err
+		}
+		probes = append(probes, *probe)
+	}
+
+	return probes, rows.Err()
+}
+
+// GetHealthProbe returns the health probe with the given ID, or nil if it does not exist
+func (db *DB) GetHealthProbe(id int) (*HealthProbe, error) {
+	probe, err := scanHealthProbe(db.QueryRow(`SELECT `+healthProbeColumns+` FROM health_probes WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return probe, err
+}
+
+// CreateHealthProbe stores a new health probe and sets its ID
+func (db *DB) CreateHealthProbe(probe *HealthProbe) error {
+	params, err := encodeStringMap(probe.Params)
+	if err != nil {
+		return err
+	}
+
+	result, err := db.Exec(`
+		INSERT INTO health_probes (endpoint_path, params, expect_schema, is_active)
+		VALUES (?, ?, ?, ?)
+	`, probe.EndpointPath, params, probe.ExpectSchema, probe.IsActive)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	probe.ID = int(id)
+	return nil
+}
+
+// UpdateHealthProbe replaces a health probe
+func (db *DB) UpdateHealthProbe(probe *HealthProbe) error {
+	params, err := encodeStringMap(probe.Params)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+		UPDATE health_probes
+		SET endpoint_path = ?, params = ?, expect_schema = ?, is_active = ?, updated_at = datetime('now')
+		WHERE id = ?
+	`, probe.EndpointPath, params, probe.ExpectSchema, probe.IsActive, probe.ID)
+	return err
+}
+
+// DeleteHealthProbe deletes a health probe
+func (db *DB) DeleteHealthProbe(id int) error {
+	_, err := db.Exec(`DELETE FROM health_probes WHERE id = ?`, id)
+	return err
+}
+
+func scanHealthProbe(row rowScanner) (*HealthProbe, error) {
+	var probe HealthProbe
+	var params string
+	if err := row.Scan(&probe.ID, &probe.EndpointPath, &params, &probe.ExpectSchema, &probe.IsActive); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if probe.Params, err = decodeStringMap(params); err != nil {
+		return nil, err
+	}
+	return &probe, nil
+}
@@ -0,0 +1,63 @@
+package database
+
+import (
+	"apicategorywithfallback/pkg/logger"
+	"apicategorywithfallback/pkg/migrate"
+	"embed"
+	"fmt"
+	"io/fs"
+)
+
+// migrationFiles holds the versioned schema migrations (NNNN_name.up.sql / .down.sql)
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// Migrator returns a migrator for the embedded schema migrations
+func (db *DB) Migrator() (*migrate.Migrator, error) {
+	files, err := fs.Sub(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	return migrate.New(db.DB, files)
+}
+
+// migrate applies all pending schema migrations
+func (db *DB) migrate() error {
+	migrator, err := db.Migrator()
+	if err != nil {
+		return err
+	}
+
+	version, err := migrator.Version()
+	if err != nil {
+		return err
+	}
+	if version == 0 {
+		if err := db.upgradeLegacySchema(); err != nil {
+			return err
+		}
+	}
+
+	applied, err := migrator.Up()
+	for _, migration := range applied {
+		logger.Infof("📦 Applied schema migration %04d_%s", migration.Version, migration.Name)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to migrate database: %w", err)
+	}
+
+	return nil
+}
+
+// upgradeLegacySchema adds columns that databases created before versioned
+// migrations may lack, so the baseline migration can adopt them as-is
+func (db *DB) upgradeLegacySchema() error {
+	var count int
+	err := db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'request_logs'`).Scan(&count)
+	if err != nil || count == 0 {
+		return err
+	}
+
+	return db.addColumnIfMissing("request_logs", "api_key_id", "INTEGER")
+}
@@ -0,0 +1,12 @@
+DROP TABLE IF EXISTS dashboard_tokens;
+DROP TABLE IF EXISTS dashboard_sessions;
+DROP TABLE IF EXISTS dashboard_users;
+DROP TABLE IF EXISTS api_key_usage;
+DROP TABLE IF EXISTS api_keys;
+DROP TABLE IF EXISTS circuit_breaker_events;
+DROP TABLE IF EXISTS request_logs;
+DROP TABLE IF EXISTS health_checks;
+DROP TABLE IF EXISTS fallback_apis;
+DROP TABLE IF EXISTS api_sources;
+DROP TABLE IF EXISTS endpoints;
+DROP TABLE IF EXISTS categories;
@@ -0,0 +1,125 @@
+-- Baseline schema. Uses IF NOT EXISTS so databases created before versioned
+-- migrations are adopted without changes.
+
+CREATE TABLE IF NOT EXISTS categories (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT UNIQUE NOT NULL,
+    is_active BOOLEAN DEFAULT TRUE,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS endpoints (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    category_id INTEGER,
+    path TEXT NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (category_id) REFERENCES categories (id)
+);
+
+CREATE TABLE IF NOT EXISTS api_sources (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    endpoint_id INTEGER,
+    source_name TEXT NOT NULL,
+    base_url TEXT NOT NULL,
+    priority INTEGER DEFAULT 1,
+    is_primary BOOLEAN DEFAULT TRUE,
+    is_active BOOLEAN DEFAULT TRUE,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (endpoint_id) REFERENCES endpoints (id)
+);
+
+CREATE TABLE IF NOT EXISTS fallback_apis (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    api_source_id INTEGER,
+    fallback_url TEXT NOT NULL,
+    priority INTEGER DEFAULT 1,
+    is_active BOOLEAN DEFAULT TRUE,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (api_source_id) REFERENCES api_sources (id)
+);
+
+CREATE TABLE IF NOT EXISTS health_checks (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    api_source_id INTEGER,
+    status TEXT NOT NULL, -- OK, TIMEOUT, ERROR
+    response_time INTEGER, -- in milliseconds
+    error_message TEXT,
+    checked_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (api_source_id) REFERENCES api_sources (id)
+);
+
+CREATE TABLE IF NOT EXISTS request_logs (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    endpoint TEXT NOT NULL,
+    category TEXT NOT NULL,
+    source_used TEXT,
+    fallback_used BOOLEAN DEFAULT FALSE,
+    response_time INTEGER,
+    status_code INTEGER,
+    client_ip TEXT,
+    user_agent TEXT,
+    api_key_id INTEGER,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS circuit_breaker_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    api_source_id INTEGER,
+    from_state TEXT NOT NULL,
+    to_state TEXT NOT NULL, -- closed, open, half-open
+    reason TEXT,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (api_source_id) REFERENCES api_sources (id)
+);
+
+CREATE TABLE IF NOT EXISTS api_keys (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    key_hash TEXT UNIQUE NOT NULL, -- sha256 of the key, the key itself is never stored
+    key_prefix TEXT NOT NULL,      -- first characters of the key, for display
+    rate_limit INTEGER DEFAULT 60, -- requests per minute, 0 = unlimited
+    daily_quota INTEGER DEFAULT 0, -- requests per UTC day, 0 = unlimited
+    is_active BOOLEAN DEFAULT TRUE,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS api_key_usage (
+    api_key_id INTEGER NOT NULL,
+    day TEXT NOT NULL, -- YYYY-MM-DD (UTC)
+    request_count INTEGER DEFAULT 0,
+    PRIMARY KEY (api_key_id, day),
+    FOREIGN KEY (api_key_id) REFERENCES api_keys (id)
+);
+
+CREATE TABLE IF NOT EXISTS dashboard_users (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    username TEXT UNIQUE NOT NULL,
+    password_hash TEXT NOT NULL, -- bcrypt
+    role TEXT NOT NULL DEFAULT 'viewer', -- viewer, operator, admin
+    is_active BOOLEAN DEFAULT TRUE,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS dashboard_sessions (
+    token_hash TEXT PRIMARY KEY, -- sha256 of the session cookie
+    user_id INTEGER NOT NULL,
+    expires_at INTEGER NOT NULL, -- unix seconds
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES dashboard_users (id)
+);
+
+CREATE TABLE IF NOT EXISTS dashboard_tokens (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    token_hash TEXT UNIQUE NOT NULL, -- sha256 of the bearer token
+    token_prefix TEXT NOT NULL,
+    role TEXT NOT NULL DEFAULT 'viewer',
+    last_used_at DATETIME,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
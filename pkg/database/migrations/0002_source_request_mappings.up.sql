@@ -0,0 +1,24 @@
+-- Per-source, per-endpoint rules for building upstream requests. source_name
+-- '*' applies to every source; a source's own rule overrides it field by field.
+-- Maps hold JSON objects of string values.
+
+CREATE TABLE IF NOT EXISTS source_request_mappings (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    source_name TEXT NOT NULL,
+    endpoint_path TEXT NOT NULL,
+    param_renames TEXT NOT NULL DEFAULT '{}', -- gateway param -> upstream param
+    default_params TEXT NOT NULL DEFAULT '{}', -- added when the request does not set them
+    path_suffix TEXT NOT NULL DEFAULT '', -- appended to the upstream path
+    headers TEXT NOT NULL DEFAULT '{}', -- extra request headers
+    is_active BOOLEAN DEFAULT TRUE,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (source_name, endpoint_path)
+);
+
+-- Rules previously hard-coded in the gateway
+INSERT OR IGNORE INTO source_request_mappings (source_name, endpoint_path, param_renames)
+VALUES ('*', '/api/v1/search', '{"q":"query"}');
+
+INSERT OR IGNORE INTO source_request_mappings (source_name, endpoint_path, default_params, path_suffix)
+VALUES ('samehadaku', '/api/v1/search', '{"force_refresh":"false"}', '/');
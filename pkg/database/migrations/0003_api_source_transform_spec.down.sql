@@ -0,0 +1 @@
+ALTER TABLE api_sources DROP COLUMN transform_spec;
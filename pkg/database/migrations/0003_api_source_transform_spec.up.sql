@@ -0,0 +1,2 @@
+-- Declarative response transform applied to a source's responses before validation
+ALTER TABLE api_sources ADD COLUMN transform_spec TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,12 @@
+-- JSON Schemas for validating upstream responses. A schema stored here
+-- overrides the built-in and file schema of the same endpoint path, which may
+-- be a template such as /api/v1/jadwal-rilis/:day.
+
+CREATE TABLE IF NOT EXISTS endpoint_schemas (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    endpoint_path TEXT UNIQUE NOT NULL,
+    schema TEXT NOT NULL,
+    is_active BOOLEAN DEFAULT TRUE,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,20 @@
+-- Minimum confidence score per endpoint and source. '*' matches every source
+-- or endpoint; the most specific active policy applies (source and endpoint,
+-- then endpoint, then source, then the '*'/'*' default).
+
+CREATE TABLE IF NOT EXISTS confidence_policies (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    endpoint_path TEXT NOT NULL,
+    source_name TEXT NOT NULL,
+    min_score REAL NOT NULL DEFAULT 0.5,
+    missing_score TEXT NOT NULL DEFAULT 'reject', -- reject, assume, derive
+    assumed_score REAL NOT NULL DEFAULT 1.0, -- used by 'assume'
+    is_active BOOLEAN DEFAULT TRUE,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (endpoint_path, source_name)
+);
+
+-- Threshold previously hard-coded in the validator
+INSERT OR IGNORE INTO confidence_policies (endpoint_path, source_name, min_score, missing_score)
+VALUES ('*', '*', 0.5, 'reject');
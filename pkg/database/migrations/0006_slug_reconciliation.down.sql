@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_source_slugs_slug;
+DROP TABLE IF EXISTS source_slugs;
+DROP TABLE IF EXISTS canonical_titles;
@@ -0,0 +1,24 @@
+-- Cross-source slug reconciliation. A canonical title groups the native slugs
+-- each source uses for the same anime; requests for any of them are
+-- translated to the slug of the source being called.
+
+CREATE TABLE IF NOT EXISTS canonical_titles (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    canonical_id TEXT UNIQUE NOT NULL,
+    title TEXT NOT NULL DEFAULT '',
+    is_manual BOOLEAN DEFAULT FALSE, -- edited from the dashboard
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS source_slugs (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    canonical_title_id INTEGER NOT NULL,
+    source_name TEXT NOT NULL,
+    slug TEXT NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (canonical_title_id, source_name),
+    FOREIGN KEY (canonical_title_id) REFERENCES canonical_titles (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_source_slugs_slug ON source_slugs (slug);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_health_checks_source_checked;
+ALTER TABLE api_sources DROP COLUMN routing_override;
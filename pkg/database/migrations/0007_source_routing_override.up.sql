@@ -0,0 +1,5 @@
+-- Dashboard override of health-aware routing: auto, force_enable or force_disable
+ALTER TABLE api_sources ADD COLUMN routing_override TEXT NOT NULL DEFAULT 'auto';
+
+-- Latest health check per source, read on the request path
+CREATE INDEX IF NOT EXISTS idx_health_checks_source_checked ON health_checks (api_source_id, checked_at);
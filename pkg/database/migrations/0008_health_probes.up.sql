@@ -0,0 +1,19 @@
+-- Synthetic health probes: the parameters each endpoint is called with during
+-- health checks, and an optional JSON Schema the response must also satisfy on
+-- top of the endpoint's response schema. Params hold a JSON object of string
+-- values; path parameters of the endpoint template are filled from them.
+
+CREATE TABLE IF NOT EXISTS health_probes (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    endpoint_path TEXT NOT NULL UNIQUE,
+    params TEXT NOT NULL DEFAULT '{}',
+    expect_schema TEXT NOT NULL DEFAULT '', -- empty uses the endpoint schema alone
+    is_active BOOLEAN DEFAULT TRUE,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Test parameters previously hard-coded in the health checker
+INSERT OR IGNORE INTO health_probes (endpoint_path, params) VALUES ('/api/v1/search', '{"query":"a"}');
+INSERT OR IGNORE INTO health_probes (endpoint_path, params) VALUES ('/api/v1/anime-detail', '{"id":"1"}');
+INSERT OR IGNORE INTO health_probes (endpoint_path, params) VALUES ('/api/v1/episode-detail', '{"id":"1"}');
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_health_checks_checked;
+DROP TABLE IF EXISTS source_hourly_outcomes;
@@ -0,0 +1,15 @@
+-- Live request outcomes per source endpoint and UTC hour, the passive signal
+-- of uptime reports next to the health check history
+CREATE TABLE IF NOT EXISTS source_hourly_outcomes (
+    source_name TEXT NOT NULL,
+    endpoint_path TEXT NOT NULL,
+    hour DATETIME NOT NULL, -- start of the hour, YYYY-MM-DD HH:00:00
+    requests INTEGER NOT NULL DEFAULT 0,
+    failures INTEGER NOT NULL DEFAULT 0, -- requests without a valid response
+    PRIMARY KEY (source_name, endpoint_path, hour)
+);
+
+CREATE INDEX IF NOT EXISTS idx_source_hourly_outcomes_hour ON source_hourly_outcomes (hour);
+
+-- Health check history is read by time range for uptime reports
+CREATE INDEX IF NOT EXISTS idx_health_checks_checked ON health_checks (checked_at);
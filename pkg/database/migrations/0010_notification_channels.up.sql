@@ -0,0 +1,17 @@
+-- Destinations for alerts on source state changes. kind decides the payload
+-- (webhook, slack or telegram); the lists hold JSON arrays of strings and
+-- restrict which events a channel gets, empty meaning all.
+CREATE TABLE IF NOT EXISTS notification_channels (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE,
+    kind TEXT NOT NULL,
+    url TEXT NOT NULL,
+    chat_id TEXT NOT NULL DEFAULT '', -- telegram only
+    event_types TEXT NOT NULL DEFAULT '[]',
+    sources TEXT NOT NULL DEFAULT '[]',
+    endpoints TEXT NOT NULL DEFAULT '[]',
+    debounce_seconds INTEGER NOT NULL DEFAULT 300, -- repeats of the same change within this period are dropped
+    is_active BOOLEAN DEFAULT TRUE,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
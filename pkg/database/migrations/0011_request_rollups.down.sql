@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_request_logs_created;
+DROP TABLE IF EXISTS request_rollups_daily;
+DROP TABLE IF EXISTS request_rollups_hourly;
@@ -0,0 +1,35 @@
+-- Request logs rolled up per UTC hour and day, endpoint, category and source.
+-- Statistics and dashboard charts read these instead of scanning request_logs,
+-- which the maintenance job prunes after REQUEST_LOG_RETENTION.
+CREATE TABLE IF NOT EXISTS request_rollups_hourly (
+    bucket DATETIME NOT NULL, -- start of the hour, YYYY-MM-DD HH:00:00
+    endpoint TEXT NOT NULL,
+    category TEXT NOT NULL,
+    source TEXT NOT NULL, -- source_used, empty when none answered
+    requests INTEGER NOT NULL DEFAULT 0,
+    successes INTEGER NOT NULL DEFAULT 0, -- 2xx responses
+    failures INTEGER NOT NULL DEFAULT 0, -- 4xx and 5xx responses
+    fallbacks INTEGER NOT NULL DEFAULT 0,
+    timed_requests INTEGER NOT NULL DEFAULT 0, -- requests with a response time
+    total_response_time INTEGER NOT NULL DEFAULT 0, -- in milliseconds
+    max_response_time INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (bucket, endpoint, category, source)
+);
+
+CREATE TABLE IF NOT EXISTS request_rollups_daily (
+    bucket DATETIME NOT NULL, -- start of the day, YYYY-MM-DD 00:00:00
+    endpoint TEXT NOT NULL,
+    category TEXT NOT NULL,
+    source TEXT NOT NULL,
+    requests INTEGER NOT NULL DEFAULT 0,
+    successes INTEGER NOT NULL DEFAULT 0,
+    failures INTEGER NOT NULL DEFAULT 0,
+    fallbacks INTEGER NOT NULL DEFAULT 0,
+    timed_requests INTEGER NOT NULL DEFAULT 0,
+    total_response_time INTEGER NOT NULL DEFAULT 0,
+    max_response_time INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (bucket, endpoint, category, source)
+);
+
+-- Rollups, statistics and pruning read request logs by time range
+CREATE INDEX IF NOT EXISTS idx_request_logs_created ON request_logs (created_at);
@@ -0,0 +1,3 @@
+UPDATE health_probes
+SET is_active = TRUE, updated_at = CURRENT_TIMESTAMP
+WHERE endpoint_path IN ('/api/v1/anime-detail', '/api/v1/episode-detail') AND params = '{"id":"1"}';
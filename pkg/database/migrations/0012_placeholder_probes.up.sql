@@ -0,0 +1,7 @@
+-- The detail probes seeded in 0008 call anime-detail and episode-detail with a
+-- placeholder id that no source resolves, so every detail source failed
+-- validation. Deactivate them until an operator sets real parameters; detail
+-- endpoints without an active probe are recorded as SKIPPED.
+UPDATE health_probes
+SET is_active = FALSE, updated_at = CURRENT_TIMESTAMP
+WHERE endpoint_path IN ('/api/v1/anime-detail', '/api/v1/episode-detail') AND params = '{"id":"1"}';
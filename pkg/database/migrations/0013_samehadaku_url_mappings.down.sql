@@ -0,0 +1,8 @@
+DELETE FROM source_request_mappings
+WHERE source_name != 'samehadaku' AND endpoint_path = '/api/v1/search'
+    AND default_params = '{"force_refresh":"false"}' AND path_suffix = '/'
+    AND source_name IN (
+        SELECT a.source_name FROM api_sources a
+        JOIN endpoints e ON e.id = a.endpoint_id
+        WHERE e.path = '/api/v1/search' AND a.base_url LIKE '%samehadaku%'
+    );
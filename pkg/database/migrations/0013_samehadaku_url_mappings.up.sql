@@ -0,0 +1,9 @@
+-- Before request mappings the samehadaku search quirks (trailing slash and
+-- force_refresh=false) applied to every source whose base URL contains
+-- "samehadaku", whatever its name; 0002 only seeded the source named
+-- samehadaku. Seed the same mapping for the other sources on such URLs.
+INSERT OR IGNORE INTO source_request_mappings (source_name, endpoint_path, default_params, path_suffix)
+SELECT DISTINCT a.source_name, '/api/v1/search', '{"force_refresh":"false"}', '/'
+FROM api_sources a
+JOIN endpoints e ON e.id = a.endpoint_id
+WHERE e.path = '/api/v1/search' AND a.base_url LIKE '%samehadaku%';
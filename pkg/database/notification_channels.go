@@ -0,0 +1,149 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+)
+
+// NotificationChannel is a destination for alerts and the rules deciding which
+// alerts it gets. Empty lists match everything.
+type NotificationChannel struct {
+	ID              int      `json:"id"`
+	Name            string   `json:"name"`
+	Kind            string   `json:"kind"` // webhook, slack or telegram
+	URL             string   `json:"url"`
+	ChatID          string   `json:"chat_id"` // Telegram chat, telegram channels only
+	EventTypes      []string `json:"event_types"`
+	Sources         []string `json:"sources"`
+	Endpoints       []string `json:"endpoints"`
+	DebounceSeconds int      `json:"debounce_seconds"`
+	IsActive        bool     `json:"is_active"`
+}
+
+const notificationChannelColumns = `id, name, kind, url, chat_id, event_types, sources, endpoints, debounce_seconds, is_active`
+
+// GetNotificationChannels returns all notification channels
+func (db *DB) GetNotificationChannels() ([]NotificationChannel, error) {
+	rows, err := db.Query(`SELECT ` + notificationChannelColumns + ` FROM notification_channels ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	channels := []NotificationChannel{}
+	for rows.Next() {
+		channel, err := scanNotificationChannel(rows)
+		if err != nil {
+			return nil, err
+		}
+		channels = append(channels, *channel)
+	}
+
+	return channels, rows.Err()
+}
+
+// GetNotificationChannel returns the notification channel with the given ID, or nil if it does not exist
+func (db *DB) GetNotificationChannel(id int) (*NotificationChannel, error) {
+	channel, err := scanNotificationChannel(db.QueryRow(`SELECT `+notificationChannelColumns+` FROM notification_channels WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return channel, err
+}
+
+// CreateNotificationChannel stores a new notification channel and sets its ID
+func (db *DB) CreateNotificationChannel(channel *NotificationChannel) error {
+	eventTypes, sources, endpoints, err := marshalNotificationChannel(channel)
+	if err != nil {
+		return err
+	}
+
+	result, err := db.Exec(`
+		INSERT INTO notification_channels (name, kind, url, chat_id, event_types, sources, endpoints, debounce_seconds, is_active)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, channel.Name, channel.Kind, channel.URL, channel.ChatID, eventTypes, sources, endpoints, channel.DebounceSeconds, channel.IsActive)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	channel.ID = int(id)
+	return nil
+}
+
+// UpdateNotificationChannel replaces a notification channel
+func (db *DB) UpdateNotificationChannel(channel *NotificationChannel) error {
+	eventTypes, sources, endpoints, err := marshalNotificationChannel(channel)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+		UPDATE notification_channels
+		SET name = ?, kind = ?, url = ?, chat_id = ?, event_types = ?, sources = ?, endpoints = ?, debounce_seconds = ?, is_active = ?, updated_at = datetime('now')
+		WHERE id = ?
+	`, channel.Name, channel.Kind, channel.URL, channel.ChatID, eventTypes, sources, endpoints, channel.DebounceSeconds, channel.IsActive, channel.ID)
+	return err
+}
+
+// DeleteNotificationChannel deletes a notification channel
+func (db *DB) DeleteNotificationChannel(id int) error {
+	_, err := db.Exec(`DELETE FROM notification_channels WHERE id = ?`, id)
+	return err
+}
+
+func scanNotificationChannel(row rowScanner) (*NotificationChannel, error) {
+	var channel NotificationChannel
+	var eventTypes, sources, endpoints string
+	if err := row.Scan(&channel.ID, &channel.Name, &channel.Kind, &channel.URL, &channel.ChatID,
+		&eventTypes, &sources, &endpoints, &channel.DebounceSeconds, &channel.IsActive); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if channel.EventTypes, err = decodeStringList(eventTypes); err != nil {
+		return nil, err
+	}
+	if channel.Sources, err = decodeStringList(sources); err != nil {
+		return nil, err
+	}
+	if channel.Endpoints, err = decodeStringList(endpoints); err != nil {
+		return nil, err
+	}
+	return &channel, nil
+}
+
+func marshalNotificationChannel(channel *NotificationChannel) (eventTypes, sources, endpoints string, err error) {
+	if eventTypes, err = encodeStringList(channel.EventTypes); err != nil {
+		return "", "", "", err
+	}
+	if sources, err = encodeStringList(channel.Sources); err != nil {
+		return "", "", "", err
+	}
+	if endpoints, err = encodeStringList(channel.Endpoints); err != nil {
+		return "", "", "", err
+	}
+	return eventTypes, sources, endpoints, nil
+}
+
+func decodeStringList(raw string) ([]string, error) {
+	values := []string{}
+	if raw == "" {
+		return values, nil
+	}
+	if err := json.Unmarshal([]byte(raw), &values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+func encodeStringList(values []string) (string, error) {
+	if values == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal(values)
+	return string(data), err
+}
@@ -0,0 +1,179 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"strings"
+)
+
+// RequestMapping describes how requests for an endpoint are adapted to an
+// upstream source. SourceName "*" applies to every source.
+type RequestMapping struct {
+	ID            int               `json:"id"`
+	SourceName    string            `json:"source_name"`
+	EndpointPath  string            `json:"endpoint_path"`
+	ParamRenames  map[string]string `json:"param_renames"`  // Gateway param name -> upstream param name
+	DefaultParams map[string]string `json:"default_params"` // Added when the request does not set them
+	PathSuffix    string            `json:"path_suffix"`    // Appended to the upstream path
+	Headers       map[string]string `json:"headers"`        // Extra upstream request headers
+	IsActive      bool              `json:"is_active"`
+}
+
+// seedSamehadakuMappings gives every search source on a samehadaku URL the
+// search quirks the gateway used to apply by URL; migration 0013 does the same
+// for sources that existed before it. Sources that already have a search
+// mapping keep it.
+func (db *DB) seedSamehadakuMappings() error {
+	_, err := db.Exec(`
+		INSERT OR IGNORE INTO source_request_mappings (source_name, endpoint_path, default_params, path_suffix)
+		SELECT DISTINCT a.source_name, '/api/v1/search', '{"force_refresh":"false"}', '/'
+		FROM api_sources a
+		JOIN endpoints e ON e.id = a.endpoint_id
+		WHERE e.path = '/api/v1/search' AND a.base_url LIKE '%samehadaku%'
+	`)
+	return err
+}
+
+// seedSamehadakuMappingsFor seeds the samehadaku search mappings after a source
+// is created on, or moved to, baseURL
+func (db *DB) seedSamehadakuMappingsFor(baseURL string) error {
+	if !strings.Contains(strings.ToLower(baseURL), "samehadaku") {
+		return nil
+	}
+	return db.seedSamehadakuMappings()
+}
+
+const requestMappingColumns = `id, source_name, endpoint_path, param_renames, default_params, path_suffix, headers, is_active`
+
+// GetRequestMappings returns all request mappings
+func (db *DB) GetRequestMappings() ([]RequestMapping, error) {
+	rows, err := db.Query(`SELECT ` + requestMappingColumns + ` FROM source_request_mappings ORDER BY endpoint_path, source_name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	mappings := []RequestMapping{}
+	for rows.Next() {
+		mapping, err := scanRequestMapping(rows)
+		if err != nil {
+			return nil, err
+		}
+		mappings = append(mappings, *mapping)
+	}
+
+	return mappings, rows.Err()
+}
+
+// GetRequestMapping returns the request mapping with the given ID, or nil if it does not exist
+func (db *DB) GetRequestMapping(id int) (*RequestMapping, error) {
+	mapping, err := scanRequestMapping(db.QueryRow(`SELECT `+requestMappingColumns+` FROM source_request_mappings WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return mapping, err
+}
+
+// CreateRequestMapping stores a new request mapping and sets its ID
+func (db *DB) CreateRequestMapping(mapping *RequestMapping) error {
+	renames, defaults, headers, err := marshalRequestMapping(mapping)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO source_request_mappings (source_name, endpoint_path, param_renames, default_params, path_suffix, headers, is_active)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+	result, err := db.Exec(query, mapping.SourceName, mapping.EndpointPath, renames, defaults, mapping.PathSuffix, headers, mapping.IsActive)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	mapping.ID = int(id)
+	return nil
+}
+
+// UpdateRequestMapping replaces a request mapping's rules
+func (db *DB) UpdateRequestMapping(mapping *RequestMapping) error {
+	renames, defaults, headers, err := marshalRequestMapping(mapping)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE source_request_mappings
+		SET source_name = ?, endpoint_path = ?, param_renames = ?, default_params = ?, path_suffix = ?, headers = ?, is_active = ?, updated_at = datetime('now')
+		WHERE id = ?
+	`
+	_, err = db.Exec(query, mapping.SourceName, mapping.EndpointPath, renames, defaults, mapping.PathSuffix, headers, mapping.IsActive, mapping.ID)
+	return err
+}
+
+// DeleteRequestMapping deletes a request mapping
+func (db *DB) DeleteRequestMapping(id int) error {
+	_, err := db.Exec(`DELETE FROM source_request_mappings WHERE id = ?`, id)
+	return err
+}
+
+// rowScanner is implemented by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanRequestMapping(row rowScanner) (*RequestMapping, error) {
+	var mapping RequestMapping
+	var renames, defaults, headers string
+	if err := row.Scan(&mapping.ID, &mapping.SourceName, &mapping.EndpointPath, &renames, &defaults, &mapping.PathSuffix, &headers, &mapping.IsActive); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if mapping.ParamRenames, err = decodeStringMap(renames); err != nil {
+		return nil, err
+	}
+	if mapping.DefaultParams, err = decodeStringMap(defaults); err != nil {
+		return nil, err
+	}
+	if mapping.Headers, err = decodeStringMap(headers); err != nil {
+		return nil, err
+	}
+
+	return &mapping, nil
+}
+
+func marshalRequestMapping(mapping *RequestMapping) (renames, defaults, headers string, err error) {
+	if renames, err = encodeStringMap(mapping.ParamRenames); err != nil {
+		return "", "", "", err
+	}
+	if defaults, err = encodeStringMap(mapping.DefaultParams); err != nil {
+		return "", "", "", err
+	}
+	if headers, err = encodeStringMap(mapping.Headers); err != nil {
+		return "", "", "", err
+	}
+	return renames, defaults, headers, nil
+}
+
+func decodeStringMap(raw string) (map[string]string, error) {
+	values := map[string]string{}
+	if raw == "" {
+		return values, nil
+	}
+	if err := json.Unmarshal([]byte(raw), &values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+func encodeStringMap(values map[string]string) (string, error) {
+	if values == nil {
+		return "{}", nil
+	}
+	data, err := json.Marshal(values)
+	return string(data), err
+}
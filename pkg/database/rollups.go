@@ -0,0 +1,256 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Rollup granularities
+const (
+	RollupHourly = "hourly"
+	RollupDaily  = "daily"
+)
+
+const (
+	hourlyRollupTable = "request_rollups_hourly"
+	dailyRollupTable  = "request_rollups_daily"
+)
+
+// endOfTime is later than every stored bucket
+const endOfTime = "9999-12-31 23:59:59"
+
+// pruneBatchSize bounds the rows one DELETE removes, so request logging does
+// not wait behind a long prune
+const pruneBatchSize = 5000
+
+// RequestRollup counts the requests of one endpoint, category and source in an hour or day
+type RequestRollup struct {
+	Bucket            time.Time // Start of the UTC hour or day
+	Endpoint          string
+	Category          string
+	Source            string // Empty when no source answered
+	Requests          int
+	Successes         int // 2xx responses
+	Failures          int // 4xx and 5xx responses
+	Fallbacks         int
+	TimedRequests     int   // Requests with a response time
+	TotalResponseTime int64 // Milliseconds, over the timed requests
+	MaxResponseTime   int
+}
+
+const rollupColumns = `bucket, endpoint, category, source, requests, successes, failures, fallbacks, timed_requests, total_response_time, max_response_time`
+
+// SQLite expressions formatting a time column as text: as is, or truncated to its hour or day
+func timeOf(column string) string { return "strftime('%Y-%m-%d %H:%M:%S', " + column + ")" }
+func hourOf(column string) string { return "strftime('%Y-%m-%d %H:00:00', " + column + ")" }
+func dayOf(column string) string  { return "strftime('%Y-%m-%d 00:00:00', " + column + ")" }
+
+// rawRollupSelect aggregates the request logs from a point in time into buckets
+func rawRollupSelect(bucket string) string {
+	return `
+		SELECT ` + bucket + ` AS bucket, endpoint, category, COALESCE(source_used, '') AS source,
+			COUNT(*) AS requests,
+			SUM(CASE WHEN status_code >= 200 AND status_code < 300 THEN 1 ELSE 0 END) AS successes,
+			SUM(CASE WHEN status_code >= 400 THEN 1 ELSE 0 END) AS failures,
+			SUM(CASE WHEN fallback_used THEN 1 ELSE 0 END) AS fallbacks,
+			SUM(CASE WHEN response_time > 0 THEN 1 ELSE 0 END) AS timed_requests,
+			SUM(CASE WHEN response_time > 0 THEN response_time ELSE 0 END) AS total_response_time,
+			COALESCE(MAX(response_time), 0) AS max_response_time
+		FROM request_logs
+		WHERE created_at >= ?
+		GROUP BY 1, 2, 3, 4`
+}
+
+// storedRollupSelect re-aggregates the rows of a rollup table in [from, to) into buckets
+func storedRollupSelect(table, bucket string) string {
+	return `
+		SELECT ` + bucket + ` AS bucket, endpoint, category, source,
+			SUM(requests) AS requests, SUM(successes) AS successes, SUM(failures) AS failures,
+			SUM(fallbacks) AS fallbacks, SUM(timed_requests) AS timed_requests,
+			SUM(total_response_time) AS total_response_time, MAX(max_response_time) AS max_response_time
+		FROM ` + table + `
+		WHERE bucket >= ? AND bucket < ?
+		GROUP BY 1, 2, 3, 4`
+}
+
+type queryRower interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// rollupWatermark returns the start of the latest bucket of a rollup table, or
+// "" when it is empty. That bucket may be partial: the next rollup recomputes it.
+func rollupWatermark(q queryRower, table string) (string, error) {
+	var watermark string
+	err := q.QueryRow(`SELECT COALESCE(` + timeOf("MAX(bucket)") + `, '') FROM ` + table).Scan(&watermark)
+	return watermark, err
+}
+
+// RollUpRequestLogs refreshes the hourly rollups from the request logs and the
+// daily rollups from the hourly ones. Only buckets from the latest rolled up
+// one onward are recomputed, so it is cheap to run often and safe to repeat.
+func (db *DB) RollUpRequestLogs() error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	hourly, err := rollupWatermark(tx, hourlyRollupTable)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM `+hourlyRollupTable+` WHERE bucket >= ?`, hourly); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`INSERT INTO `+hourlyRollupTable+` (`+rollupColumns+`)`+rawRollupSelect(hourOf("created_at")), hourly); err != nil {
+		return err
+	}
+
+	daily, err := rollupWatermark(tx, dailyRollupTable)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM `+dailyRollupTable+` WHERE bucket >= ?`, daily); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`INSERT INTO `+dailyRollupTable+` (`+rollupColumns+`)`+storedRollupSelect(hourlyRollupTable, dayOf("bucket")), daily, endOfTime); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetRequestRollups returns the request counts per bucket, endpoint, category
+// and source since a point in time, at hourly or daily granularity. Buckets
+// not rolled up yet are aggregated from the finer rollups and the request
+// logs, so the latest bucket is always current.
+func (db *DB) GetRequestRollups(granularity string, since time.Time) ([]RequestRollup, error) {
+	from := since.UTC().Format(sqliteTimeLayout)
+	hourly, err := rollupWatermark(db, hourlyRollupTable)
+	if err != nil {
+		return nil, err
+	}
+
+	var parts string
+	var args []interface{}
+	switch granularity {
+	case RollupHourly:
+		parts = storedRollupSelect(hourlyRollupTable, timeOf("bucket")) +
+			` UNION ALL ` + rawRollupSelect(hourOf("created_at"))
+		args = []interface{}{from, hourly, maxTime(from, hourly)}
+	case RollupDaily:
+		daily, err := rollupWatermark(db, dailyRollupTable)
+		if err != nil {
+			return nil, err
+		}
+		parts = storedRollupSelect(dailyRollupTable, timeOf("bucket")) +
+			` UNION ALL ` + storedRollupSelect(hourlyRollupTable, dayOf("bucket")) +
+			` UNION ALL ` + rawRollupSelect(dayOf("created_at"))
+		args = []interface{}{from, daily, maxTime(from, daily), hourly, maxTime(from, hourly)}
+	default:
+		return nil, fmt.Errorf("unknown rollup granularity %q", granularity)
+	}
+
+	rows, err := db.Query(`
+		SELECT bucket, endpoint, category, source,
+			SUM(requests), SUM(successes), SUM(failures), SUM(fallbacks),
+			SUM(timed_requests), SUM(total_response_time), MAX(max_response_time)
+		FROM (`+parts+`)
+		GROUP BY bucket, endpoint, category, source
+		ORDER BY bucket
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rollups := []RequestRollup{}
+	for rows.Next() {
+		var rollup RequestRollup
+		var bucket string
+		if err := rows.Scan(&bucket, &rollup.Endpoint, &rollup.Category, &rollup.Source, &rollup.Requests, &rollup.Successes,
+			&rollup.Failures, &rollup.Fallbacks, &rollup.TimedRequests, &rollup.TotalResponseTime, &rollup.MaxResponseTime); err != nil {
+			return nil, err
+		}
+		if rollup.Bucket, err = time.Parse(sqliteTimeLayout, bucket); err != nil {
+			return nil, err
+		}
+		rollups = append(rollups, rollup)
+	}
+
+	return rollups, rows.Err()
+}
+
+// DeleteRequestLogsBefore deletes the request logs older than a point in time
+// that are already rolled up, and returns how many it deleted
+func (db *DB) DeleteRequestLogsBefore(before time.Time) (int64, error) {
+	hourly, err := rollupWatermark(db, hourlyRollupTable)
+	if err != nil {
+		return 0, err
+	}
+	return db.deleteBefore("request_logs", "created_at", minTime(before.UTC().Format(sqliteTimeLayout), hourly))
+}
+
+// DeleteRequestRollupsBefore deletes the rollups of a granularity older than a
+// point in time; hourly rollups are kept until their day is rolled up
+func (db *DB) DeleteRequestRollupsBefore(granularity string, before time.Time) (int64, error) {
+	cutoff := before.UTC().Format(sqliteTimeLayout)
+	switch granularity {
+	case RollupHourly:
+		daily, err := rollupWatermark(db, dailyRollupTable)
+		if err != nil {
+			return 0, err
+		}
+		return db.deleteBefore(hourlyRollupTable, "bucket", minTime(cutoff, daily))
+	case RollupDaily:
+		return db.deleteBefore(dailyRollupTable, "bucket", cutoff)
+	default:
+		return 0, fmt.Errorf("unknown rollup granularity %q", granularity)
+	}
+}
+
+// DeleteHealthChecksBefore deletes the health checks older than a point in time
+func (db *DB) DeleteHealthChecksBefore(before time.Time) (int64, error) {
+	return db.deleteBefore("health_checks", "checked_at", before.UTC().Format(sqliteTimeLayout))
+}
+
+// DeleteSourceOutcomesBefore deletes the hourly source outcomes older than a point in time
+func (db *DB) DeleteSourceOutcomesBefore(before time.Time) (int64, error) {
+	return db.deleteBefore("source_hourly_outcomes", "hour", before.UTC().Format(sqliteTimeLayout))
+}
+
+// deleteBefore deletes the rows of a table whose time column is before cutoff,
+// in batches of pruneBatchSize
+func (db *DB) deleteBefore(table, column, cutoff string) (int64, error) {
+	var total int64
+	for {
+		result, err := db.Exec(`DELETE FROM `+table+` WHERE rowid IN (SELECT rowid FROM `+table+` WHERE `+column+` < ? LIMIT ?)`, cutoff, pruneBatchSize)
+		if err != nil {
+			return total, err
+		}
+		deleted, err := result.RowsAffected()
+		if err != nil {
+			return total, err
+		}
+		total += deleted
+		if deleted < pruneBatchSize {
+			return total, nil
+		}
+	}
+}
+
+// minTime and maxTime compare times formatted with sqliteTimeLayout; "" is the earliest
+func minTime(a, b string) string {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxTime(a, b string) string {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,106 @@
+package database
+
+import "time"
+
+// sqliteTimeLayout is how SQLite's CURRENT_TIMESTAMP and datetime() format times
+const sqliteTimeLayout = "2006-01-02 15:04:05"
+
+// HealthCheckRecord is a health check with the source and endpoint it probed
+type HealthCheckRecord struct {
+	SourceName   string
+	EndpointPath string
+	Status       string
+	ErrorMessage string
+	CheckedAt    time.Time
+}
+
+// SourceOutcome counts the live requests to a source endpoint in one hour
+type SourceOutcome struct {
+	SourceName   string
+	EndpointPath string
+	Hour         time.Time // Start of the UTC hour
+	Requests     int
+	Failures     int
+}
+
+// GetHealthCheckHistory returns the health checks since a point in time, oldest first
+func (db *DB) GetHealthCheckHistory(since time.Time) ([]HealthCheckRecord, error) {
+	rows, err := db.Query(`
+		SELECT a.source_name, e.path, h.status, COALESCE(h.error_message, ''), h.checked_at
+		FROM health_checks h
+		JOIN api_sources a ON a.id = h.api_source_id
+		JOIN endpoints e ON e.id = a.endpoint_id
+		WHERE h.checked_at >= ?
+		ORDER BY h.checked_at, h.id
+	`, since.UTC().Format(sqliteTimeLayout))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	records := []HealthCheckRecord{}
+	for rows.Next() {
+		var record HealthCheckRecord
+		if err := rows.Scan(&record.SourceName, &record.EndpointPath, &record.Status, &record.ErrorMessage, &record.CheckedAt); err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+
+	return records, rows.Err()
+}
+
+// AddSourceOutcomes adds request counts to their hourly rows in one transaction
+func (db *DB) AddSourceOutcomes(outcomes []SourceOutcome) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO source_hourly_outcomes (source_name, endpoint_path, hour, requests, failures)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (source_name, endpoint_path, hour) DO UPDATE SET
+			requests = requests + excluded.requests,
+			failures = failures + excluded.failures
+	`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, outcome := range outcomes {
+		hour := outcome.Hour.UTC().Truncate(time.Hour).Format(sqliteTimeLayout)
+		if _, err := stmt.Exec(outcome.SourceName, outcome.EndpointPath, hour, outcome.Requests, outcome.Failures); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetSourceOutcomes returns the hourly request counts since a point in time
+func (db *DB) GetSourceOutcomes(since time.Time) ([]SourceOutcome, error) {
+	rows, err := db.Query(`
+		SELECT source_name, endpoint_path, hour, requests, failures
+		FROM source_hourly_outcomes
+		WHERE hour >= ?
+		ORDER BY hour
+	`, since.UTC().Truncate(time.Hour).Format(sqliteTimeLayout))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	outcomes := []SourceOutcome{}
+	for rows.Next() {
+		var outcome SourceOutcome
+		if err := rows.Scan(&outcome.SourceName, &outcome.EndpointPath, &outcome.Hour, &outcome.Requests, &outcome.Failures); err != nil {
+			return nil, err
+		}
+		outcomes = append(outcomes, outcome)
+	}
+
+	return outcomes, rows.Err()
+}
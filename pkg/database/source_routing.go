@@ -0,0 +1,70 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Routing overrides of an API source
+const (
+	RoutingAuto         = "auto"          // Follow the latest health check
+	RoutingForceEnable  = "force_enable"  // Route as healthy whatever the health checks say
+	RoutingForceDisable = "force_disable" // Never route to the source
+)
+
+// SourceRouting is the routing override and latest health of an API source
+type SourceRouting struct {
+	APISourceID  int
+	Override     string
+	HealthStatus string // Latest health check status, empty when none is recent enough
+}
+
+// GetSourceRouting returns the routing override and the latest health check
+// status newer than maxAge of every API source, keyed by API source ID
+func (db *DB) GetSourceRouting(maxAge time.Duration) (map[int]SourceRouting, error) {
+	rows, err := db.Query(`
+		SELECT a.id, a.routing_override,
+			COALESCE((
+				SELECT h.status FROM health_checks h
+				WHERE h.api_source_id = a.id AND h.checked_at >= datetime('now', ?)
+				ORDER BY h.checked_at DESC, h.id DESC
+				LIMIT 1
+			), '')
+		FROM api_sources a
+	`, fmt.Sprintf("-%d seconds", int(maxAge.Seconds())))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	routing := make(map[int]SourceRouting)
+	for rows.Next() {
+		var r SourceRouting
+		if err := rows.Scan(&r.APISourceID, &r.Override, &r.HealthStatus); err != nil {
+			return nil, err
+		}
+		routing[r.APISourceID] = r
+	}
+
+	return routing, rows.Err()
+}
+
+// GetRoutingOverride returns the routing override of an API source; found is
+// false when the source does not exist
+func (db *DB) GetRoutingOverride(apiSourceID int) (override string, found bool, err error) {
+	err = db.QueryRow(`SELECT routing_override FROM api_sources WHERE id = ?`, apiSourceID).Scan(&override)
+	if err == sql.ErrNoRows {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return override, true, nil
+}
+
+// SetRoutingOverride stores the routing override of an API source
+func (db *DB) SetRoutingOverride(apiSourceID int, override string) error {
+	_, err := db.Exec(`UPDATE api_sources SET routing_override = ?, updated_at = datetime('now') WHERE id = ?`, override, apiSourceID)
+	return err
+}
@@ -0,0 +1,43 @@
+package database
+
+import "database/sql"
+
+// GetTransformSpecs returns the non-empty response transform specs, keyed by API source ID
+func (db *DB) GetTransformSpecs() (map[int]string, error) {
+	rows, err := db.Query(`SELECT id, transform_spec FROM api_sources WHERE transform_spec != ''`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	specs := make(map[int]string)
+	for rows.Next() {
+		var id int
+		var spec string
+		if err := rows.Scan(&id, &spec); err != nil {
+			return nil, err
+		}
+		specs[id] = spec
+	}
+
+	return specs, rows.Err()
+}
+
+// GetTransformSpec returns the response transform spec of an API source; found
+// is false when the source does not exist
+func (db *DB) GetTransformSpec(apiSourceID int) (spec string, found bool, err error) {
+	err = db.QueryRow(`SELECT transform_spec FROM api_sources WHERE id = ?`, apiSourceID).Scan(&spec)
+	if err == sql.ErrNoRows {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return spec, true, nil
+}
+
+// SetTransformSpec stores the response transform spec of an API source
+func (db *DB) SetTransformSpec(apiSourceID int, spec string) error {
+	_, err := db.Exec(`UPDATE api_sources SET transform_spec = ?, updated_at = datetime('now') WHERE id = ?`, spec, apiSourceID)
+	return err
+}
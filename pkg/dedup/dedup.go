@@ -0,0 +1,338 @@
+package dedup
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Matcher names
+const (
+	MatcherExact = "exact" // Same anime_slug, else same judul, else same url
+	MatcherFuzzy = "fuzzy" // Also similar normalized titles with the same episode number
+)
+
+// DefaultThreshold is the title similarity above which fuzzy items match
+const DefaultThreshold = 0.85
+
+// DefaultSuffixes are stripped from the end of titles before comparing them
+var DefaultSuffixes = []string{
+	"subtitle indonesia", "sub indonesia", "sub indo", "indo sub", "sub",
+	"batch", "tamat",
+}
+
+// Item is a list entry and the source it came from
+type Item struct {
+	Fields map[string]interface{}
+	Source string
+}
+
+// Matcher reports whether two list entries describe the same title
+type Matcher interface {
+	Match(a, b Item) bool
+}
+
+// NewMatcher returns the matcher with the given name; threshold and suffixes
+// only apply to the fuzzy matcher, where zero values use the defaults
+func NewMatcher(name string, threshold float64, suffixes []string) (Matcher, error) {
+	switch name {
+	case MatcherExact:
+		return ExactMatcher{}, nil
+	case MatcherFuzzy, "":
+		if threshold == 0 {
+			threshold = DefaultThreshold
+		}
+		if threshold < 0 || threshold > 1 {
+			return nil, fmt.Errorf("similarity threshold must be between 0 and 1")
+		}
+		if suffixes == nil {
+			suffixes = DefaultSuffixes
+		}
+		return &FuzzyMatcher{Threshold: threshold, Suffixes: suffixes}, nil
+	default:
+		return nil, fmt.Errorf("unknown dedup matcher %q", name)
+	}
+}
+
+// ExactMatcher matches items on their first identifier: anime_slug, then judul, then url
+type ExactMatcher struct{}
+
+// Match implements Matcher
+func (ExactMatcher) Match(a, b Item) bool {
+	keyA, keyB := exactKey(a.Fields), exactKey(b.Fields)
+	return keyA != "" && keyA == keyB
+}
+
+func exactKey(fields map[string]interface{}) string {
+	for _, name := range []string{"anime_slug", "judul", "url"} {
+		if value, exists := fields[name]; exists {
+			return name + ":" + fmt.Sprintf("%v", value)
+		}
+	}
+	return ""
+}
+
+// FuzzyMatcher matches items with the same URL or slug, or with normalized
+// titles at least Threshold similar. Items whose episode numbers differ never
+// match, nor do similar titles with different season, part or other numbers.
+type FuzzyMatcher struct {
+	Threshold float64
+	Suffixes  []string
+}
+
+// Match implements Matcher
+func (m *FuzzyMatcher) Match(a, b Item) bool {
+	if sameString(a.Fields, b.Fields, "url") {
+		return true
+	}
+
+	baseA, episodeA := m.title(a.Fields)
+	baseB, episodeB := m.title(b.Fields)
+	if episodeA != "" && episodeB != "" && episodeA != episodeB {
+		return false
+	}
+	if sameString(a.Fields, b.Fields, "anime_slug") {
+		return true
+	}
+	if baseA == "" || baseB == "" || episodeA != episodeB {
+		return false
+	}
+	if !sameNumbers(baseA, baseB) {
+		return false
+	}
+	return Similarity(baseA, baseB) >= m.Threshold
+}
+
+// numberPattern finds the numbers left in a normalized title, such as the
+// season in "season 2" or "2nd season" or a sequel's trailing number
+var numberPattern = regexp.MustCompile(`\d+`)
+
+// sameNumbers reports whether two normalized titles contain the same numbers
+// in the same order, ignoring leading zeros
+func sameNumbers(a, b string) bool {
+	numbersA, numbersB := numberPattern.FindAllString(a, -1), numberPattern.FindAllString(b, -1)
+	if len(numbersA) != len(numbersB) {
+		return false
+	}
+	for i := range numbersA {
+		if strings.TrimLeft(numbersA[i], "0") != strings.TrimLeft(numbersB[i], "0") {
+			return false
+		}
+	}
+	return true
+}
+
+// title returns the normalized judul of an item and its episode number, taken
+// from the title or else from the episode field
+func (m *FuzzyMatcher) title(fields map[string]interface{}) (base, episode string) {
+	if judul, ok := fields["judul"].(string); ok {
+		base, episode = NormalizeTitle(judul, m.Suffixes)
+	}
+	if value, exists := fields["episode"]; exists && episode == "" && value != nil {
+		_, episode = NormalizeTitle(fmt.Sprintf("episode %v", value), nil)
+	}
+	return base, episode
+}
+
+func sameString(a, b map[string]interface{}, field string) bool {
+	valueA, okA := a[field].(string)
+	valueB, okB := b[field].(string)
+	return okA && okB && valueA != "" && valueA == valueB
+}
+
+// episodePattern finds episode markers such as "Episode 12", "Ep. 12" or "Eps 12"
+var episodePattern = regexp.MustCompile(`\b(?:episode|eps|ep)\s*(\d+)\b`)
+
+// NormalizeTitle lowercases a title, replaces punctuation with spaces, extracts
+// its episode number and strips the given suffixes from its end
+func NormalizeTitle(title string, suffixes []string) (base, episode string) {
+	normalized := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return ' '
+	}, title)
+	normalized = strings.Join(strings.Fields(normalized), " ")
+
+	if match := episodePattern.FindStringSubmatchIndex(normalized); match != nil {
+		episode = strings.TrimLeft(normalized[match[2]:match[3]], "0")
+		if episode == "" {
+			episode = "0"
+		}
+		normalized = normalized[:match[0]] + normalized[match[1]:]
+		normalized = strings.Join(strings.Fields(normalized), " ")
+	}
+
+	for trimmed := true; trimmed; {
+		trimmed = false
+		for _, suffix := range suffixes {
+			if normalized == suffix || !strings.HasSuffix(normalized, " "+suffix) {
+				continue
+			}
+			normalized = strings.TrimSpace(strings.TrimSuffix(normalized, suffix))
+			trimmed = true
+		}
+	}
+
+	return normalized, episode
+}
+
+// Similarity is the Sørensen-Dice coefficient of the character bigrams of two
+// strings, ignoring spaces: 1 for equal strings, 0 for nothing in common
+func Similarity(a, b string) float64 {
+	a, b = strings.ReplaceAll(a, " ", ""), strings.ReplaceAll(b, " ", "")
+	if a == b {
+		return 1
+	}
+	bigramsA, bigramsB := bigrams(a), bigrams(b)
+	if len(bigramsA) == 0 || len(bigramsB) == 0 {
+		return 0
+	}
+
+	counts := make(map[string]int, len(bigramsA))
+	for _, bigram := range bigramsA {
+		counts[bigram]++
+	}
+	common := 0
+	for _, bigram := range bigramsB {
+		if counts[bigram] > 0 {
+			counts[bigram]--
+			common++
+		}
+	}
+	return 2 * float64(common) / float64(len(bigramsA)+len(bigramsB))
+}
+
+func bigrams(s string) []string {
+	runes := []rune(s)
+	if len(runes) < 2 {
+		return nil
+	}
+	result := make([]string, 0, len(runes)-1)
+	for i := 0; i < len(runes)-1; i++ {
+		result = append(result, string(runes[i:i+2]))
+	}
+	return result
+}
+
+// Alternate is a source's URL and slug for a deduplicated item
+type Alternate struct {
+	Source string `json:"source"`
+	URL    string `json:"url,omitempty"`
+	Slug   string `json:"anime_slug,omitempty"`
+	title  string // Normalized judul, for Group.Exact
+}
+
+// Group is an item found in more than one source
+type Group struct {
+	Title      string
+	Alternates []Alternate
+	Exact      bool // Every source gave the same normalized title, slug or URL
+}
+
+// Deduplicator collects list items from several sources, in priority order,
+// merging each item into the first earlier item from another source it matches
+type Deduplicator struct {
+	matcher Matcher
+	entries []*entry
+}
+
+type entry struct {
+	first      Item
+	alternates []Alternate
+}
+
+// New creates a deduplicator using the given matcher
+func New(matcher Matcher) *Deduplicator {
+	return &Deduplicator{matcher: matcher}
+}
+
+// Add adds an item and reports whether it was new
+func (d *Deduplicator) Add(item Item) bool {
+	for _, existing := range d.entries {
+		if existing.hasSource(item.Source) {
+			continue // A source lists each of its titles once, so these differ
+		}
+		if d.matcher.Match(existing.first, item) {
+			existing.alternates = append(existing.alternates, d.alternate(item))
+			return false
+		}
+	}
+	d.entries = append(d.entries, &entry{first: item, alternates: []Alternate{d.alternate(item)}})
+	return true
+}
+
+func (e *entry) hasSource(source string) bool {
+	for _, alternate := range e.alternates {
+		if alternate.Source == source {
+			return true
+		}
+	}
+	return false
+}
+
+// Items returns the deduplicated items in the order they were first added.
+// Items found in more than one source list every source URL under "alternates".
+func (d *Deduplicator) Items() []interface{} {
+	items := make([]interface{}, 0, len(d.entries))
+	for _, e := range d.entries {
+		if len(e.alternates) == 1 {
+			items = append(items, e.first.Fields)
+			continue
+		}
+
+		fields := make(map[string]interface{}, len(e.first.Fields)+1)
+		for key, value := range e.first.Fields {
+			fields[key] = value
+		}
+		fields["alternates"] = e.alternates
+		items = append(items, fields)
+	}
+	return items
+}
+
+// Groups returns the items found in more than one source
+func (d *Deduplicator) Groups() []Group {
+	var groups []Group
+	for _, e := range d.entries {
+		if len(e.alternates) > 1 {
+			title, _ := e.first.Fields["judul"].(string)
+			groups = append(groups, Group{Title: title, Alternates: e.alternates, Exact: e.exact()})
+		}
+	}
+	return groups
+}
+
+// exact reports whether all alternates agree on their title, slug or URL
+func (e *entry) exact() bool {
+	for _, field := range []func(Alternate) string{
+		func(a Alternate) string { return a.title },
+		func(a Alternate) string { return a.Slug },
+		func(a Alternate) string { return a.URL },
+	} {
+		same := field(e.alternates[0]) != ""
+		for _, alt := range e.alternates[1:] {
+			same = same && field(alt) == field(e.alternates[0])
+		}
+		if same {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *Deduplicator) alternate(item Item) Alternate {
+	url, _ := item.Fields["url"].(string)
+	slug, _ := item.Fields["anime_slug"].(string)
+	alt := Alternate{Source: item.Source, URL: url, Slug: slug}
+	if judul, ok := item.Fields["judul"].(string); ok {
+		suffixes := DefaultSuffixes
+		if fuzzy, ok := d.matcher.(*FuzzyMatcher); ok {
+			suffixes = fuzzy.Suffixes
+		}
+		base, episode := NormalizeTitle(judul, suffixes)
+		alt.title = strings.TrimSpace(base + " " + episode)
+	}
+	return alt
+}
@@ -0,0 +1,153 @@
+package dedup
+
+import "testing"
+
+func TestNormalizeTitle(t *testing.T) {
+	tests := []struct {
+		title   string
+		base    string
+		episode string
+	}{
+		{"One Piece Episode 1090 Sub Indo", "one piece", "1090"},
+		{"One Piece Ep 1090", "one piece", "1090"},
+		{"ONE PIECE - Eps.05 [Subtitle Indonesia]", "one piece", "5"},
+		{"Sousou no Frieren (Batch) Sub Indo", "sousou no frieren", ""},
+		{"Sub", "sub", ""},
+	}
+	for _, tt := range tests {
+		base, episode := NormalizeTitle(tt.title, DefaultSuffixes)
+		if base != tt.base || episode != tt.episode {
+			t.Errorf("NormalizeTitle(%q) = %q, %q, want %q, %q", tt.title, base, episode, tt.base, tt.episode)
+		}
+	}
+}
+
+func TestFuzzyMatcher(t *testing.T) {
+	matcher, err := NewMatcher(MatcherFuzzy, 0, nil)
+	if err != nil {
+		t.Fatalf("NewMatcher: %v", err)
+	}
+
+	item := func(fields map[string]interface{}) Item { return Item{Fields: fields} }
+	tests := []struct {
+		name string
+		a, b map[string]interface{}
+		want bool
+	}{
+		{"same episode, different wording",
+			map[string]interface{}{"judul": "One Piece Episode 1090 Sub Indo"},
+			map[string]interface{}{"judul": "One Piece Ep 1090"}, true},
+		{"different episodes of the same slug",
+			map[string]interface{}{"judul": "One Piece Episode 1090", "anime_slug": "one-piece"},
+			map[string]interface{}{"judul": "One Piece Episode 1091", "anime_slug": "one-piece"}, false},
+		{"same slug",
+			map[string]interface{}{"judul": "Frieren", "anime_slug": "frieren"},
+			map[string]interface{}{"judul": "Sousou no Frieren", "anime_slug": "frieren"}, true},
+		{"episode field",
+			map[string]interface{}{"judul": "Frieren", "episode": "12"},
+			map[string]interface{}{"judul": "Frieren Episode 12"}, true},
+		{"different titles",
+			map[string]interface{}{"judul": "One Piece"},
+			map[string]interface{}{"judul": "One Piece Film Red"}, false},
+		{"different seasons",
+			map[string]interface{}{"judul": "Shingeki no Kyojin Season 2"},
+			map[string]interface{}{"judul": "Shingeki no Kyojin Season 3"}, false},
+		{"different sequels",
+			map[string]interface{}{"judul": "Kimetsu no Yaiba 2"},
+			map[string]interface{}{"judul": "Kimetsu no Yaiba 3"}, false},
+		{"sequel and original",
+			map[string]interface{}{"judul": "Kimetsu no Yaiba"},
+			map[string]interface{}{"judul": "Kimetsu no Yaiba 2"}, false},
+		{"same season, different wording",
+			map[string]interface{}{"judul": "Shingeki no Kyojin Season 02 Sub Indo"},
+			map[string]interface{}{"judul": "Shingeki no Kyojin Season 2"}, true},
+	}
+	for _, tt := range tests {
+		if got := matcher.Match(item(tt.a), item(tt.b)); got != tt.want {
+			t.Errorf("%s: Match() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	if _, err := NewMatcher("soundex", 0, nil); err == nil {
+		t.Error("Unknown matcher should return error")
+	}
+}
+
+func TestDeduplicatorKeepsAlternates(t *testing.T) {
+	matcher, _ := NewMatcher(MatcherFuzzy, 0, nil)
+	d := New(matcher)
+
+	d.Add(Item{Source: "a", Fields: map[string]interface{}{"judul": "One Piece Episode 1090 Sub Indo", "url": "https://a.example/op-1090"}})
+	d.Add(Item{Source: "a", Fields: map[string]interface{}{"judul": "Frieren Episode 1", "url": "https://a.example/frieren-1"}})
+	if d.Add(Item{Source: "b", Fields: map[string]interface{}{"judul": "One Piece Ep 1090", "url": "https://b.example/op-1090"}}) {
+		t.Error("Duplicate item should not be added")
+	}
+
+	items := d.Items()
+	if len(items) != 2 {
+		t.Fatalf("Items() returned %d items, want 2", len(items))
+	}
+	first := items[0].(map[string]interface{})
+	alternates, ok := first["alternates"].([]Alternate)
+	if !ok || len(alternates) != 2 || alternates[1].URL != "https://b.example/op-1090" {
+		t.Errorf("alternates = %v", first["alternates"])
+	}
+	if _, exists := items[1].(map[string]interface{})["alternates"]; exists {
+		t.Error("Items found in one source should not have alternates")
+	}
+}
+
+func TestDeduplicatorGroups(t *testing.T) {
+	d := New(ExactMatcher{})
+
+	d.Add(Item{Source: "a", Fields: map[string]interface{}{"judul": "One Piece", "anime_slug": "one-piece", "url": "https://a.example/op"}})
+	d.Add(Item{Source: "a", Fields: map[string]interface{}{"judul": "Frieren", "anime_slug": "frieren", "url": "https://a.example/frieren"}})
+	d.Add(Item{Source: "b", Fields: map[string]interface{}{"judul": "One Piece", "anime_slug": "one-piece", "url": "https://b.example/op"}})
+
+	groups := d.Groups()
+	if len(groups) != 1 {
+		t.Fatalf("Groups() returned %d groups, want 1", len(groups))
+	}
+	if groups[0].Title != "One Piece" || len(groups[0].Alternates) != 2 || groups[0].Alternates[1].Slug != "one-piece" || !groups[0].Exact {
+		t.Errorf("Groups() = %+v", groups)
+	}
+}
+
+func TestDeduplicatorGroupsExact(t *testing.T) {
+	matcher, _ := NewMatcher(MatcherFuzzy, 0, nil)
+	d := New(matcher)
+
+	d.Add(Item{Source: "a", Fields: map[string]interface{}{"judul": "One Piece Episode 1090 Sub Indo", "anime_slug": "one-piece"}})
+	d.Add(Item{Source: "b", Fields: map[string]interface{}{"judul": "ONE PIECE Ep 1090", "anime_slug": "1piece"}})
+	d.Add(Item{Source: "a", Fields: map[string]interface{}{"judul": "Sousou no Frieren", "anime_slug": "frieren"}})
+	d.Add(Item{Source: "b", Fields: map[string]interface{}{"judul": "Sousou no Frieren S", "anime_slug": "frieren-s"}})
+
+	groups := d.Groups()
+	if len(groups) != 2 {
+		t.Fatalf("Groups() returned %d groups, want 2", len(groups))
+	}
+	if !groups[0].Exact {
+		t.Errorf("Titles equal after normalization should be exact: %+v", groups[0])
+	}
+	if groups[1].Exact {
+		t.Errorf("Merely similar titles should not be exact: %+v", groups[1])
+	}
+}
+
+func TestDeduplicatorOnlyMergesAcrossSources(t *testing.T) {
+	matcher, _ := NewMatcher(MatcherFuzzy, 0, nil)
+	d := New(matcher)
+
+	d.Add(Item{Source: "a", Fields: map[string]interface{}{"judul": "Frieren Episode 1", "url": "https://a.example/frieren-1"}})
+	if !d.Add(Item{Source: "a", Fields: map[string]interface{}{"judul": "Frieren Ep 1", "url": "https://a.example/frieren-1-batch"}}) {
+		t.Error("Similar items from the same source should both be kept")
+	}
+	if d.Add(Item{Source: "b", Fields: map[string]interface{}{"judul": "Frieren Episode 1", "url": "https://b.example/frieren-1"}}) {
+		t.Error("Matching item from another source should be merged")
+	}
+
+	groups := d.Groups()
+	if len(groups) != 1 || len(groups[0].Alternates) != 2 || groups[0].Alternates[1].Source != "b" {
+		t.Errorf("Groups() = %+v", groups)
+	}
+}
@@ -0,0 +1,218 @@
+package merge
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// Field merge strategies
+const (
+	StrategyPriority    = "priority"     // Value of the highest-priority source where it is non-empty
+	StrategyLongestList = "longest_list" // List with the most items, ties going to the higher priority
+	StrategyUnionByURL  = "union_by_url" // Items of every source, deduplicated by their "url"; objects are merged key by key
+)
+
+// Strategies maps top-level field names to merge strategies. Fields without an
+// entry use StrategyPriority.
+type Strategies map[string]string
+
+// Validate checks that every strategy is known
+func (s Strategies) Validate() error {
+	for field, strategy := range s {
+		switch strategy {
+		case StrategyPriority, StrategyLongestList, StrategyUnionByURL:
+		default:
+			return fmt.Errorf("field %q: unknown merge strategy %q", field, strategy)
+		}
+	}
+	return nil
+}
+
+// Candidate is one source's payload; a lower Priority value wins
+type Candidate struct {
+	Source   string
+	Priority int
+	Data     map[string]interface{}
+}
+
+// Result is a merged payload and the sources each field came from, in
+// priority order. Fields whose values are all empty have no sources.
+type Result struct {
+	Data    map[string]interface{}
+	Sources map[string][]string
+}
+
+// Merge combines candidate payloads field by field
+func Merge(candidates []Candidate, strategies Strategies) Result {
+	ordered := make([]Candidate, len(candidates))
+	copy(ordered, candidates)
+	sort.SliceStable(ordered, func(i, j int) bool { return ordered[i].Priority < ordered[j].Priority })
+
+	fields := make(map[string]bool)
+	for _, candidate := range ordered {
+		for field := range candidate.Data {
+			fields[field] = true
+		}
+	}
+
+	result := Result{
+		Data:    make(map[string]interface{}, len(fields)),
+		Sources: make(map[string][]string, len(fields)),
+	}
+	for field := range fields {
+		var value interface{}
+		var sources []string
+		switch strategies[field] {
+		case StrategyLongestList:
+			value, sources = longestList(ordered, field)
+		case StrategyUnionByURL:
+			value, sources = unionByURL(ordered, field)
+		default:
+			value, sources = highestPriority(ordered, field)
+		}
+		result.Data[field] = value
+		if len(sources) > 0 {
+			result.Sources[field] = sources
+		}
+	}
+
+	return result
+}
+
+// highestPriority returns the first non-empty value, or the first value when all are empty
+func highestPriority(candidates []Candidate, field string) (interface{}, []string) {
+	var fallback interface{}
+	found := false
+	for _, candidate := range candidates {
+		value, exists := candidate.Data[field]
+		if !exists {
+			continue
+		}
+		if !isEmpty(value) {
+			return value, []string{candidate.Source}
+		}
+		if !found {
+			fallback, found = value, true
+		}
+	}
+	return fallback, nil
+}
+
+func longestList(candidates []Candidate, field string) (interface{}, []string) {
+	var best []interface{}
+	var source string
+	for _, candidate := range candidates {
+		list, ok := candidate.Data[field].([]interface{})
+		if ok && (source == "" || len(list) > len(best)) {
+			best, source = list, candidate.Source
+		}
+	}
+	if source == "" || len(best) == 0 {
+		return highestPriority(candidates, field)
+	}
+	return best, []string{source}
+}
+
+func unionByURL(candidates []Candidate, field string) (interface{}, []string) {
+	var merged interface{}
+	var sources []string
+	for _, candidate := range candidates {
+		value, exists := candidate.Data[field]
+		if !exists || isEmpty(value) {
+			continue
+		}
+		if merged == nil {
+			merged = value
+			sources = append(sources, candidate.Source)
+			continue
+		}
+		var added bool
+		if merged, added = union(merged, value); added {
+			sources = append(sources, candidate.Source)
+		}
+	}
+	if merged == nil {
+		return highestPriority(candidates, field)
+	}
+	return merged, sources
+}
+
+// union adds the items of next that base lacks; objects are merged key by
+// key and other values keep base. added reports whether next contributed.
+func union(base, next interface{}) (merged interface{}, added bool) {
+	switch typed := base.(type) {
+	case []interface{}:
+		items, ok := next.([]interface{})
+		if !ok {
+			return base, false
+		}
+		seen := make(map[string]bool, len(typed))
+		for _, item := range typed {
+			seen[itemKey(item)] = true
+		}
+		result := append([]interface{}{}, typed...)
+		for _, item := range items {
+			key := itemKey(item)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			result = append(result, item)
+			added = true
+		}
+		return result, added
+	case map[string]interface{}:
+		object, ok := next.(map[string]interface{})
+		if !ok {
+			return base, false
+		}
+		result := make(map[string]interface{}, len(typed))
+		for key, value := range typed {
+			result[key] = value
+		}
+		for key, value := range object {
+			existing, exists := result[key]
+			if !exists || isEmpty(existing) {
+				if !isEmpty(value) {
+					result[key] = value
+					added = true
+				}
+				continue
+			}
+			var keyAdded bool
+			if result[key], keyAdded = union(existing, value); keyAdded {
+				added = true
+			}
+		}
+		return result, added
+	default:
+		return base, false
+	}
+}
+
+// itemKey identifies list items by their "url", or by their JSON encoding
+func itemKey(item interface{}) string {
+	if object, ok := item.(map[string]interface{}); ok {
+		if url, ok := object["url"].(string); ok && url != "" {
+			return "url:" + url
+		}
+	}
+	data, _ := json.Marshal(item)
+	return "json:" + string(data)
+}
+
+func isEmpty(value interface{}) bool {
+	switch typed := value.(type) {
+	case nil:
+		return true
+	case string:
+		return typed == ""
+	case []interface{}:
+		return len(typed) == 0
+	case map[string]interface{}:
+		return len(typed) == 0
+	default:
+		return false
+	}
+}
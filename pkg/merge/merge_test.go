@@ -0,0 +1,104 @@
+package merge
+
+import (
+	"reflect"
+	"testing"
+)
+
+func episodes(n int) []interface{} {
+	list := make([]interface{}, n)
+	for i := range list {
+		list[i] = map[string]interface{}{"episode": float64(i + 1)}
+	}
+	return list
+}
+
+func TestMergeStrategies(t *testing.T) {
+	candidates := []Candidate{
+		{Source: "fallback", Priority: 2, Data: map[string]interface{}{
+			"judul":        "Title B",
+			"synopsis":     "Full synopsis",
+			"episode_list": episodes(12),
+			"streaming_servers": []interface{}{
+				map[string]interface{}{"server": "b", "url": "https://b.example/1"},
+				map[string]interface{}{"server": "a", "url": "https://a.example/1"},
+			},
+		}},
+		{Source: "primary", Priority: 1, Data: map[string]interface{}{
+			"judul":        "Title A",
+			"synopsis":     "",
+			"episode_list": episodes(3),
+			"streaming_servers": []interface{}{
+				map[string]interface{}{"server": "a", "url": "https://a.example/1"},
+			},
+		}},
+	}
+
+	result := Merge(candidates, Strategies{
+		"episode_list":      StrategyLongestList,
+		"streaming_servers": StrategyUnionByURL,
+	})
+
+	if result.Data["judul"] != "Title A" {
+		t.Errorf("judul = %v, want the primary's", result.Data["judul"])
+	}
+	if result.Data["synopsis"] != "Full synopsis" {
+		t.Errorf("synopsis = %v, want the first non-empty value", result.Data["synopsis"])
+	}
+	if list := result.Data["episode_list"].([]interface{}); len(list) != 12 {
+		t.Errorf("episode_list has %d items, want 12", len(list))
+	}
+	if servers := result.Data["streaming_servers"].([]interface{}); len(servers) != 2 {
+		t.Errorf("streaming_servers has %d items, want 2", len(servers))
+	}
+
+	wantSources := map[string][]string{
+		"judul":             {"primary"},
+		"synopsis":          {"fallback"},
+		"episode_list":      {"fallback"},
+		"streaming_servers": {"primary", "fallback"},
+	}
+	if !reflect.DeepEqual(result.Sources, wantSources) {
+		t.Errorf("Sources = %v, want %v", result.Sources, wantSources)
+	}
+}
+
+func TestUnionMergesObjectsByKey(t *testing.T) {
+	candidates := []Candidate{
+		{Source: "a", Priority: 1, Data: map[string]interface{}{
+			"download_links": map[string]interface{}{
+				"480p": []interface{}{map[string]interface{}{"url": "https://a.example/480"}},
+			},
+		}},
+		{Source: "b", Priority: 2, Data: map[string]interface{}{
+			"download_links": map[string]interface{}{
+				"480p": []interface{}{map[string]interface{}{"url": "https://a.example/480"}},
+				"720p": []interface{}{map[string]interface{}{"url": "https://b.example/720"}},
+			},
+		}},
+		{Source: "c", Priority: 3, Data: map[string]interface{}{
+			"download_links": map[string]interface{}{
+				"480p": []interface{}{map[string]interface{}{"url": "https://a.example/480"}},
+			},
+		}},
+	}
+
+	result := Merge(candidates, Strategies{"download_links": StrategyUnionByURL})
+
+	links := result.Data["download_links"].(map[string]interface{})
+	if len(links["480p"].([]interface{})) != 1 || len(links["720p"].([]interface{})) != 1 {
+		t.Errorf("download_links = %v", links)
+	}
+	if !reflect.DeepEqual(result.Sources["download_links"], []string{"a", "b"}) {
+		t.Errorf("Sources = %v, want [a b]", result.Sources["download_links"])
+	}
+}
+
+func TestStrategiesValidate(t *testing.T) {
+	if err := (Strategies{"episode_list": "newest"}).Validate(); err == nil {
+		t.Error("Unknown strategy should return error")
+	}
+	if err := (Strategies{"episode_list": StrategyLongestList}).Validate(); err != nil {
+		t.Errorf("Valid strategies should not return error: %v", err)
+	}
+}
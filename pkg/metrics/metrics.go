@@ -0,0 +1,205 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "apigateway"
+
+// Upstream roles
+const (
+	RolePrimary  = "primary"
+	RoleFallback = "fallback"
+)
+
+// Upstream attempt outcomes
+const (
+	OutcomeSuccess   = "success"
+	OutcomeError     = "error"
+	OutcomeInvalid   = "invalid" // Response failed validation
+	OutcomeCancelled = "cancelled"
+)
+
+// Cache lookup results
+const (
+	CacheHit          = "hit"
+	CacheMiss         = "miss"
+	CacheStale        = "stale"          // Served stale while revalidating
+	CacheStaleIfError = "stale_if_error" // Served stale after all sources failed
+)
+
+// CategoryUnknown labels requests whose category is not a configured one
+const CategoryUnknown = "unknown"
+
+// Request results (which kind of source served the response)
+const (
+	ResultPrimary  = "primary"
+	ResultFallback = "fallback"
+	ResultFailed   = "failed"
+)
+
+// Registry holds all gateway metrics plus the Go runtime and process collectors
+var Registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests handled by the gateway.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Latency of HTTP requests handled by the gateway.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	upstreamRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "upstream_requests_total",
+		Help:      "Requests made to upstream sources, by outcome.",
+	}, []string{"endpoint", "category", "source", "role", "outcome"})
+
+	upstreamDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "upstream_request_duration_seconds",
+		Help:      "Latency of requests to upstream sources.",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 20, 30},
+	}, []string{"endpoint", "category", "source", "role"})
+
+	validationFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "validation_failures_total",
+		Help:      "Upstream responses rejected by response validation.",
+	}, []string{"endpoint", "category", "source"})
+
+	requestResults = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "upstream_results_total",
+		Help:      "Upstream fetches by whether a primary source, a fallback or nothing served them.",
+	}, []string{"endpoint", "category", "result"})
+
+	cacheLookups = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cache_lookups_total",
+		Help:      "Cache lookups by result (hit, miss, stale, stale_if_error).",
+	}, []string{"endpoint", "category", "result"})
+
+	bruteforceWins = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "bruteforce_wins_total",
+		Help:      "Detail requests won by each source in the concurrent bruteforce.",
+	}, []string{"endpoint", "category", "source", "role"})
+
+	sourceHealth = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "source_health_status",
+		Help:      "Result of the last health check per source (1 = OK, 0 = failing).",
+	}, []string{"source", "endpoint"})
+
+	healthChecks = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "health_checks_total",
+		Help:      "Health checks run per source, by status.",
+	}, []string{"source", "status"})
+
+	healthCheckDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "health_check_duration_seconds",
+		Help:      "Latency of health checks per source.",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+	}, []string{"source"})
+
+	circuitState = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "circuit_breaker_state",
+		Help:      "Circuit breaker state per source (0 = closed, 1 = half-open, 2 = open).",
+	}, []string{"source_id", "source"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests,
+		httpDuration,
+		upstreamRequests,
+		upstreamDuration,
+		validationFailures,
+		requestResults,
+		cacheLookups,
+		bruteforceWins,
+		sourceHealth,
+		healthChecks,
+		healthCheckDuration,
+		circuitState,
+	)
+}
+
+// Handler serves the metrics in the Prometheus exposition format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// ObserveHTTPRequest records one request handled by the gateway. route is the
+// matched route pattern, not the raw path, to keep label cardinality bounded.
+func ObserveHTTPRequest(method, route string, status int, duration time.Duration) {
+	httpRequests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+	httpDuration.WithLabelValues(method, route).Observe(duration.Seconds())
+}
+
+// ObserveUpstream records one request to an upstream source
+func ObserveUpstream(endpoint, category, source, role, outcome string, duration time.Duration) {
+	upstreamRequests.WithLabelValues(endpoint, category, source, role, outcome).Inc()
+	if outcome != OutcomeCancelled {
+		upstreamDuration.WithLabelValues(endpoint, category, source, role).Observe(duration.Seconds())
+	}
+	if outcome == OutcomeInvalid {
+		validationFailures.WithLabelValues(endpoint, category, source).Inc()
+	}
+}
+
+// ObserveResult records which kind of source served an upstream fetch
+func ObserveResult(endpoint, category, result string) {
+	requestResults.WithLabelValues(endpoint, category, result).Inc()
+}
+
+// ObserveCacheLookup records a cache lookup result
+func ObserveCacheLookup(endpoint, category, result string) {
+	cacheLookups.WithLabelValues(endpoint, category, result).Inc()
+}
+
+// ObserveBruteforceWin records the source that won a bruteforce race
+func ObserveBruteforceWin(endpoint, category, source, role string) {
+	bruteforceWins.WithLabelValues(endpoint, category, source, role).Inc()
+}
+
+// ObserveHealthCheck records the result of a health check
+func ObserveHealthCheck(source, endpoint, status string, duration time.Duration) {
+	healthy := 0.0
+	if status == "OK" {
+		healthy = 1
+	}
+	sourceHealth.WithLabelValues(source, endpoint).Set(healthy)
+	healthChecks.WithLabelValues(source, status).Inc()
+	healthCheckDuration.WithLabelValues(source).Observe(duration.Seconds())
+}
+
+// SetCircuitState records the circuit breaker state of a source
+func SetCircuitState(sourceID int, source, state string) {
+	value := 0.0
+	switch state {
+	case "half-open":
+		value = 1
+	case "open":
+		value = 2
+	}
+	circuitState.WithLabelValues(strconv.Itoa(sourceID), source).Set(value)
+}
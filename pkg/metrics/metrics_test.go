@@ -0,0 +1,64 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestObserveUpstream(t *testing.T) {
+	ObserveUpstream("/api/v1/home", "anime", "src1", RolePrimary, OutcomeSuccess, 100*time.Millisecond)
+	ObserveUpstream("/api/v1/home", "anime", "src1", RoleFallback, OutcomeInvalid, 200*time.Millisecond)
+
+	if got := testutil.ToFloat64(upstreamRequests.WithLabelValues("/api/v1/home", "anime", "src1", RolePrimary, OutcomeSuccess)); got != 1 {
+		t.Errorf("Expected 1 successful upstream request, got %v", got)
+	}
+	if got := testutil.ToFloat64(validationFailures.WithLabelValues("/api/v1/home", "anime", "src1")); got != 1 {
+		t.Errorf("Expected 1 validation failure, got %v", got)
+	}
+}
+
+func TestObserveHealthCheck(t *testing.T) {
+	ObserveHealthCheck("src1", "/api/v1/home", "OK", 50*time.Millisecond)
+	if got := testutil.ToFloat64(sourceHealth.WithLabelValues("src1", "/api/v1/home")); got != 1 {
+		t.Errorf("Expected healthy gauge 1, got %v", got)
+	}
+
+	ObserveHealthCheck("src1", "/api/v1/home", "ERROR", 50*time.Millisecond)
+	if got := testutil.ToFloat64(sourceHealth.WithLabelValues("src1", "/api/v1/home")); got != 0 {
+		t.Errorf("Expected healthy gauge 0, got %v", got)
+	}
+}
+
+func TestSetCircuitState(t *testing.T) {
+	SetCircuitState(7, "src1", "open")
+	if got := testutil.ToFloat64(circuitState.WithLabelValues("7", "src1")); got != 2 {
+		t.Errorf("Expected open state 2, got %v", got)
+	}
+
+	SetCircuitState(7, "src1", "closed")
+	if got := testutil.ToFloat64(circuitState.WithLabelValues("7", "src1")); got != 0 {
+		t.Errorf("Expected closed state 0, got %v", got)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	ObserveCacheLookup("/api/v1/home", "anime", CacheHit)
+
+	w := httptest.NewRecorder()
+	Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", w.Code)
+	}
+	if !strings.Contains(w.Body.String(), `apigateway_cache_lookups_total{category="anime",endpoint="/api/v1/home",result="hit"}`) {
+		t.Error("Expected cache lookup metric in output")
+	}
+	if !strings.Contains(w.Body.String(), "go_goroutines") {
+		t.Error("Expected Go runtime metrics in output")
+	}
+}
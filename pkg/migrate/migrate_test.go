@@ -0,0 +1,112 @@
+package migrate
+
+import (
+	"database/sql"
+	"testing"
+	"testing/fstest"
+
+	_ "modernc.org/sqlite"
+)
+
+func testFS() fstest.MapFS {
+	return fstest.MapFS{
+		"0001_create_items.up.sql":   {Data: []byte(`CREATE TABLE items (id INTEGER PRIMARY KEY); CREATE INDEX idx_items ON items (id);`)},
+		"0001_create_items.down.sql": {Data: []byte(`DROP TABLE items;`)},
+		"0002_add_name.up.sql":       {Data: []byte(`ALTER TABLE items ADD COLUMN name TEXT;`)},
+		"0002_add_name.down.sql":     {Data: []byte(`ALTER TABLE items DROP COLUMN name;`)},
+	}
+}
+
+func openDB(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1) // Every connection to :memory: is a separate database
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestLoadOrdersAndValidates(t *testing.T) {
+	migrations, err := Load(testFS())
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(migrations) != 2 || migrations[0].Version != 1 || migrations[1].Name != "add_name" {
+		t.Fatalf("Unexpected migrations: %+v", migrations)
+	}
+
+	bad := fstest.MapFS{"create.sql": {Data: []byte(`SELECT 1`)}}
+	if _, err := Load(bad); err == nil {
+		t.Error("Expected error for invalid file name")
+	}
+
+	downOnly := fstest.MapFS{"0001_x.down.sql": {Data: []byte(`SELECT 1`)}}
+	if _, err := Load(downOnly); err == nil {
+		t.Error("Expected error for migration without up file")
+	}
+}
+
+func TestUpDownStatus(t *testing.T) {
+	db := openDB(t)
+	m, err := New(db, testFS())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done, err := m.Up()
+	if err != nil || len(done) != 2 {
+		t.Fatalf("Expected 2 applied migrations, got %d (%v)", len(done), err)
+	}
+	if _, err := db.Exec(`INSERT INTO items (id, name) VALUES (1, 'a')`); err != nil {
+		t.Fatalf("Schema not migrated: %v", err)
+	}
+
+	// Up is idempotent
+	if done, err := m.Up(); err != nil || len(done) != 0 {
+		t.Fatalf("Expected no pending migrations, got %d (%v)", len(done), err)
+	}
+
+	if done, err := m.Down(1); err != nil || len(done) != 1 || done[0].Version != 2 {
+		t.Fatalf("Expected rollback of version 2, got %+v (%v)", done, err)
+	}
+	if version, _ := m.Version(); version != 1 {
+		t.Errorf("Expected version 1 after rollback, got %d", version)
+	}
+
+	statuses, err := m.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !statuses[0].Applied || statuses[1].Applied {
+		t.Errorf("Unexpected status: %+v", statuses)
+	}
+}
+
+func TestFailedMigrationRollsBack(t *testing.T) {
+	db := openDB(t)
+	fsys := testFS()
+	fsys["0003_broken.up.sql"] = &fstest.MapFile{Data: []byte(`CREATE TABLE other (id INTEGER); INSERT INTO missing VALUES (1);`)}
+
+	m, err := New(db, fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done, err := m.Up()
+	if err == nil {
+		t.Fatal("Expected broken migration to fail")
+	}
+	if len(done) != 2 {
+		t.Errorf("Expected the 2 good migrations to be applied, got %d", len(done))
+	}
+	if version, _ := m.Version(); version != 2 {
+		t.Errorf("Expected version 2, got %d", version)
+	}
+
+	var count int
+	db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE name = 'other'`).Scan(&count)
+	if count != 0 {
+		t.Error("Expected partial migration to be rolled back")
+	}
+}
@@ -0,0 +1,210 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Event types
+const (
+	EventHealthChanged    = "health_changed"     // A source's health check status changed
+	EventCircuitChanged   = "circuit_changed"    // A source's circuit opened, or closed again
+	EventAllSourcesFailed = "all_sources_failed" // No source answered a request for an endpoint
+	EventTest             = "test"               // Sent on demand to check a channel; not subscribable
+)
+
+// EventTypes are the event types channels can subscribe to
+var EventTypes = []string{EventHealthChanged, EventCircuitChanged, EventAllSourcesFailed}
+
+// Channel kinds, which decide the payload format
+const (
+	KindWebhook  = "webhook"  // The event as JSON
+	KindSlack    = "slack"    // {"text": ...} for Slack-compatible incoming webhooks
+	KindTelegram = "telegram" // {"chat_id": ..., "text": ...} for the Telegram sendMessage API
+)
+
+// Event is a state change worth telling someone about
+type Event struct {
+	Type     string    `json:"type"`
+	Source   string    `json:"source,omitempty"` // Empty for endpoint events
+	Endpoint string    `json:"endpoint,omitempty"`
+	Category string    `json:"category,omitempty"`
+	From     string    `json:"from,omitempty"` // Previous state
+	To       string    `json:"to,omitempty"`   // New state
+	Message  string    `json:"message,omitempty"`
+	At       time.Time `json:"at"`
+}
+
+// key identifies repeats of the same change for debouncing
+func (e Event) key() string {
+	return strings.Join([]string{e.Type, e.Source, e.Endpoint, e.Category, e.To}, "|")
+}
+
+// Text renders the event as a one-line human readable message
+func (e Event) Text() string {
+	var text string
+	switch e.Type {
+	case EventHealthChanged:
+		text = fmt.Sprintf("Source %s on %s is %s (was %s)", e.Source, e.Endpoint, e.To, e.From)
+	case EventCircuitChanged:
+		text = fmt.Sprintf("Circuit of source %s on %s is %s (was %s)", e.Source, e.Endpoint, e.To, e.From)
+	case EventAllSourcesFailed:
+		text = fmt.Sprintf("All sources failed for %s", e.Endpoint)
+		if e.Category != "" {
+			text += fmt.Sprintf(" in category %s", e.Category)
+		}
+	case EventTest:
+		text = "Test notification from API Fallback"
+	default:
+		text = e.Type
+	}
+	if e.Message != "" {
+		text += ": " + e.Message
+	}
+	return text
+}
+
+// Channel is a destination for events and the rules deciding which events it gets
+type Channel struct {
+	ID         int
+	Name       string
+	Kind       string
+	URL        string
+	ChatID     string        // Telegram chat, only used by KindTelegram
+	EventTypes []string      // Empty for every type
+	Sources    []string      // Empty for every source; endpoint events always match
+	Endpoints  []string      // Empty for every endpoint
+	Debounce   time.Duration // Repeats of the same change within this period are dropped
+}
+
+// Matches reports whether the channel's routing rules accept the event
+func (c Channel) Matches(event Event) bool {
+	if !matchAny(c.EventTypes, event.Type) {
+		return false
+	}
+	if event.Source != "" && !matchAny(c.Sources, event.Source) {
+		return false
+	}
+	return matchAny(c.Endpoints, strings.TrimSuffix(event.Endpoint, "/"))
+}
+
+func matchAny(values []string, value string) bool {
+	if len(values) == 0 {
+		return true
+	}
+	for _, candidate := range values {
+		if strings.TrimSuffix(candidate, "/") == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Payload renders the request body the channel's kind expects
+func Payload(channel Channel, event Event) ([]byte, error) {
+	switch channel.Kind {
+	case KindWebhook:
+		return json.Marshal(event)
+	case KindSlack:
+		return json.Marshal(map[string]string{"text": event.Text()})
+	case KindTelegram:
+		return json.Marshal(map[string]string{"chat_id": channel.ChatID, "text": event.Text()})
+	default:
+		return nil, fmt.Errorf("unknown channel kind %q", channel.Kind)
+	}
+}
+
+// Notifier sends events to the channels whose rules match, dropping repeats
+// of the same change per channel within the channel's debounce period
+type Notifier struct {
+	client *http.Client
+
+	mu       sync.Mutex
+	lastSent map[string]time.Time // channel ID and event key -> last send
+	now      func() time.Time
+}
+
+// New creates a notifier sending with client
+func New(client *http.Client) *Notifier {
+	return &Notifier{
+		client:   client,
+		lastSent: make(map[string]time.Time),
+		now:      time.Now,
+	}
+}
+
+// Notify sends event to every matching channel that is not debouncing it, in
+// the background. It returns the channels the event is sent to.
+func (n *Notifier) Notify(event Event, channels []Channel, onError func(Channel, error)) []Channel {
+	var selected []Channel
+	for _, channel := range channels {
+		if channel.Matches(event) && n.allow(channel, event) {
+			selected = append(selected, channel)
+		}
+	}
+
+	for _, channel := range selected {
+		go func(channel Channel) {
+			if err := n.Send(context.Background(), channel, event); err != nil && onError != nil {
+				onError(channel, err)
+			}
+		}(channel)
+	}
+	return selected
+}
+
+// allow records a send of the event to the channel unless one happened within its debounce period
+func (n *Notifier) allow(channel Channel, event Event) bool {
+	key := fmt.Sprintf("%d|%s", channel.ID, event.key())
+	now := n.now()
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if last, exists := n.lastSent[key]; exists && now.Sub(last) < channel.Debounce {
+		return false
+	}
+	n.lastSent[key] = now
+
+	// Forget keys that no longer debounce anything
+	for other, last := range n.lastSent {
+		if now.Sub(last) > 24*time.Hour {
+			delete(n.lastSent, other)
+		}
+	}
+	return true
+}
+
+// Send posts the event to one channel, ignoring rules and debouncing
+func (n *Notifier) Send(ctx context.Context, channel Channel, event Event) error {
+	body, err := Payload(channel, event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, channel.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "APIFallback-Notifier/1.0")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestChannelMatches(t *testing.T) {
+	health := Event{Type: EventHealthChanged, Source: "alpha", Endpoint: "/api/v1/home", To: "ERROR"}
+	failed := Event{Type: EventAllSourcesFailed, Endpoint: "/api/v1/search"}
+
+	cases := []struct {
+		name    string
+		channel Channel
+		event   Event
+		want    bool
+	}{
+		{"no rules", Channel{}, health, true},
+		{"type", Channel{EventTypes: []string{EventCircuitChanged}}, health, false},
+		{"source", Channel{Sources: []string{"beta"}}, health, false},
+		{"source match", Channel{Sources: []string{"alpha"}}, health, true},
+		{"endpoint trailing slash", Channel{Endpoints: []string{"/api/v1/home/"}}, health, true},
+		{"endpoint event ignores sources", Channel{Sources: []string{"beta"}}, failed, true},
+		{"endpoint event filtered by endpoint", Channel{Endpoints: []string{"/api/v1/home"}}, failed, false},
+	}
+	for _, tc := range cases {
+		if got := tc.channel.Matches(tc.event); got != tc.want {
+			t.Errorf("%s: Matches = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestPayload(t *testing.T) {
+	event := Event{Type: EventHealthChanged, Source: "alpha", Endpoint: "/api/v1/home", From: "OK", To: "ERROR", Message: "HTTP 500"}
+
+	body, err := Payload(Channel{Kind: KindSlack}, event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var slack map[string]string
+	json.Unmarshal(body, &slack)
+	if slack["text"] != "Source alpha on /api/v1/home is ERROR (was OK): HTTP 500" {
+		t.Errorf("slack text = %q", slack["text"])
+	}
+
+	body, _ = Payload(Channel{Kind: KindTelegram, ChatID: "-100"}, event)
+	var telegram map[string]string
+	json.Unmarshal(body, &telegram)
+	if telegram["chat_id"] != "-100" || telegram["text"] == "" {
+		t.Errorf("telegram payload = %s", body)
+	}
+
+	body, _ = Payload(Channel{Kind: KindWebhook}, event)
+	var webhook Event
+	json.Unmarshal(body, &webhook)
+	if webhook.Type != EventHealthChanged || webhook.To != "ERROR" {
+		t.Errorf("webhook payload = %s", body)
+	}
+
+	if _, err := Payload(Channel{Kind: "email"}, event); err == nil {
+		t.Error("unknown kinds should fail")
+	}
+}
+
+func TestNotifyDebounces(t *testing.T) {
+	received := make(chan string, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- string(body)
+	}))
+	defer server.Close()
+
+	n := New(server.Client())
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	n.now = func() time.Time { return now }
+
+	channels := []Channel{{ID: 1, Kind: KindSlack, URL: server.URL, Debounce: 5 * time.Minute}}
+	down := Event{Type: EventHealthChanged, Source: "alpha", Endpoint: "/api/v1/home", From: "OK", To: "ERROR"}
+	up := Event{Type: EventHealthChanged, Source: "alpha", Endpoint: "/api/v1/home", From: "ERROR", To: "OK"}
+
+	if sent := n.Notify(down, channels, nil); len(sent) != 1 {
+		t.Fatalf("first event sent to %d channels, want 1", len(sent))
+	}
+	if sent := n.Notify(down, channels, nil); len(sent) != 0 {
+		t.Error("repeat within the debounce period should be dropped")
+	}
+	if sent := n.Notify(up, channels, nil); len(sent) != 1 {
+		t.Error("a different change should not be debounced")
+	}
+
+	now = now.Add(6 * time.Minute)
+	if sent := n.Notify(down, channels, nil); len(sent) != 1 {
+		t.Error("repeat after the debounce period should be sent")
+	}
+
+	for i := 0; i < 3; i++ {
+		select {
+		case <-received:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("only %d of 3 notifications arrived", i)
+		}
+	}
+}
+
+func TestSendFailsOnErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	err := New(server.Client()).Send(context.Background(), Channel{Kind: KindWebhook, URL: server.URL}, Event{Type: EventAllSourcesFailed})
+	if err == nil {
+		t.Error("Send should fail on HTTP 403")
+	}
+}
@@ -0,0 +1,100 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// Decision is the outcome of a rate limit check
+type Decision struct {
+	Allowed    bool
+	Limit      int           // Requests allowed per window
+	Remaining  int           // Requests left in the current window
+	Reset      time.Time     // When the current window ends
+	RetryAfter time.Duration // How long to wait before retrying (0 when allowed)
+}
+
+// Limiter is a fixed-window request counter keyed by client identity
+// (API key, IP address, ...). Each key can have its own limit and window.
+type Limiter struct {
+	mu      sync.Mutex
+	windows map[string]*window
+	now     func() time.Time
+	checks  int
+}
+
+type window struct {
+	start time.Time
+	end   time.Time
+	count int
+}
+
+// sweepEvery controls how often expired windows are removed
+const sweepEvery = 1000
+
+// New creates an empty limiter
+func New() *Limiter {
+	return &Limiter{
+		windows: make(map[string]*window),
+		now:     time.Now,
+	}
+}
+
+// Allow counts a request for key and reports whether it fits in the limit.
+// A limit <= 0 means unlimited.
+func (l *Limiter) Allow(key string, limit int, period time.Duration) Decision {
+	now := l.now()
+
+	if limit <= 0 {
+		return Decision{Allowed: true, Limit: limit, Remaining: -1, Reset: now.Add(period)}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.checks++
+	if l.checks%sweepEvery == 0 {
+		l.sweep(now)
+	}
+
+	w, exists := l.windows[key]
+	if !exists || !now.Before(w.end) {
+		w = &window{start: now, end: now.Add(period)}
+		l.windows[key] = w
+	}
+
+	if w.count >= limit {
+		return Decision{
+			Allowed:    false,
+			Limit:      limit,
+			Remaining:  0,
+			Reset:      w.end,
+			RetryAfter: w.end.Sub(now),
+		}
+	}
+
+	w.count++
+	return Decision{
+		Allowed:   true,
+		Limit:     limit,
+		Remaining: limit - w.count,
+		Reset:     w.end,
+	}
+}
+
+// Reset forgets the counter for key
+func (l *Limiter) Reset(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.windows, key)
+}
+
+// sweep removes expired windows; caller must hold the lock
+func (l *Limiter) sweep(now time.Time) {
+	for key, w := range l.windows {
+		if !now.Before(w.end) {
+			delete(l.windows, key)
+		}
+	}
+}
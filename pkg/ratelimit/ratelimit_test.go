@@ -0,0 +1,69 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLimiterAllow(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter := New()
+	limiter.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		decision := limiter.Allow("ip:1.2.3.4", 3, time.Minute)
+		if !decision.Allowed {
+			t.Fatalf("Expected request %d to be allowed", i)
+		}
+		if decision.Remaining != 2-i {
+			t.Errorf("Expected remaining %d, got %d", 2-i, decision.Remaining)
+		}
+	}
+
+	decision := limiter.Allow("ip:1.2.3.4", 3, time.Minute)
+	if decision.Allowed {
+		t.Fatal("Expected request over the limit to be rejected")
+	}
+	if decision.RetryAfter != time.Minute {
+		t.Errorf("Expected retry after 1m, got %s", decision.RetryAfter)
+	}
+	if !decision.Reset.Equal(now.Add(time.Minute)) {
+		t.Errorf("Expected reset at %s, got %s", now.Add(time.Minute), decision.Reset)
+	}
+
+	// Other keys have their own counter
+	if !limiter.Allow("ip:5.6.7.8", 3, time.Minute).Allowed {
+		t.Error("Expected a different key to be allowed")
+	}
+
+	// A new window starts after the period
+	now = now.Add(time.Minute)
+	decision = limiter.Allow("ip:1.2.3.4", 3, time.Minute)
+	if !decision.Allowed || decision.Remaining != 2 {
+		t.Errorf("Expected a fresh window, got %+v", decision)
+	}
+}
+
+func TestLimiterUnlimited(t *testing.T) {
+	limiter := New()
+
+	for i := 0; i < 100; i++ {
+		if !limiter.Allow("key:1", 0, time.Minute).Allowed {
+			t.Fatal("Expected limit 0 to be unlimited")
+		}
+	}
+}
+
+func TestLimiterReset(t *testing.T) {
+	limiter := New()
+
+	limiter.Allow("key:1", 1, time.Minute)
+	if limiter.Allow("key:1", 1, time.Minute).Allowed {
+		t.Fatal("Expected second request to be rejected")
+	}
+
+	limiter.Reset("key:1")
+	if !limiter.Allow("key:1", 1, time.Minute).Allowed {
+		t.Error("Expected request to be allowed after reset")
+	}
+}
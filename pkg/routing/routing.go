@@ -0,0 +1,169 @@
+package routing
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Table matches request paths against path templates such as
+// /api/v1/jadwal-rilis/:day. Static segments take precedence over parameters,
+// so /api/v1/anime/popular wins over /api/v1/anime/:slug.
+type Table struct {
+	routes []route
+}
+
+type route struct {
+	pattern  string
+	segments []string
+}
+
+// NewTable creates an empty routing table
+func NewTable() *Table {
+	return &Table{}
+}
+
+// Validate checks that a pattern is an absolute path whose parameters are
+// named and unique
+func Validate(pattern string) error {
+	if !strings.HasPrefix(pattern, "/") {
+		return fmt.Errorf("path %q must start with /", pattern)
+	}
+
+	seen := make(map[string]bool)
+	for _, segment := range splitPath(pattern) {
+		if segment == "" {
+			return fmt.Errorf("path %q contains an empty segment", pattern)
+		}
+		if !strings.HasPrefix(segment, ":") {
+			continue
+		}
+
+		name := segment[1:]
+		if name == "" {
+			return fmt.Errorf("path %q has an unnamed parameter", pattern)
+		}
+		if seen[name] {
+			return fmt.Errorf("path %q uses parameter :%s more than once", pattern, name)
+		}
+		seen[name] = true
+	}
+
+	return nil
+}
+
+// Add registers a pattern; adding the same pattern twice is a no-op
+func (t *Table) Add(pattern string) error {
+	if err := Validate(pattern); err != nil {
+		return err
+	}
+
+	segments := splitPath(pattern)
+	for _, existing := range t.routes {
+		if existing.pattern == pattern {
+			return nil
+		}
+	}
+
+	t.routes = append(t.routes, route{pattern: pattern, segments: segments})
+	return nil
+}
+
+// Match returns the most specific pattern matching path and the values of its parameters
+func (t *Table) Match(path string) (string, map[string]string, bool) {
+	segments := splitPath(path)
+
+	var best *route
+	for i := range t.routes {
+		candidate := &t.routes[i]
+		if !candidate.matches(segments) {
+			continue
+		}
+		if best == nil || candidate.moreSpecificThan(best) {
+			best = candidate
+		}
+	}
+
+	if best == nil {
+		return "", nil, false
+	}
+
+	params := make(map[string]string)
+	for i, segment := range best.segments {
+		if strings.HasPrefix(segment, ":") {
+			params[segment[1:]] = segments[i]
+		}
+	}
+	return best.pattern, params, true
+}
+
+// Expand fills a pattern's parameters from params and returns the path and the
+// params left over for the query string. Every parameter needs a non-empty value.
+func Expand(pattern string, params map[string]string) (string, map[string]string, error) {
+	rest := make(map[string]string, len(params))
+	for name, value := range params {
+		rest[name] = value
+	}
+
+	segments := splitPath(pattern)
+	for i, segment := range segments {
+		if !strings.HasPrefix(segment, ":") {
+			continue
+		}
+		name := segment[1:]
+		value := rest[name]
+		if value == "" {
+			return "", nil, fmt.Errorf("path %q needs a value for :%s", pattern, name)
+		}
+		segments[i] = url.PathEscape(value)
+		delete(rest, name)
+	}
+
+	return "/" + strings.Join(segments, "/"), rest, nil
+}
+
+// Len returns the number of registered patterns
+func (t *Table) Len() int {
+	return len(t.routes)
+}
+
+func (r *route) matches(segments []string) bool {
+	if len(segments) != len(r.segments) {
+		return false
+	}
+
+	for i, segment := range r.segments {
+		if strings.HasPrefix(segment, ":") {
+			if segments[i] == "" {
+				return false
+			}
+			continue
+		}
+		if segment != segments[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// moreSpecificThan compares segment by segment: the first static segment where
+// the other route has a parameter wins
+func (r *route) moreSpecificThan(other *route) bool {
+	for i := range r.segments {
+		rParam := strings.HasPrefix(r.segments[i], ":")
+		otherParam := strings.HasPrefix(other.segments[i], ":")
+		if rParam != otherParam {
+			return !rParam
+		}
+	}
+	return false
+}
+
+// splitPath splits a path into segments, ignoring a trailing slash
+func splitPath(path string) []string {
+	path = strings.TrimSuffix(path, "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimPrefix(path, "/"), "/")
+}
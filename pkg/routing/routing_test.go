@@ -0,0 +1,90 @@
+package routing
+
+import "testing"
+
+func TestMatch(t *testing.T) {
+	table := NewTable()
+	for _, pattern := range []string{
+		"/api/v1/home",
+		"/api/v1/jadwal-rilis/:day",
+		"/api/v1/anime/:slug",
+		"/api/v1/anime/popular",
+		"/api/v1/anime/:slug/episodes/:episode",
+	} {
+		if err := table.Add(pattern); err != nil {
+			t.Fatalf("Add(%s) failed: %v", pattern, err)
+		}
+	}
+
+	tests := []struct {
+		path    string
+		pattern string
+		params  map[string]string
+	}{
+		{"/api/v1/home", "/api/v1/home", map[string]string{}},
+		{"/api/v1/home/", "/api/v1/home", map[string]string{}},
+		{"/api/v1/jadwal-rilis/senin", "/api/v1/jadwal-rilis/:day", map[string]string{"day": "senin"}},
+		{"/api/v1/anime/popular", "/api/v1/anime/popular", map[string]string{}},
+		{"/api/v1/anime/one-piece", "/api/v1/anime/:slug", map[string]string{"slug": "one-piece"}},
+		{"/api/v1/anime/one-piece/episodes/12", "/api/v1/anime/:slug/episodes/:episode", map[string]string{"slug": "one-piece", "episode": "12"}},
+	}
+
+	for _, tt := range tests {
+		pattern, params, ok := table.Match(tt.path)
+		if !ok || pattern != tt.pattern {
+			t.Errorf("Match(%s) = %s, %v; want %s", tt.path, pattern, ok, tt.pattern)
+			continue
+		}
+		if len(params) != len(tt.params) {
+			t.Errorf("Match(%s) params = %v; want %v", tt.path, params, tt.params)
+		}
+		for k, v := range tt.params {
+			if params[k] != v {
+				t.Errorf("Match(%s) param %s = %s; want %s", tt.path, k, params[k], v)
+			}
+		}
+	}
+
+	for _, path := range []string{"/api/v1/jadwal-rilis", "/api/v1/jadwal-rilis//", "/api/v1/unknown", "/api/v1/anime/a/b"} {
+		if pattern, _, ok := table.Match(path); ok {
+			t.Errorf("Match(%s) unexpectedly matched %s", path, pattern)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := []string{"/api/v1/home", "/api/v1/jadwal-rilis/:day", "/a/:x/b/:y"}
+	for _, pattern := range valid {
+		if err := Validate(pattern); err != nil {
+			t.Errorf("Validate(%s) failed: %v", pattern, err)
+		}
+	}
+
+	invalid := []string{"api/v1/home", "/api//home", "/api/:", "/api/:id/x/:id"}
+	for _, pattern := range invalid {
+		if err := Validate(pattern); err == nil {
+			t.Errorf("Validate(%s) should fail", pattern)
+		}
+	}
+}
+
+func TestExpand(t *testing.T) {
+	path, rest, err := Expand("/api/v1/jadwal-rilis/:day", map[string]string{"day": "senin", "page": "1"})
+	if err != nil {
+		t.Fatalf("Expand failed: %v", err)
+	}
+	if path != "/api/v1/jadwal-rilis/senin" {
+		t.Errorf("path = %s, want /api/v1/jadwal-rilis/senin", path)
+	}
+	if len(rest) != 1 || rest["page"] != "1" {
+		t.Errorf("rest = %v, want only page", rest)
+	}
+
+	if path, _, err := Expand("/api/v1/home/", nil); err != nil || path != "/api/v1/home" {
+		t.Errorf("Expand(/api/v1/home/) = %s, %v", path, err)
+	}
+
+	if _, _, err := Expand("/api/v1/anime/:slug", map[string]string{"id": "1"}); err == nil {
+		t.Error("Expand should fail when a parameter has no value")
+	}
+}
@@ -0,0 +1,358 @@
+package sla
+
+import (
+	"sort"
+	"time"
+)
+
+// StatusOK is the health check status of a source that is up; every other
+// status (DEGRADED, TIMEOUT, ERROR) counts as down. Callers leave out SKIPPED
+// checks, which did not probe the source.
+const StatusOK = "OK"
+
+// Check is one synthetic health check of a source endpoint
+type Check struct {
+	At      time.Time
+	Status  string
+	Message string
+}
+
+// Outcomes counts the live requests to a source endpoint in one hour
+type Outcomes struct {
+	Hour     time.Time // Start of the hour
+	Requests int
+	Failures int // Requests without a valid response
+}
+
+// Window is a reporting period split into timeline buckets
+type Window struct {
+	Name   string
+	Length time.Duration
+	Bucket time.Duration
+}
+
+// Windows are the supported reporting periods
+var Windows = []Window{
+	{Name: "24h", Length: 24 * time.Hour, Bucket: time.Hour},
+	{Name: "7d", Length: 7 * 24 * time.Hour, Bucket: 6 * time.Hour},
+	{Name: "30d", Length: 30 * 24 * time.Hour, Bucket: 24 * time.Hour},
+}
+
+// WindowByName returns the window with the given name
+func WindowByName(name string) (Window, bool) {
+	for _, window := range Windows {
+		if window.Name == name {
+			return window, true
+		}
+	}
+	return Window{}, false
+}
+
+// Start returns the start of the window's first bucket; buckets are aligned
+// to multiples of the bucket length, so the last one is still in progress
+func (w Window) Start(now time.Time) time.Time {
+	buckets := int(w.Length / w.Bucket)
+	return now.Truncate(w.Bucket).Add(-time.Duration(buckets-1) * w.Bucket)
+}
+
+// Incident is a run of failing health checks
+type Incident struct {
+	Start           time.Time  `json:"start"`
+	End             *time.Time `json:"end"`              // nil while ongoing
+	DurationSeconds int64      `json:"duration_seconds"` // Up to now while ongoing
+	Status          string     `json:"status"`           // Status of the first failing check
+	Message         string     `json:"message,omitempty"`
+	Endpoint        string     `json:"endpoint,omitempty"` // Set on combined reports
+}
+
+// Point is the availability of one timeline bucket, nil without data
+type Point struct {
+	Start  time.Time `json:"start"`
+	Uptime *float64  `json:"uptime"`
+}
+
+// Report is the availability of a source over a window. Percentages are nil
+// when there is no data to compute them from.
+type Report struct {
+	Window             string     `json:"window"`
+	Uptime             *float64   `json:"uptime"`               // Percent of time available, from both signals
+	ProbeUptime        *float64   `json:"probe_uptime"`         // Percent of probed time the health checks passed
+	RequestSuccessRate *float64   `json:"request_success_rate"` // Percent of live requests that got a valid response
+	Checks             int        `json:"checks"`
+	Requests           int        `json:"requests"`
+	Failures           int        `json:"failures"`
+	Incidents          []Incident `json:"incidents"`
+	MTTRSeconds        *int64     `json:"mttr_seconds"` // Mean time to recovery of resolved incidents
+	Timeline           []Point    `json:"timeline,omitempty"`
+}
+
+// bucket accumulates both signals for one timeline bucket
+type bucket struct {
+	start    time.Time
+	length   time.Duration // Shorter for the bucket in progress
+	up       time.Duration
+	covered  time.Duration
+	requests int
+	failures int
+}
+
+// availability is the lower of the probe and request availabilities of the
+// bucket, since a source is only as available as its worse signal
+func (b *bucket) availability() (float64, bool) {
+	value, ok := 1.0, false
+	if b.covered > 0 {
+		value, ok = float64(b.up)/float64(b.covered), true
+	}
+	if b.requests > 0 {
+		rate := float64(b.requests-b.failures) / float64(b.requests)
+		if rate < value {
+			value = rate
+		}
+		ok = true
+	}
+	return value, ok
+}
+
+// Compute reports the availability of one source endpoint over a window.
+// Each check's status holds until the next check, for at most maxAge; time
+// not covered by a check is left to the request outcomes. Checks should
+// include the last one before the window so its status carries in.
+func Compute(now time.Time, window Window, checks []Check, outcomes []Outcomes, maxAge time.Duration) Report {
+	start := window.Start(now)
+	buckets := make([]bucket, int(window.Length/window.Bucket))
+	for i := range buckets {
+		buckets[i].start = start.Add(time.Duration(i) * window.Bucket)
+		buckets[i].length = window.Bucket
+	}
+	last := &buckets[len(buckets)-1]
+	last.length = now.Sub(last.start)
+
+	checks = append([]Check(nil), checks...)
+	sort.SliceStable(checks, func(i, j int) bool { return checks[i].At.Before(checks[j].At) })
+
+	report := Report{Window: window.Name}
+
+	// Spread the time each check covers over the buckets
+	var up, covered time.Duration
+	for i, check := range checks {
+		end := check.At.Add(maxAge)
+		if i+1 < len(checks) && checks[i+1].At.Before(end) {
+			end = checks[i+1].At
+		}
+		if end.After(now) {
+			end = now
+		}
+		from := check.At
+		if from.Before(start) {
+			from = start
+		}
+		if !check.At.Before(start) {
+			report.Checks++
+		}
+
+		for from.Before(end) {
+			index := int(from.Sub(start) / window.Bucket)
+			to := buckets[index].start.Add(window.Bucket)
+			if to.After(end) {
+				to = end
+			}
+			buckets[index].covered += to.Sub(from)
+			covered += to.Sub(from)
+			if check.Status == StatusOK {
+				buckets[index].up += to.Sub(from)
+				up += to.Sub(from)
+			}
+			from = to
+		}
+	}
+
+	for _, outcome := range outcomes {
+		if outcome.Hour.Before(start) || outcome.Hour.After(now) {
+			continue
+		}
+		index := int(outcome.Hour.Sub(start) / window.Bucket)
+		buckets[index].requests += outcome.Requests
+		buckets[index].failures += outcome.Failures
+		report.Requests += outcome.Requests
+		report.Failures += outcome.Failures
+	}
+
+	// Weight each bucket with data by its length
+	var weighted float64
+	var weight time.Duration
+	report.Timeline = make([]Point, len(buckets))
+	for i := range buckets {
+		report.Timeline[i].Start = buckets[i].start
+		availability, ok := buckets[i].availability()
+		if !ok {
+			continue
+		}
+		report.Timeline[i].Uptime = percent(availability)
+		weighted += availability * float64(buckets[i].length)
+		weight += buckets[i].length
+	}
+	if weight > 0 {
+		report.Uptime = percent(weighted / float64(weight))
+	}
+	if covered > 0 {
+		report.ProbeUptime = percent(float64(up) / float64(covered))
+	}
+	if report.Requests > 0 {
+		report.RequestSuccessRate = percent(float64(report.Requests-report.Failures) / float64(report.Requests))
+	}
+
+	report.Incidents = incidents(now, start, checks, maxAge)
+	sortIncidents(report.Incidents)
+	report.MTTRSeconds = mttr(report.Incidents)
+	return report
+}
+
+// incidents returns the runs of failing checks overlapping the window. An
+// incident ends at the next passing check, or maxAge after its last check
+// when the checks stop.
+func incidents(now, start time.Time, checks []Check, maxAge time.Duration) []Incident {
+	found := []Incident{}
+	var open *Incident
+	var lastAt time.Time
+
+	closeAt := func(end time.Time) {
+		if !end.After(start) {
+			open = nil
+			return
+		}
+		open.End = &end
+		open.DurationSeconds = int64(end.Sub(open.Start) / time.Second)
+		found = append(found, *open)
+		open = nil
+	}
+
+	for _, check := range checks {
+		if open != nil && check.At.Sub(lastAt) > maxAge {
+			closeAt(lastAt.Add(maxAge))
+		}
+		lastAt = check.At
+
+		switch {
+		case check.Status == StatusOK && open != nil:
+			closeAt(check.At)
+		case check.Status != StatusOK && open == nil:
+			open = &Incident{Start: check.At, Status: check.Status, Message: check.Message}
+		}
+	}
+
+	if open != nil {
+		if now.Sub(lastAt) > maxAge {
+			closeAt(lastAt.Add(maxAge))
+		} else {
+			open.DurationSeconds = int64(now.Sub(open.Start) / time.Second)
+			found = append(found, *open)
+		}
+	}
+
+	return found
+}
+
+// sortIncidents orders incidents newest first
+func sortIncidents(incidents []Incident) {
+	sort.SliceStable(incidents, func(i, j int) bool {
+		return incidents[i].Start.After(incidents[j].Start)
+	})
+}
+
+// mttr returns the mean duration of the resolved incidents, or nil when there are none
+func mttr(incidents []Incident) *int64 {
+	var total int64
+	resolved := 0
+	for _, incident := range incidents {
+		if incident.End != nil {
+			total += incident.DurationSeconds
+			resolved++
+		}
+	}
+	if resolved == 0 {
+		return nil
+	}
+	mean := total / int64(resolved)
+	return &mean
+}
+
+// Combine merges the reports of a source's endpoints, keyed by endpoint path,
+// into one report for the source: availabilities are averaged over the
+// endpoints with data, counts are summed and incidents are listed together.
+func Combine(reports map[string]Report) Report {
+	endpoints := make([]string, 0, len(reports))
+	for endpoint := range reports {
+		endpoints = append(endpoints, endpoint)
+	}
+	sort.Strings(endpoints)
+
+	combined := Report{Incidents: []Incident{}}
+	var uptime, probeUptime mean
+	var timeline []mean
+	for _, endpoint := range endpoints {
+		report := reports[endpoint]
+		combined.Window = report.Window
+		combined.Checks += report.Checks
+		combined.Requests += report.Requests
+		combined.Failures += report.Failures
+		uptime.add(report.Uptime)
+		probeUptime.add(report.ProbeUptime)
+
+		for _, incident := range report.Incidents {
+			incident.Endpoint = endpoint
+			combined.Incidents = append(combined.Incidents, incident)
+		}
+
+		if timeline == nil && len(report.Timeline) > 0 {
+			timeline = make([]mean, len(report.Timeline))
+			combined.Timeline = make([]Point, len(report.Timeline))
+			for i, point := range report.Timeline {
+				combined.Timeline[i].Start = point.Start
+			}
+		}
+		for i, point := range report.Timeline {
+			if i < len(timeline) {
+				timeline[i].add(point.Uptime)
+			}
+		}
+	}
+
+	combined.Uptime = uptime.value()
+	combined.ProbeUptime = probeUptime.value()
+	if combined.Requests > 0 {
+		combined.RequestSuccessRate = percent(float64(combined.Requests-combined.Failures) / float64(combined.Requests))
+	}
+	for i := range timeline {
+		combined.Timeline[i].Uptime = timeline[i].value()
+	}
+
+	sortIncidents(combined.Incidents)
+	combined.MTTRSeconds = mttr(combined.Incidents)
+	return combined
+}
+
+// mean averages the percentages that are set
+type mean struct {
+	sum   float64
+	count int
+}
+
+func (m *mean) add(value *float64) {
+	if value != nil {
+		m.sum += *value
+		m.count++
+	}
+}
+
+func (m *mean) value() *float64 {
+	if m.count == 0 {
+		return nil
+	}
+	value := m.sum / float64(m.count)
+	return &value
+}
+
+func percent(fraction float64) *float64 {
+	value := fraction * 100
+	return &value
+}
@@ -0,0 +1,144 @@
+package sla
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+var day = Window{Name: "24h", Length: 24 * time.Hour, Bucket: time.Hour}
+
+func at(now time.Time, ago time.Duration) time.Time {
+	return now.Add(-ago)
+}
+
+func near(t *testing.T, name string, got *float64, want float64) {
+	t.Helper()
+	if got == nil {
+		t.Fatalf("%s = nil, want %.2f", name, want)
+	}
+	if math.Abs(*got-want) > 0.01 {
+		t.Errorf("%s = %.2f, want %.2f", name, *got, want)
+	}
+}
+
+func TestComputeProbeUptimeAndIncidents(t *testing.T) {
+	now := time.Date(2026, 1, 2, 12, 0, 0, 0, time.UTC)
+	checks := []Check{
+		{At: at(now, 4*time.Hour), Status: StatusOK},
+		{At: at(now, 3*time.Hour), Status: "ERROR", Message: "HTTP 500"},
+		{At: at(now, 2*time.Hour), Status: StatusOK},
+		{At: at(now, time.Hour), Status: StatusOK},
+	}
+
+	report := Compute(now, day, checks, nil, 2*time.Hour)
+
+	// 4 hours probed, 1 of them down
+	near(t, "probe_uptime", report.ProbeUptime, 75)
+	near(t, "uptime", report.Uptime, 75)
+	if report.RequestSuccessRate != nil {
+		t.Errorf("request_success_rate = %v, want nil without requests", *report.RequestSuccessRate)
+	}
+	if report.Checks != 4 {
+		t.Errorf("checks = %d, want 4", report.Checks)
+	}
+
+	if len(report.Incidents) != 1 {
+		t.Fatalf("incidents = %d, want 1", len(report.Incidents))
+	}
+	incident := report.Incidents[0]
+	if incident.End == nil || incident.DurationSeconds != 3600 || incident.Status != "ERROR" {
+		t.Errorf("incident = %+v, want a resolved 1h ERROR", incident)
+	}
+	if report.MTTRSeconds == nil || *report.MTTRSeconds != 3600 {
+		t.Errorf("mttr = %v, want 3600", report.MTTRSeconds)
+	}
+	if len(report.Timeline) != 24 || report.Timeline[0].Uptime != nil {
+		t.Errorf("timeline should have 24 buckets, the first without data")
+	}
+}
+
+func TestComputeOngoingAndStaleIncidents(t *testing.T) {
+	now := time.Date(2026, 1, 2, 12, 0, 0, 0, time.UTC)
+
+	ongoing := Compute(now, day, []Check{{At: at(now, 30*time.Minute), Status: "DEGRADED"}}, nil, time.Hour)
+	if len(ongoing.Incidents) != 1 || ongoing.Incidents[0].End != nil {
+		t.Fatalf("incidents = %+v, want one ongoing", ongoing.Incidents)
+	}
+	if ongoing.MTTRSeconds != nil {
+		t.Errorf("mttr = %d, want nil without resolved incidents", *ongoing.MTTRSeconds)
+	}
+
+	// Checks stopped: the incident ends maxAge after the last one
+	stale := Compute(now, day, []Check{{At: at(now, 5*time.Hour), Status: "TIMEOUT"}}, nil, time.Hour)
+	if len(stale.Incidents) != 1 || stale.Incidents[0].End == nil || stale.Incidents[0].DurationSeconds != 3600 {
+		t.Errorf("incidents = %+v, want one closed after 1h", stale.Incidents)
+	}
+}
+
+func TestComputeUsesWorseSignal(t *testing.T) {
+	now := time.Date(2026, 1, 2, 12, 0, 0, 0, time.UTC)
+	hour := now.Add(-time.Hour)
+	checks := []Check{{At: hour, Status: StatusOK}}
+	outcomes := []Outcomes{
+		{Hour: hour, Requests: 10, Failures: 5},
+		{Hour: now.Add(-3 * time.Hour), Requests: 4, Failures: 0},
+	}
+
+	report := Compute(now, day, checks, outcomes, time.Hour)
+
+	near(t, "probe_uptime", report.ProbeUptime, 100)
+	near(t, "request_success_rate", report.RequestSuccessRate, 100*9/14.0)
+	// Probed hour counts at the request rate (50%), the unprobed one at 100%
+	near(t, "uptime", report.Uptime, 75)
+	near(t, "timeline", report.Timeline[22].Uptime, 50)
+}
+
+func TestComputeIgnoresOldData(t *testing.T) {
+	now := time.Date(2026, 1, 2, 12, 0, 0, 0, time.UTC)
+	checks := []Check{{At: at(now, 48*time.Hour), Status: "ERROR"}}
+	outcomes := []Outcomes{{Hour: at(now, 48*time.Hour), Requests: 5, Failures: 5}}
+
+	report := Compute(now, day, checks, outcomes, time.Hour)
+	if report.Uptime != nil || report.Checks != 0 || report.Requests != 0 || len(report.Incidents) != 0 {
+		t.Errorf("report = %+v, want no data", report)
+	}
+}
+
+func TestCombine(t *testing.T) {
+	now := time.Date(2026, 1, 2, 12, 0, 0, 0, time.UTC)
+	search := Compute(now, day, []Check{
+		{At: at(now, 2*time.Hour), Status: "ERROR"},
+		{At: at(now, time.Hour), Status: StatusOK},
+	}, nil, time.Hour)
+	home := Compute(now, day, []Check{{At: at(now, 2*time.Hour), Status: StatusOK}}, []Outcomes{{Hour: at(now, time.Hour), Requests: 4, Failures: 1}}, 2*time.Hour)
+
+	combined := Combine(map[string]Report{"/api/v1/search": search, "/api/v1/home": home})
+
+	near(t, "uptime", combined.Uptime, (*search.Uptime+*home.Uptime)/2)
+	near(t, "request_success_rate", combined.RequestSuccessRate, 75)
+	if combined.Window != "24h" || combined.Checks != 3 || combined.Requests != 4 {
+		t.Errorf("combined = %+v", combined)
+	}
+	if len(combined.Incidents) != 1 || combined.Incidents[0].Endpoint != "/api/v1/search" {
+		t.Errorf("incidents = %+v, want the search incident", combined.Incidents)
+	}
+	near(t, "timeline", combined.Timeline[22].Uptime, 87.5)
+}
+
+func TestWindowByName(t *testing.T) {
+	for _, name := range []string{"24h", "7d", "30d"} {
+		if _, ok := WindowByName(name); !ok {
+			t.Errorf("WindowByName(%s) not found", name)
+		}
+	}
+	if _, ok := WindowByName("1y"); ok {
+		t.Error("WindowByName(1y) should not exist")
+	}
+
+	week, _ := WindowByName("7d")
+	now := time.Date(2026, 1, 2, 13, 30, 0, 0, time.UTC)
+	if start := week.Start(now); !start.Equal(time.Date(2025, 12, 26, 18, 0, 0, 0, time.UTC)) {
+		t.Errorf("7d start = %s", start)
+	}
+}
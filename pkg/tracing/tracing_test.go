@@ -0,0 +1,79 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func setupRecorder(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { provider.Shutdown(context.Background()) })
+	return recorder
+}
+
+func TestInitDisabled(t *testing.T) {
+	shutdown, err := Init(context.Background(), Settings{Enabled: false})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Errorf("Expected no-op shutdown, got %v", err)
+	}
+}
+
+func TestPropagation(t *testing.T) {
+	setupRecorder(t)
+
+	if TraceID(context.Background()) != "" {
+		t.Error("Expected empty trace ID without a span")
+	}
+
+	ctx, span := Start(context.Background(), "inbound")
+	defer span.End()
+
+	header := http.Header{}
+	Inject(ctx, header)
+	traceparent := header.Get("traceparent")
+	if !strings.Contains(traceparent, TraceID(ctx)) {
+		t.Fatalf("Expected traceparent to carry trace ID %s, got %q", TraceID(ctx), traceparent)
+	}
+
+	// The upstream side continues the same trace
+	remoteCtx, remoteSpan := Start(Extract(context.Background(), header), "upstream")
+	defer remoteSpan.End()
+	if TraceID(remoteCtx) != TraceID(ctx) {
+		t.Errorf("Expected trace ID %s after extract, got %s", TraceID(ctx), TraceID(remoteCtx))
+	}
+}
+
+func TestEndRecordsError(t *testing.T) {
+	recorder := setupRecorder(t)
+
+	_, span := Start(context.Background(), "failing")
+	End(span, errors.New("boom"))
+	_, span = Start(context.Background(), "ok")
+	End(span, nil)
+
+	ended := recorder.Ended()
+	if len(ended) != 2 {
+		t.Fatalf("Expected 2 ended spans, got %d", len(ended))
+	}
+	if ended[0].Status().Code != codes.Error || ended[0].Status().Description != "boom" {
+		t.Errorf("Expected error status, got %+v", ended[0].Status())
+	}
+	if ended[1].Status().Code != codes.Unset {
+		t.Errorf("Expected unset status, got %+v", ended[1].Status())
+	}
+}
@@ -0,0 +1,158 @@
+package traffic
+
+import (
+	"sort"
+	"time"
+)
+
+// Dimensions a report can be broken down by
+const (
+	ByEndpoint = "endpoint"
+	ByCategory = "category"
+	BySource   = "source"
+)
+
+// Dimensions are the supported breakdowns
+var Dimensions = []string{ByEndpoint, ByCategory, BySource}
+
+// Counts are request counts and response time sums, which add up across rows
+type Counts struct {
+	Requests          int   `json:"requests"`
+	Successes         int   `json:"successes"` // 2xx responses
+	Failures          int   `json:"failures"`  // 4xx and 5xx responses
+	Fallbacks         int   `json:"fallbacks"`
+	TimedRequests     int   `json:"-"`
+	TotalResponseTime int64 `json:"-"`
+	MaxResponseTime   int   `json:"max_response_time"` // Milliseconds
+}
+
+// Add adds other's counts
+func (c *Counts) Add(other Counts) {
+	c.Requests += other.Requests
+	c.Successes += other.Successes
+	c.Failures += other.Failures
+	c.Fallbacks += other.Fallbacks
+	c.TimedRequests += other.TimedRequests
+	c.TotalResponseTime += other.TotalResponseTime
+	if other.MaxResponseTime > c.MaxResponseTime {
+		c.MaxResponseTime = other.MaxResponseTime
+	}
+}
+
+// Stats are counts with the rates derived from them
+type Stats struct {
+	Counts
+	SuccessRate     *float64 `json:"success_rate"`      // Percent of requests answered with 2xx; nil without requests
+	AvgResponseTime *float64 `json:"avg_response_time"` // Milliseconds; nil without timed requests
+}
+
+// Stats derives the rates of the counts
+func (c Counts) Stats() Stats {
+	stats := Stats{Counts: c}
+	if c.Requests > 0 {
+		rate := float64(c.Successes) / float64(c.Requests) * 100
+		stats.SuccessRate = &rate
+	}
+	if c.TimedRequests > 0 {
+		avg := float64(c.TotalResponseTime) / float64(c.TimedRequests)
+		stats.AvgResponseTime = &avg
+	}
+	return stats
+}
+
+// Row is the counts of one bucket, endpoint, category and source
+type Row struct {
+	Bucket   time.Time
+	Endpoint string
+	Category string
+	Source   string
+	Counts
+}
+
+func (r Row) key(groupBy string) string {
+	switch groupBy {
+	case ByEndpoint:
+		return r.Endpoint
+	case ByCategory:
+		return r.Category
+	case BySource:
+		return r.Source
+	default:
+		return ""
+	}
+}
+
+// Point is one timeline bucket
+type Point struct {
+	Start time.Time `json:"start"`
+	Stats
+}
+
+// Series is the traffic of one endpoint, category or source
+type Series struct {
+	Key string `json:"key"`
+	Stats
+	Timeline []Point `json:"timeline"`
+}
+
+// Report is the traffic of a period, in total and broken down by a dimension
+type Report struct {
+	GroupBy string `json:"group_by,omitempty"`
+	Stats
+	Timeline []Point  `json:"timeline"`
+	Series   []Series `json:"series"` // Busiest first; empty without a breakdown
+}
+
+// Build adds rows up into a timeline of buckets of the given length from
+// start, in total and per key of the groupBy dimension ("" for none). Rows
+// before start are ignored and rows past the last bucket count towards it.
+func Build(start time.Time, bucket time.Duration, buckets int, groupBy string, rows []Row) Report {
+	total := make([]Counts, buckets)
+	perKey := make(map[string][]Counts)
+
+	for _, row := range rows {
+		if row.Bucket.Before(start) {
+			continue
+		}
+		i := int(row.Bucket.Sub(start) / bucket)
+		if i >= buckets {
+			i = buckets - 1
+		}
+
+		total[i].Add(row.Counts)
+		if groupBy == "" {
+			continue
+		}
+		key := row.key(groupBy)
+		if perKey[key] == nil {
+			perKey[key] = make([]Counts, buckets)
+		}
+		perKey[key][i].Add(row.Counts)
+	}
+
+	report := Report{GroupBy: groupBy, Series: []Series{}}
+	report.Stats, report.Timeline = timeline(start, bucket, total)
+	for key, counts := range perKey {
+		series := Series{Key: key}
+		series.Stats, series.Timeline = timeline(start, bucket, counts)
+		report.Series = append(report.Series, series)
+	}
+	sort.Slice(report.Series, func(i, j int) bool {
+		if report.Series[i].Requests != report.Series[j].Requests {
+			return report.Series[i].Requests > report.Series[j].Requests
+		}
+		return report.Series[i].Key < report.Series[j].Key
+	})
+	return report
+}
+
+// timeline returns the stats of all buckets together and of each bucket
+func timeline(start time.Time, bucket time.Duration, counts []Counts) (Stats, []Point) {
+	var sum Counts
+	points := make([]Point, len(counts))
+	for i, c := range counts {
+		sum.Add(c)
+		points[i] = Point{Start: start.Add(time.Duration(i) * bucket), Stats: c.Stats()}
+	}
+	return sum.Stats(), points
+}
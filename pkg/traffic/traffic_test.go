@@ -0,0 +1,61 @@
+package traffic
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBuild(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	rows := []Row{
+		{Bucket: start.Add(-time.Hour), Endpoint: "/api/v1/home", Source: "alpha", Counts: Counts{Requests: 50, Successes: 50}},
+		{Bucket: start, Endpoint: "/api/v1/home", Source: "alpha", Counts: Counts{Requests: 4, Successes: 3, Failures: 1, TimedRequests: 4, TotalResponseTime: 400, MaxResponseTime: 200}},
+		{Bucket: start, Endpoint: "/api/v1/search", Source: "beta", Counts: Counts{Requests: 2, Successes: 2, Fallbacks: 1, TimedRequests: 2, TotalResponseTime: 100, MaxResponseTime: 60}},
+		{Bucket: start.Add(3 * time.Hour), Endpoint: "/api/v1/home", Source: "beta", Counts: Counts{Requests: 4, Successes: 4}},
+		{Bucket: start.Add(9 * time.Hour), Endpoint: "/api/v1/search", Source: "beta", Counts: Counts{Requests: 2, Failures: 2}},
+	}
+
+	report := Build(start, 6*time.Hour, 2, ByEndpoint, rows)
+
+	if report.Requests != 12 || report.Successes != 9 || report.Failures != 3 || report.Fallbacks != 1 {
+		t.Errorf("totals = %+v, want 12 requests, 9 successes, 3 failures, 1 fallback", report.Counts)
+	}
+	if report.SuccessRate == nil || *report.SuccessRate != 75 {
+		t.Errorf("success rate = %v, want 75", report.SuccessRate)
+	}
+	if report.AvgResponseTime == nil || *report.AvgResponseTime != 500.0/6 {
+		t.Errorf("avg response time = %v, want %v", report.AvgResponseTime, 500.0/6)
+	}
+	if report.MaxResponseTime != 200 {
+		t.Errorf("max response time = %d, want 200", report.MaxResponseTime)
+	}
+
+	if len(report.Timeline) != 2 || report.Timeline[0].Requests != 10 || report.Timeline[1].Requests != 2 {
+		t.Fatalf("timeline = %+v, want 10 then 2 requests", report.Timeline)
+	}
+	if !report.Timeline[1].Start.Equal(start.Add(6 * time.Hour)) {
+		t.Errorf("second bucket starts at %v", report.Timeline[1].Start)
+	}
+	if report.Timeline[1].AvgResponseTime != nil {
+		t.Error("a bucket without timed requests should have no average")
+	}
+
+	if len(report.Series) != 2 || report.Series[0].Key != "/api/v1/home" || report.Series[0].Requests != 8 {
+		t.Fatalf("series = %+v, want /api/v1/home first with 8 requests", report.Series)
+	}
+	if search := report.Series[1]; search.Requests != 4 || search.Timeline[1].Failures != 2 {
+		t.Errorf("search series = %+v", search)
+	}
+}
+
+func TestBuildWithoutBreakdown(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	report := Build(start, time.Hour, 24, "", []Row{{Bucket: start, Source: "alpha", Counts: Counts{Requests: 1}}})
+
+	if len(report.Series) != 0 {
+		t.Errorf("series = %+v, want none", report.Series)
+	}
+	if len(report.Timeline) != 24 || report.Timeline[23].SuccessRate != nil {
+		t.Errorf("timeline should have 24 buckets, the empty ones without a success rate")
+	}
+}
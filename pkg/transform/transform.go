@@ -0,0 +1,411 @@
+package transform
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Operation kinds
+const (
+	OpMove    = "move"    // Move the value at From to To, replacing it
+	OpCopy    = "copy"    // Copy the value at From to To, replacing it
+	OpMerge   = "merge"   // Move the object at From into the object at To, keeping fields To already has
+	OpRename  = "rename"  // Rename the field at Path to the key To
+	OpDefault = "default" // Set Path to Value when it is missing or null
+	OpDelete  = "delete"  // Remove the field at Path
+	OpUnwrap  = "unwrap"  // Replace each element of the array at Path by its Field, or the array by its first element when Field is empty
+)
+
+// maxOperations bounds the size of a spec
+const maxOperations = 100
+
+// rootKey names the whole document in paths ("$")
+const rootKey = "$"
+
+// Spec is a declarative list of operations applied in order to a JSON response.
+//
+// Paths are dot separated field names relative to the document root, such as
+// data.anime_list. A "[]" suffix applies the rest of the path to every element
+// of an array (data.items[].title), and "$" is the whole document. Move, copy
+// and merge may use "[]" when From and To share the same prefix up to the last
+// "[]"; the operation then runs within each element.
+type Spec struct {
+	Operations []Operation `json:"operations"`
+}
+
+// Operation is a single transformation step
+type Operation struct {
+	Op    string      `json:"op"`
+	From  string      `json:"from,omitempty"`
+	Path  string      `json:"path,omitempty"`
+	To    string      `json:"to,omitempty"`
+	Field string      `json:"field,omitempty"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+type segment struct {
+	key  string
+	each bool
+}
+
+// compiled is an operation with parsed paths; scope is the shared prefix of
+// move, copy and merge paths that contain "[]"
+type compiled struct {
+	Operation
+	scope []segment
+	from  []segment
+	path  []segment
+}
+
+// Parse parses and validates a JSON spec. An empty string yields an empty spec.
+func Parse(raw string) (*Spec, error) {
+	spec := &Spec{}
+	if strings.TrimSpace(raw) == "" {
+		return spec, nil
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(spec); err != nil {
+		return nil, fmt.Errorf("invalid transform spec: %w", err)
+	}
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+
+	return spec, nil
+}
+
+// Validate checks every operation of the spec
+func (s *Spec) Validate() error {
+	_, err := s.compile()
+	return err
+}
+
+// IsEmpty reports whether the spec has no operations
+func (s *Spec) IsEmpty() bool {
+	return s == nil || len(s.Operations) == 0
+}
+
+// Apply transforms a JSON document
+func (s *Spec) Apply(data []byte) ([]byte, error) {
+	if s.IsEmpty() {
+		return data, nil
+	}
+
+	operations, err := s.compile()
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var document interface{}
+	if err := decoder.Decode(&document); err != nil {
+		return nil, fmt.Errorf("response is not valid JSON: %w", err)
+	}
+
+	// Wrapping the document lets operations replace the root like any other field
+	root := map[string]interface{}{rootKey: document}
+	for _, op := range operations {
+		for _, node := range collect(root, op.scope) {
+			op.apply(node)
+		}
+	}
+
+	return json.Marshal(root[rootKey])
+}
+
+func (s *Spec) compile() ([]compiled, error) {
+	if len(s.Operations) > maxOperations {
+		return nil, fmt.Errorf("transform spec has %d operations, at most %d are allowed", len(s.Operations), maxOperations)
+	}
+
+	operations := make([]compiled, 0, len(s.Operations))
+	for i, op := range s.Operations {
+		c, err := compileOperation(op)
+		if err != nil {
+			return nil, fmt.Errorf("operation %d (%s): %w", i+1, op.Op, err)
+		}
+		operations = append(operations, c)
+	}
+	return operations, nil
+}
+
+func compileOperation(op Operation) (compiled, error) {
+	c := compiled{Operation: op}
+
+	switch op.Op {
+	case OpMove, OpCopy, OpMerge:
+		from, err := parsePath(op.From, "from")
+		if err != nil {
+			return c, err
+		}
+		to, err := parsePath(op.To, "to")
+		if err != nil {
+			return c, err
+		}
+		c.scope, c.from, c.path, err = splitScope(from, to)
+		if err != nil {
+			return c, err
+		}
+		if len(c.from) == 0 || len(c.path) == 0 {
+			return c, fmt.Errorf("from and to must differ below their shared prefix")
+		}
+
+	case OpRename, OpDefault, OpDelete, OpUnwrap:
+		path, err := parsePath(op.Path, "path")
+		if err != nil {
+			return c, err
+		}
+		if len(path) == 1 {
+			return c, fmt.Errorf("path %q must name a field", op.Path)
+		}
+		c.path = path
+		if op.Op == OpRename && (op.To == "" || strings.ContainsAny(op.To, ".[]")) {
+			return c, fmt.Errorf("to must be a plain field name")
+		}
+		if op.Op == OpDefault && op.Value == nil {
+			return c, fmt.Errorf("value is required")
+		}
+
+	default:
+		return c, fmt.Errorf("unknown op %q", op.Op)
+	}
+
+	return c, nil
+}
+
+// parsePath parses a path into segments, starting with the root segment
+func parsePath(path, name string) ([]segment, error) {
+	if path == "" {
+		return nil, fmt.Errorf("%s is required", name)
+	}
+
+	segments := []segment{{key: rootKey}}
+	path = strings.TrimPrefix(path, rootKey)
+	if path == "" {
+		return segments, nil
+	}
+	path = strings.TrimPrefix(path, ".")
+
+	for _, part := range strings.Split(path, ".") {
+		seg := segment{key: strings.TrimSuffix(part, "[]")}
+		seg.each = seg.key != part
+		if seg.key == "" || strings.ContainsAny(seg.key, "[]") {
+			return nil, fmt.Errorf("invalid %s %q", name, path)
+		}
+		segments = append(segments, seg)
+	}
+	if segments[len(segments)-1].each {
+		return nil, fmt.Errorf("%s %q must not end with []", name, path)
+	}
+
+	return segments, nil
+}
+
+// splitScope separates the prefix shared by from and to up to their last "[]"
+// segment, so the operation can run within each array element
+func splitScope(from, to []segment) (scope, fromRest, toRest []segment, err error) {
+	last := lastEach(from)
+	if last != lastEach(to) {
+		return nil, nil, nil, fmt.Errorf("from and to must share their [] prefix")
+	}
+	for i := 0; i <= last; i++ {
+		if from[i] != to[i] {
+			return nil, nil, nil, fmt.Errorf("from and to must share their [] prefix")
+		}
+	}
+	return from[:last+1], from[last+1:], to[last+1:], nil
+}
+
+// lastEach returns the index of the last "[]" segment, or -1
+func lastEach(path []segment) int {
+	for i := len(path) - 1; i >= 0; i-- {
+		if path[i].each {
+			return i
+		}
+	}
+	return -1
+}
+
+func (c compiled) apply(node interface{}) {
+	switch c.Op {
+	case OpMove, OpCopy, OpMerge:
+		value, ok := get(node, c.from)
+		if !ok {
+			return
+		}
+		if c.Op == OpCopy {
+			value = deepCopy(value)
+		} else {
+			remove(node, c.from)
+		}
+
+		if c.Op == OpMerge {
+			source, isObject := value.(map[string]interface{})
+			target, exists := get(node, c.path)
+			if targetObject, ok := target.(map[string]interface{}); isObject && exists && ok {
+				for key, v := range source {
+					if _, taken := targetObject[key]; !taken {
+						targetObject[key] = v
+					}
+				}
+				return
+			}
+		}
+		set(node, c.path, value)
+
+	case OpRename:
+		visit(node, c.path, func(obj map[string]interface{}, key string) {
+			if value, exists := obj[key]; exists {
+				delete(obj, key)
+				obj[c.To] = value
+			}
+		})
+
+	case OpDefault:
+		visitCreate(node, c.path, func(obj map[string]interface{}, key string) {
+			if value, exists := obj[key]; !exists || value == nil {
+				obj[key] = deepCopy(c.Value)
+			}
+		})
+
+	case OpDelete:
+		visit(node, c.path, func(obj map[string]interface{}, key string) {
+			delete(obj, key)
+		})
+
+	case OpUnwrap:
+		visit(node, c.path, func(obj map[string]interface{}, key string) {
+			items, ok := obj[key].([]interface{})
+			if !ok {
+				return
+			}
+			if c.Field == "" {
+				if len(items) == 0 {
+					obj[key] = nil
+				} else {
+					obj[key] = items[0]
+				}
+				return
+			}
+			for i, item := range items {
+				if element, ok := item.(map[string]interface{}); ok {
+					if inner, exists := element[c.Field]; exists {
+						items[i] = inner
+					}
+				}
+			}
+		})
+	}
+}
+
+// collect returns the nodes reached by following a scope; "[]" segments fan out
+// over array elements
+func collect(node interface{}, scope []segment) []interface{} {
+	if len(scope) == 0 {
+		return []interface{}{node}
+	}
+
+	obj, ok := node.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	child, exists := obj[scope[0].key]
+	if !exists {
+		return nil
+	}
+	if !scope[0].each {
+		return collect(child, scope[1:])
+	}
+
+	items, ok := child.([]interface{})
+	if !ok {
+		return nil
+	}
+	var nodes []interface{}
+	for _, item := range items {
+		nodes = append(nodes, collect(item, scope[1:])...)
+	}
+	return nodes
+}
+
+// visit calls fn with the object holding the last segment of path, for every
+// match; missing fields end the walk
+func visit(node interface{}, path []segment, fn func(obj map[string]interface{}, key string)) {
+	walk(node, path, false, fn)
+}
+
+// visitCreate is visit, creating missing intermediate objects
+func visitCreate(node interface{}, path []segment, fn func(obj map[string]interface{}, key string)) {
+	walk(node, path, true, fn)
+}
+
+func walk(node interface{}, path []segment, create bool, fn func(obj map[string]interface{}, key string)) {
+	obj, ok := node.(map[string]interface{})
+	if !ok {
+		return
+	}
+	if len(path) == 1 {
+		fn(obj, path[0].key)
+		return
+	}
+
+	child, exists := obj[path[0].key]
+	if path[0].each {
+		items, _ := child.([]interface{})
+		for _, item := range items {
+			walk(item, path[1:], create, fn)
+		}
+		return
+	}
+	if (!exists || child == nil) && create {
+		child = map[string]interface{}{}
+		obj[path[0].key] = child
+	}
+	walk(child, path[1:], create, fn)
+}
+
+// get returns the value at a path without "[]" segments
+func get(node interface{}, path []segment) (interface{}, bool) {
+	var value interface{}
+	found := false
+	visit(node, path, func(obj map[string]interface{}, key string) {
+		value, found = obj[key]
+	})
+	return value, found
+}
+
+func set(node interface{}, path []segment, value interface{}) {
+	visitCreate(node, path, func(obj map[string]interface{}, key string) {
+		obj[key] = value
+	})
+}
+
+func remove(node interface{}, path []segment) {
+	visit(node, path, func(obj map[string]interface{}, key string) {
+		delete(obj, key)
+	})
+}
+
+func deepCopy(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			copied[key] = deepCopy(item)
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(v))
+		for i, item := range v {
+			copied[i] = deepCopy(item)
+		}
+		return copied
+	default:
+		return v
+	}
+}
@@ -0,0 +1,106 @@
+package transform
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func apply(t *testing.T, spec, input string) map[string]interface{} {
+	t.Helper()
+
+	parsed, err := Parse(spec)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	output, err := parsed.Apply([]byte(input))
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+
+	var result map[string]interface{}
+	if err := json.Unmarshal(output, &result); err != nil {
+		t.Fatalf("output is not a JSON object: %v", err)
+	}
+	return result
+}
+
+func assertJSON(t *testing.T, got map[string]interface{}, want string) {
+	t.Helper()
+
+	var expected map[string]interface{}
+	if err := json.Unmarshal([]byte(want), &expected); err != nil {
+		t.Fatalf("invalid expectation: %v", err)
+	}
+	if !reflect.DeepEqual(got, expected) {
+		gotJSON, _ := json.Marshal(got)
+		t.Errorf("got %s, want %s", gotJSON, want)
+	}
+}
+
+func TestMergeFlattensNestedData(t *testing.T) {
+	spec := `{"operations":[{"op":"merge","from":"data.data","to":"data"}]}`
+	input := `{"data":{"data":{"title":"A","source":"inner"},"source":"outer"},"confidence_score":1}`
+
+	assertJSON(t, apply(t, spec, input), `{"data":{"title":"A","source":"outer"},"confidence_score":1}`)
+}
+
+func TestMoveRenameDefaultDelete(t *testing.T) {
+	spec := `{"operations":[
+		{"op":"move","from":"result.items","to":"data"},
+		{"op":"delete","path":"result"},
+		{"op":"rename","path":"data[].judul","to":"title"},
+		{"op":"default","path":"data[].type","value":"TV"},
+		{"op":"default","path":"confidence_score","value":1}
+	]}`
+	input := `{"result":{"items":[{"judul":"A"},{"judul":"B","type":"Movie"}],"page":1}}`
+
+	assertJSON(t, apply(t, spec, input), `{
+		"data":[{"title":"A","type":"TV"},{"title":"B","type":"Movie"}],
+		"confidence_score":1
+	}`)
+}
+
+func TestUnwrap(t *testing.T) {
+	spec := `{"operations":[
+		{"op":"unwrap","path":"data.list","field":"anime"},
+		{"op":"unwrap","path":"data.detail"}
+	]}`
+	input := `{"data":{"list":[{"anime":{"id":1}},{"anime":{"id":2}}],"detail":[{"id":3}]}}`
+
+	assertJSON(t, apply(t, spec, input), `{"data":{"list":[{"id":1},{"id":2}],"detail":{"id":3}}}`)
+}
+
+func TestScopedMoveAndRoot(t *testing.T) {
+	spec := `{"operations":[
+		{"op":"move","from":"items[].meta.slug","to":"items[].slug"},
+		{"op":"delete","path":"items[].meta"},
+		{"op":"move","from":"$","to":"data"}
+	]}`
+	input := `{"items":[{"meta":{"slug":"a"}},{"meta":{"slug":"b"}}]}`
+
+	assertJSON(t, apply(t, spec, input), `{"data":{"items":[{"slug":"a"},{"slug":"b"}]}}`)
+}
+
+func TestParseRejectsInvalidSpecs(t *testing.T) {
+	invalid := []string{
+		`{"operations":[{"op":"explode","path":"data"}]}`,
+		`{"operations":[{"op":"rename","path":"data.a","to":"b.c"}]}`,
+		`{"operations":[{"op":"move","from":"a[].b","to":"c"}]}`,
+		`{"operations":[{"op":"delete","path":"$"}]}`,
+		`{"operations":[{"op":"delete","path":"data[]"}]}`,
+		`{"operations":[{"op":"default","path":"data"}]}`,
+		`{"ops":[]}`,
+		`not json`,
+	}
+	for _, spec := range invalid {
+		if _, err := Parse(spec); err == nil {
+			t.Errorf("Parse(%s) should fail", spec)
+		}
+	}
+
+	spec, err := Parse("")
+	if err != nil || !spec.IsEmpty() {
+		t.Errorf("empty spec should parse to an empty spec, got %v, %v", spec, err)
+	}
+}
@@ -0,0 +1,102 @@
+package validator
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Policies for responses without a confidence_score
+const (
+	MissingScoreReject = "reject" // Reject the response
+	MissingScoreAssume = "assume" // Use the policy's assumed score
+	MissingScoreDerive = "derive" // Use the fraction of required schema fields that validated
+)
+
+// ConfidencePolicy decides which confidence scores are accepted
+type ConfidencePolicy struct {
+	MinScore     float64 `json:"min_score"`
+	MissingScore string  `json:"missing_score"`
+	AssumedScore float64 `json:"assumed_score"` // Used by MissingScoreAssume
+}
+
+// DefaultConfidencePolicy rejects scores below 0.5 and responses without a score
+var DefaultConfidencePolicy = ConfidencePolicy{MinScore: 0.5, MissingScore: MissingScoreReject}
+
+// Validate checks that a policy's scores and missing score policy are usable
+func (p ConfidencePolicy) Validate() error {
+	if p.MinScore < 0 || p.MinScore > 1 {
+		return fmt.Errorf("min_score must be between 0 and 1")
+	}
+	if p.AssumedScore < 0 || p.AssumedScore > 1 {
+		return fmt.Errorf("assumed_score must be between 0 and 1")
+	}
+	switch p.MissingScore {
+	case MissingScoreReject, MissingScoreAssume, MissingScoreDerive:
+		return nil
+	default:
+		return fmt.Errorf("missing_score must be %q, %q or %q", MissingScoreReject, MissingScoreAssume, MissingScoreDerive)
+	}
+}
+
+// ConfidenceScore returns the confidence_score of a response, read from the top
+// level or, for wrapped responses, from the data object
+func ConfidenceScore(data []byte) (score float64, present bool, err error) {
+	var resp struct {
+		ConfidenceScore *float64        `json:"confidence_score"`
+		Data            json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return 0, false, fmt.Errorf("invalid JSON structure: %v", err)
+	}
+	if resp.ConfidenceScore != nil {
+		return *resp.ConfidenceScore, true, nil
+	}
+
+	var wrapped struct {
+		ConfidenceScore *float64 `json:"confidence_score"`
+	}
+	if len(resp.Data) > 0 && json.Unmarshal(resp.Data, &wrapped) == nil && wrapped.ConfidenceScore != nil {
+		return *wrapped.ConfidenceScore, true, nil
+	}
+	return 0, false, nil
+}
+
+// ValidateWithPolicy checks the confidence score of a response against a policy
+// and, when the endpoint has a schema, its structure
+func (r *Registry) ValidateWithPolicy(endpoint string, data []byte, policy ConfidencePolicy) error {
+	score, present, err := ConfidenceScore(data)
+	if err != nil {
+		return err
+	}
+
+	schema, hasSchema := r.Lookup(endpoint)
+	if !present {
+		switch policy.MissingScore {
+		case MissingScoreAssume:
+			score = policy.AssumedScore
+		case MissingScoreDerive:
+			// Required field failures lower the derived score instead of
+			// rejecting the response; without a schema nothing is required
+			score = 1
+			if hasSchema {
+				if score, err = schema.Schema.ValidateCoverage(data); err != nil {
+					return err
+				}
+			}
+			if score < policy.MinScore {
+				return fmt.Errorf("derived confidence score too low: %f", score)
+			}
+			return nil
+		default:
+			return fmt.Errorf("confidence score missing")
+		}
+	}
+
+	if score < policy.MinScore {
+		return fmt.Errorf("confidence score too low: %f", score)
+	}
+	if !hasSchema {
+		return nil
+	}
+	return schema.Schema.Validate(data)
+}
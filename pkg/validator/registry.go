@@ -0,0 +1,169 @@
+package validator
+
+import (
+	"apicategorywithfallback/pkg/routing"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Schema origins, in increasing order of precedence
+const (
+	OriginBuiltin  = "builtin"
+	OriginFile     = "file"
+	OriginDatabase = "database"
+)
+
+// builtinSchemas holds the default schemas of the built-in endpoints
+//
+//go:embed schemas/*.json
+var builtinSchemas embed.FS
+
+// EndpointSchema is a schema registered for an endpoint path or template such
+// as /api/v1/jadwal-rilis/:day
+type EndpointSchema struct {
+	Endpoint string  `json:"endpoint"`
+	Schema   *Schema `json:"schema"`
+	Origin   string  `json:"origin,omitempty"`
+}
+
+// Registry resolves endpoints to their response schemas. Registries are built
+// once and then only read; build a new one to change the schemas in use.
+type Registry struct {
+	schemas map[string]EndpointSchema
+	routes  *routing.Table
+}
+
+// defaultRegistry backs ValidateResponse
+var defaultRegistry = NewRegistry()
+
+// NewRegistry creates a registry holding the built-in schemas
+func NewRegistry() *Registry {
+	r := &Registry{
+		schemas: make(map[string]EndpointSchema),
+		routes:  routing.NewTable(),
+	}
+
+	files, err := fs.Sub(builtinSchemas, "schemas")
+	if err != nil {
+		panic(err)
+	}
+	builtins, err := loadSchemaFiles(files, OriginBuiltin)
+	if err != nil {
+		panic(fmt.Sprintf("invalid built-in schema: %v", err))
+	}
+	for _, schema := range builtins {
+		if err := r.Register(schema); err != nil {
+			panic(fmt.Sprintf("invalid built-in schema: %v", err))
+		}
+	}
+
+	return r
+}
+
+// Register adds a schema, replacing any schema registered for the same endpoint
+func (r *Registry) Register(schema EndpointSchema) error {
+	if schema.Schema == nil {
+		return fmt.Errorf("schema for %s is empty", schema.Endpoint)
+	}
+	if err := routing.Validate(schema.Endpoint); err != nil {
+		return err
+	}
+
+	key := normalizeEndpoint(schema.Endpoint)
+	schema.Endpoint = key
+	r.schemas[key] = schema
+	return r.routes.Add(key)
+}
+
+// Lookup returns the schema for an endpoint path, matching templates when no
+// schema is registered for the exact path
+func (r *Registry) Lookup(endpoint string) (EndpointSchema, bool) {
+	key := normalizeEndpoint(endpoint)
+	if schema, exists := r.schemas[key]; exists {
+		return schema, true
+	}
+	if pattern, _, ok := r.routes.Match(key); ok {
+		return r.schemas[pattern], true
+	}
+	return EndpointSchema{}, false
+}
+
+// Schemas returns the registered schemas ordered by endpoint
+func (r *Registry) Schemas() []EndpointSchema {
+	schemas := make([]EndpointSchema, 0, len(r.schemas))
+	for _, schema := range r.schemas {
+		schemas = append(schemas, schema)
+	}
+	sort.Slice(schemas, func(i, j int) bool { return schemas[i].Endpoint < schemas[j].Endpoint })
+	return schemas
+}
+
+// Validate checks a response against DefaultConfidencePolicy and, when the
+// endpoint has a schema, its structure
+func (r *Registry) Validate(endpoint string, data []byte) error {
+	return r.ValidateWithPolicy(endpoint, data, DefaultConfidencePolicy)
+}
+
+// ParseEndpointSchema parses a schema file: {"endpoint": "/api/v1/...", "schema": {...}}
+func ParseEndpointSchema(data []byte) (EndpointSchema, error) {
+	var file struct {
+		Endpoint string          `json:"endpoint"`
+		Schema   json.RawMessage `json:"schema"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return EndpointSchema{}, fmt.Errorf("invalid schema file: %w", err)
+	}
+	if err := routing.Validate(file.Endpoint); err != nil {
+		return EndpointSchema{}, err
+	}
+	if len(file.Schema) == 0 {
+		return EndpointSchema{}, fmt.Errorf("schema file for %s has no schema", file.Endpoint)
+	}
+
+	schema, err := ParseSchema(file.Schema)
+	if err != nil {
+		return EndpointSchema{}, err
+	}
+	return EndpointSchema{Endpoint: normalizeEndpoint(file.Endpoint), Schema: schema}, nil
+}
+
+// LoadSchemaDir reads every *.json schema file in a directory
+func LoadSchemaDir(dir string) ([]EndpointSchema, error) {
+	return loadSchemaFiles(os.DirFS(dir), OriginFile)
+}
+
+func loadSchemaFiles(files fs.FS, origin string) ([]EndpointSchema, error) {
+	names, err := fs.Glob(files, "*.json")
+	if err != nil {
+		return nil, err
+	}
+
+	schemas := make([]EndpointSchema, 0, len(names))
+	for _, name := range names {
+		data, err := fs.ReadFile(files, name)
+		if err != nil {
+			return nil, err
+		}
+		schema, err := ParseEndpointSchema(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", filepath.Base(name), err)
+		}
+		schema.Origin = origin
+		schemas = append(schemas, schema)
+	}
+	return schemas, nil
+}
+
+// normalizeEndpoint removes the trailing slash of an endpoint path
+func normalizeEndpoint(endpoint string) string {
+	if endpoint == "/" {
+		return endpoint
+	}
+	return strings.TrimRight(endpoint, "/")
+}
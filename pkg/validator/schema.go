@@ -0,0 +1,416 @@
+package validator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Schema is the subset of JSON Schema used to validate upstream responses:
+// type, required, properties, additionalProperties, items, anyOf, enum,
+// format "uri", minLength, minItems, minimum and maximum.
+//
+// Required string fields must also be non-empty and must not look like an
+// error placeholder ("not found", "404", ...). The root schema may replace the
+// placeholder blacklist with "x-placeholders"; an empty list disables it.
+type Schema struct {
+	Type                 typeList           `json:"type,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	Placeholders         []string           `json:"x-placeholders,omitempty"`
+
+	// never is set for the boolean schema false, which rejects every value
+	never bool
+}
+
+// schemaTypes are the type names the validator understands
+var schemaTypes = map[string]bool{
+	"object": true, "array": true, "string": true, "number": true,
+	"integer": true, "boolean": true, "null": true,
+}
+
+// typeList accepts "type" as a single name or a list of names
+type typeList []string
+
+func (t *typeList) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*t = typeList{single}
+		return nil
+	}
+
+	var multiple []string
+	if err := json.Unmarshal(data, &multiple); err != nil {
+		return fmt.Errorf("type must be a string or a list of strings")
+	}
+	*t = multiple
+	return nil
+}
+
+func (t typeList) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+// UnmarshalJSON accepts the boolean schemas true (anything) and false (nothing)
+func (s *Schema) UnmarshalJSON(data []byte) error {
+	switch string(bytes.TrimSpace(data)) {
+	case "true":
+		*s = Schema{}
+		return nil
+	case "false":
+		*s = Schema{never: true}
+		return nil
+	}
+
+	type plain Schema
+	var decoded plain
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&decoded); err != nil {
+		return err
+	}
+	*s = Schema(decoded)
+	return nil
+}
+
+// MarshalJSON writes the boolean schema false back as false
+func (s *Schema) MarshalJSON() ([]byte, error) {
+	if s.never {
+		return []byte("false"), nil
+	}
+	type plain Schema
+	return json.Marshal((*plain)(s))
+}
+
+// ParseSchema parses and checks a schema document. Unknown keywords are
+// rejected so typos do not silently disable a rule.
+func ParseSchema(data []byte) (*Schema, error) {
+	var schema Schema
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+	if err := schema.check("$"); err != nil {
+		return nil, err
+	}
+	return &schema, nil
+}
+
+func (s *Schema) check(path string) error {
+	for _, name := range s.Type {
+		if !schemaTypes[name] {
+			return fmt.Errorf("invalid schema at %s: unknown type %q", path, name)
+		}
+	}
+	if s.Format != "" && s.Format != "uri" {
+		return fmt.Errorf("invalid schema at %s: unsupported format %q", path, s.Format)
+	}
+
+	for name, property := range s.Properties {
+		if property == nil {
+			return fmt.Errorf("invalid schema at %s.%s: null schema", path, name)
+		}
+		if err := property.check(path + "." + name); err != nil {
+			return err
+		}
+	}
+	if s.AdditionalProperties != nil {
+		if err := s.AdditionalProperties.check(path + ".*"); err != nil {
+			return err
+		}
+	}
+	if s.Items != nil {
+		if err := s.Items.check(path + "[]"); err != nil {
+			return err
+		}
+	}
+	for i, option := range s.AnyOf {
+		if option == nil {
+			return fmt.Errorf("invalid schema at %s.anyOf[%d]: null schema", path, i)
+		}
+		if err := option.check(fmt.Sprintf("%s.anyOf[%d]", path, i)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Validate checks a JSON document against the schema
+func (s *Schema) Validate(data []byte) error {
+	document, err := decodeDocument(data)
+	if err != nil {
+		return err
+	}
+
+	v := &schemaValidator{placeholders: s.Placeholders}
+	return v.validate(s, document, "$")
+}
+
+// ValidateCoverage checks a JSON document against the schema without failing
+// on required fields, and returns the fraction of required fields that are
+// present and valid (1 when the schema requires none)
+func (s *Schema) ValidateCoverage(data []byte) (float64, error) {
+	document, err := decodeDocument(data)
+	if err != nil {
+		return 0, err
+	}
+
+	v := &schemaValidator{placeholders: s.Placeholders, countRequired: true}
+	if err := v.validate(s, document, "$"); err != nil {
+		return 0, err
+	}
+	return v.coverage(), nil
+}
+
+func decodeDocument(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var document interface{}
+	if err := decoder.Decode(&document); err != nil {
+		return nil, fmt.Errorf("invalid JSON structure: %v", err)
+	}
+	return document, nil
+}
+
+// schemaValidator carries settings of the root schema through validation
+type schemaValidator struct {
+	placeholders []string // nil uses the built-in placeholder blacklist
+
+	// countRequired counts required fields instead of failing on them
+	countRequired bool
+	required      int
+	valid         int
+}
+
+func (v *schemaValidator) coverage() float64 {
+	if v.required == 0 {
+		return 1
+	}
+	return float64(v.valid) / float64(v.required)
+}
+
+func (v *schemaValidator) validate(s *Schema, value interface{}, path string) error {
+	if s.never {
+		return fmt.Errorf("%s is not allowed", path)
+	}
+
+	if len(s.Type) > 0 && !hasType(s.Type, value) {
+		return fmt.Errorf("%s must be of type %s, got %s", path, strings.Join(s.Type, " or "), jsonType(value))
+	}
+
+	if len(s.Enum) > 0 && !inEnum(s.Enum, value) {
+		return fmt.Errorf("%s must be one of the allowed values", path)
+	}
+
+	if len(s.AnyOf) > 0 {
+		// When counting, keep the counts of the shape with the best coverage
+		var firstErr error
+		var best *schemaValidator
+		for _, option := range s.AnyOf {
+			sub := &schemaValidator{placeholders: v.placeholders, countRequired: v.countRequired}
+			err := sub.validate(option, value, path)
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				continue
+			}
+			if best == nil || sub.coverage() > best.coverage() {
+				best = sub
+			}
+			if !v.countRequired {
+				break
+			}
+		}
+		if best == nil {
+			return fmt.Errorf("%s matches none of the allowed shapes: %v", path, firstErr)
+		}
+		v.required += best.required
+		v.valid += best.valid
+	}
+
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		return v.validateObject(s, typed, path)
+	case []interface{}:
+		if s.MinItems != nil && len(typed) < *s.MinItems {
+			return fmt.Errorf("%s must have at least %d items", path, *s.MinItems)
+		}
+		if s.Items != nil {
+			for i, item := range typed {
+				if err := v.validate(s.Items, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+	case string:
+		if s.MinLength != nil && len([]rune(typed)) < *s.MinLength {
+			return fmt.Errorf("%s must be at least %d characters", path, *s.MinLength)
+		}
+		if s.Format == "uri" && !isValidURL(typed) {
+			return fmt.Errorf("%s contains invalid URL: %s", path, typed)
+		}
+	case json.Number:
+		number, err := typed.Float64()
+		if err != nil {
+			return fmt.Errorf("%s is not a valid number", path)
+		}
+		if s.Minimum != nil && number < *s.Minimum {
+			return fmt.Errorf("%s must be at least %v", path, *s.Minimum)
+		}
+		if s.Maximum != nil && number > *s.Maximum {
+			return fmt.Errorf("%s must be at most %v", path, *s.Maximum)
+		}
+	}
+
+	return nil
+}
+
+func (v *schemaValidator) validateObject(s *Schema, object map[string]interface{}, path string) error {
+	// When counting, a required field with an invalid value counts as missing
+	// instead of failing the document
+	counted := make(map[string]bool)
+	for _, name := range s.Required {
+		err := v.checkRequired(object, name, path)
+		if !v.countRequired {
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		v.required++
+		counted[name] = true
+		if err != nil {
+			continue
+		}
+		sub := &schemaValidator{placeholders: v.placeholders, countRequired: true}
+		if property := s.propertySchema(name); property != nil {
+			if err := sub.validate(property, object[name], path+"."+name); err != nil {
+				continue
+			}
+		}
+		v.valid++
+		v.required += sub.required
+		v.valid += sub.valid
+	}
+
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if counted[name] {
+			continue
+		}
+		property := s.propertySchema(name)
+		if property == nil {
+			continue
+		}
+		if err := v.validate(property, object[name], path+"."+name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// propertySchema returns the schema of an object property, or nil when it is unconstrained
+func (s *Schema) propertySchema(name string) *Schema {
+	if property, declared := s.Properties[name]; declared {
+		return property
+	}
+	return s.AdditionalProperties
+}
+
+func (v *schemaValidator) checkRequired(object map[string]interface{}, name, path string) error {
+	value, exists := object[name]
+	if !exists {
+		return fmt.Errorf("required field '%s' not found at %s", name, path)
+	}
+	if value == nil {
+		return fmt.Errorf("required field '%s' is null at %s", name, path)
+	}
+	if text, ok := value.(string); ok && (text == "" || v.isPlaceholder(text)) {
+		return fmt.Errorf("required field '%s' is empty or placeholder at %s", name, path)
+	}
+	return nil
+}
+
+func (v *schemaValidator) isPlaceholder(value string) bool {
+	if v.placeholders == nil {
+		return isPlaceholderValue(value)
+	}
+	if isValidURL(value) {
+		return false
+	}
+
+	lowerValue := strings.ToLower(strings.TrimSpace(value))
+	for _, placeholder := range v.placeholders {
+		if placeholder != "" && strings.Contains(lowerValue, strings.ToLower(placeholder)) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasType(types typeList, value interface{}) bool {
+	actual := jsonType(value)
+	for _, name := range types {
+		if name == actual {
+			return true
+		}
+		if name == "number" && actual == "integer" {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonType returns the JSON Schema type name of a decoded value
+func jsonType(value interface{}) string {
+	switch typed := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number:
+		if _, err := typed.Int64(); err == nil {
+			return "integer"
+		}
+		return "number"
+	case []interface{}:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+func inEnum(enum []interface{}, value interface{}) bool {
+	encoded, _ := json.Marshal(value)
+	for _, allowed := range enum {
+		candidate, _ := json.Marshal(allowed)
+		if bytes.Equal(encoded, candidate) {
+			return true
+		}
+	}
+	return false
+}
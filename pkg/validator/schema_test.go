@@ -0,0 +1,119 @@
+package validator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseSchemaRejectsUnknownKeywords(t *testing.T) {
+	if _, err := ParseSchema([]byte(`{"type": "object", "patternProperties": {}}`)); err == nil {
+		t.Error("Unsupported keyword should return error")
+	}
+	if _, err := ParseSchema([]byte(`{"type": "tuple"}`)); err == nil {
+		t.Error("Unknown type should return error")
+	}
+	if _, err := ParseSchema([]byte(`{"type": ["object", "null"], "required": ["id"]}`)); err != nil {
+		t.Errorf("Valid schema should not return error: %v", err)
+	}
+}
+
+func TestSchemaValidate(t *testing.T) {
+	schema, err := ParseSchema([]byte(`{
+		"type": "object",
+		"required": ["title", "items"],
+		"properties": {
+			"title": {"type": "string"},
+			"items": {"type": "array", "minItems": 1, "items": {"type": "object", "required": ["url"], "properties": {"url": {"type": "string", "format": "uri"}}}},
+			"status": {"enum": ["ongoing", "completed"]}
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("ParseSchema: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		data    string
+		wantErr bool
+	}{
+		{"valid", `{"title": "One Piece", "items": [{"url": "https://example.com/1"}], "status": "ongoing"}`, false},
+		{"missing required", `{"items": [{"url": "https://example.com/1"}]}`, true},
+		{"placeholder", `{"title": "Not Found", "items": [{"url": "https://example.com/1"}]}`, true},
+		{"too few items", `{"title": "One Piece", "items": []}`, true},
+		{"invalid uri", `{"title": "One Piece", "items": [{"url": "not a url"}]}`, true},
+		{"enum", `{"title": "One Piece", "items": [{"url": "https://example.com/1"}], "status": "unknown"}`, true},
+		{"wrong type", `{"title": 1, "items": [{"url": "https://example.com/1"}]}`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := schema.Validate([]byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSchemaCustomPlaceholders(t *testing.T) {
+	schema, err := ParseSchema([]byte(`{"required": ["title"], "x-placeholders": ["tba"]}`))
+	if err != nil {
+		t.Fatalf("ParseSchema: %v", err)
+	}
+
+	if err := schema.Validate([]byte(`{"title": "TBA"}`)); err == nil {
+		t.Error("Custom placeholder should return error")
+	}
+	if err := schema.Validate([]byte(`{"title": "Coming Soon"}`)); err != nil {
+		t.Errorf("Built-in placeholders should be replaced: %v", err)
+	}
+}
+
+func TestRegistryLookup(t *testing.T) {
+	r := NewRegistry()
+
+	if schema, ok := r.Lookup("/api/v1/home/"); !ok || schema.Origin != OriginBuiltin {
+		t.Errorf("Lookup(/api/v1/home/) = %+v, %v", schema, ok)
+	}
+	if schema, ok := r.Lookup("/api/v1/jadwal-rilis/monday"); !ok || schema.Endpoint != "/api/v1/jadwal-rilis/:day" {
+		t.Errorf("Lookup(/api/v1/jadwal-rilis/monday) = %+v, %v", schema, ok)
+	}
+	if _, ok := r.Lookup("/api/v1/unknown"); ok {
+		t.Error("Lookup should not find a schema for an unknown endpoint")
+	}
+
+	// Endpoints without a schema only get the base checks
+	if err := r.Validate("/api/v1/unknown", []byte(`{"confidence_score": 0.9}`)); err != nil {
+		t.Errorf("Validate without schema: %v", err)
+	}
+	if err := r.Validate("/api/v1/unknown", []byte(`{"confidence_score": 0.1}`)); err == nil {
+		t.Error("Low confidence should return error")
+	}
+}
+
+func TestLoadSchemaDirOverridesBuiltin(t *testing.T) {
+	dir := t.TempDir()
+	file := `{"endpoint": "/api/v1/movie/", "schema": {"type": "object", "required": ["films"]}}`
+	if err := os.WriteFile(filepath.Join(dir, "movie.json"), []byte(file), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	schemas, err := LoadSchemaDir(dir)
+	if err != nil {
+		t.Fatalf("LoadSchemaDir: %v", err)
+	}
+	if len(schemas) != 1 || schemas[0].Endpoint != "/api/v1/movie" || schemas[0].Origin != OriginFile {
+		t.Fatalf("LoadSchemaDir = %+v", schemas)
+	}
+
+	r := NewRegistry()
+	if err := r.Register(schemas[0]); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	if err := r.Validate("/api/v1/movie", []byte(`{"confidence_score": 0.9, "films": []}`)); err != nil {
+		t.Errorf("File schema should replace the built-in one: %v", err)
+	}
+	if err := r.Validate("/api/v1/movie", []byte(`{"confidence_score": 0.9, "data": []}`)); err == nil {
+		t.Error("File schema should require films")
+	}
+}
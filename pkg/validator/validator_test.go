@@ -222,3 +222,37 @@ func TestValidateURLFields(t *testing.T) {
 		t.Errorf("Invalid URL should return error")
 	}
 }
+
+func TestConfidencePolicies(t *testing.T) {
+	r := NewRegistry()
+	complete := []byte(`{"data": [{"judul": "Movie", "url": "https://example.com/m", "anime_slug": "movie", "cover": "https://example.com/c.jpg"}]}`)
+	partial := []byte(`{"data": [{"judul": "Movie", "url": "https://example.com/m", "anime_slug": "movie", "cover": "N/A"}]}`)
+
+	tests := []struct {
+		name    string
+		data    []byte
+		policy  ConfidencePolicy
+		wantErr bool
+	}{
+		{"missing rejected", complete, ConfidencePolicy{MinScore: 0.5, MissingScore: MissingScoreReject}, true},
+		{"missing assumed", complete, ConfidencePolicy{MinScore: 0.5, MissingScore: MissingScoreAssume, AssumedScore: 0.9}, false},
+		{"assumed below minimum", complete, ConfidencePolicy{MinScore: 0.5, MissingScore: MissingScoreAssume, AssumedScore: 0.3}, true},
+		{"derived complete", complete, ConfidencePolicy{MinScore: 0.9, MissingScore: MissingScoreDerive}, false},
+		{"derived partial accepted", partial, ConfidencePolicy{MinScore: 0.5, MissingScore: MissingScoreDerive}, false},
+		{"derived partial rejected", partial, ConfidencePolicy{MinScore: 0.9, MissingScore: MissingScoreDerive}, true},
+		{"lower minimum", []byte(`{"confidence_score": 0.3, "data": []}`), ConfidencePolicy{MinScore: 0.2, MissingScore: MissingScoreReject}, false},
+		{"wrapped score", []byte(`{"data": {"confidence_score": 0.3}}`), ConfidencePolicy{MinScore: 0.5, MissingScore: MissingScoreAssume, AssumedScore: 1}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := r.ValidateWithPolicy("/api/v1/movie", tt.data, tt.policy)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateWithPolicy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if err := (ConfidencePolicy{MinScore: 0.5, MissingScore: "ignore"}).Validate(); err == nil {
+		t.Error("Unknown missing score policy should return error")
+	}
+}
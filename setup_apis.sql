@@ -15,6 +15,7 @@ SELECT e.id, 'winbutv', 'http://localhost:8082', 2, 1
 FROM endpoints e;
 
 -- Tambahkan fallback APIs (contoh)
+-- Fallback juga bisa dikelola lewat dashboard: /dashboard/api-sources/:id/fallbacks
 INSERT OR IGNORE INTO fallback_apis (api_source_id, fallback_url, priority) 
 SELECT a.id, 'http://localhost:8083' || e.path, 1
 FROM api_sources a